@@ -0,0 +1,269 @@
+// Package actionlib implements action clients and servers which speak the
+// actionlib protocol: goals are sent on <action>/goal and canceled on
+// <action>/cancel, and servers report on <action>/status, <action>/feedback
+// and <action>/result.
+//
+// Callbacks are invoked by the node's Spin or SpinOnce, so a node must be
+// spinning, usually in its own goroutine, for clients and servers to make
+// progress.
+package actionlib
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ppg/rosgo/msgs/actionlib_msgs"
+	"github.com/ppg/rosgo/msgs/std_msgs"
+	"github.com/ppg/rosgo/ros"
+)
+
+// A node gives everything using a topic the same publisher or subscriber,
+// so clients and servers count their references to those they use, and the
+// last to let go shuts them down.  The mutex is held while they are created
+// too, so one isn't shut down just as another client or server takes it.
+var shared = struct {
+	sync.Mutex
+	refs map[interface{ Shutdown() }]int
+}{refs: make(map[interface{ Shutdown() }]int)}
+
+// Advertise topic, taking a reference to its publisher.
+func advertise(node ros.Node, topic string, msgType ros.MessageType, opts ...ros.PublisherOption) (ros.Publisher, error) {
+	shared.Lock()
+	defer shared.Unlock()
+	pub, err := node.NewPublisher(topic, msgType, opts...)
+	if err != nil {
+		return nil, err
+	}
+	shared.refs[pub]++
+	return pub, nil
+}
+
+// Subscribe to topic, taking a reference to its subscriber.  The callback
+// stays with the subscriber until it shuts down, so it must ignore messages
+// once its client or server has.
+func subscribe(node ros.Node, topic string, msgType ros.MessageType, callback interface{}) (ros.Subscriber, error) {
+	shared.Lock()
+	defer shared.Unlock()
+	sub, err := node.NewSubscriber(topic, msgType, callback)
+	if err != nil {
+		return nil, err
+	}
+	shared.refs[sub]++
+	return sub, nil
+}
+
+// Drop a reference taken by advertise or subscribe, shutting the publisher
+// or subscriber down with the last one.
+func release(x interface{ Shutdown() }) {
+	shared.Lock()
+	defer shared.Unlock()
+	if shared.refs[x]--; shared.refs[x] > 0 {
+		return
+	}
+	delete(shared.refs, x)
+	x.Shutdown()
+}
+
+// ActionType describes an action by the generated types of the messages sent
+// on its goal, result and feedback topics.
+type ActionType interface {
+	// Name of the action such as control_msgs/FollowJointTrajectory.
+	Name() string
+	ActionGoalType() ros.MessageType
+	ActionResultType() ros.MessageType
+	ActionFeedbackType() ros.MessageType
+}
+
+type defaultActionType struct {
+	actionGoal     ros.MessageType
+	actionResult   ros.MessageType
+	actionFeedback ros.MessageType
+}
+
+// Create the ActionType of the messages generated for an action, such as
+//
+//	actionlib.NewActionType(control_msgs.MsgFollowJointTrajectoryActionGoal,
+//		control_msgs.MsgFollowJointTrajectoryActionResult,
+//		control_msgs.MsgFollowJointTrajectoryActionFeedback)
+func NewActionType(actionGoal, actionResult, actionFeedback ros.MessageType) ActionType {
+	return &defaultActionType{actionGoal, actionResult, actionFeedback}
+}
+
+func (t *defaultActionType) Name() string {
+	return strings.TrimSuffix(t.actionGoal.Name(), "ActionGoal")
+}
+
+func (t *defaultActionType) ActionGoalType() ros.MessageType {
+	return t.actionGoal
+}
+
+func (t *defaultActionType) ActionResultType() ros.MessageType {
+	return t.actionResult
+}
+
+func (t *defaultActionType) ActionFeedbackType() ros.MessageType {
+	return t.actionFeedback
+}
+
+// Status of a goal, as in actionlib_msgs/GoalStatus.
+type Status uint8
+
+const (
+	Pending Status = iota
+	Active
+	Preempted
+	Succeeded
+	Aborted
+	Rejected
+	Preempting
+	Recalling
+	Recalled
+	Lost
+)
+
+var statusNames = []string{
+	"PENDING", "ACTIVE", "PREEMPTED", "SUCCEEDED", "ABORTED",
+	"REJECTED", "PREEMPTING", "RECALLING", "RECALLED", "LOST",
+}
+
+func (s Status) String() string {
+	if int(s) < len(statusNames) {
+		return statusNames[s]
+	}
+	return fmt.Sprintf("Status(%d)", uint8(s))
+}
+
+// Whether a goal with this status is finished.
+func (s Status) IsTerminal() bool {
+	switch s {
+	case Preempted, Succeeded, Aborted, Rejected, Recalled, Lost:
+		return true
+	}
+	return false
+}
+
+// The fields of a generated <Action>ActionGoal, <Action>ActionResult or
+// <Action>ActionFeedback message.  Exactly one of goalID and status is set,
+// and payload points to the Goal, Result or Feedback field.
+type actionMessage struct {
+	msg     ros.Message
+	header  *std_msgs.Header
+	goalID  *actionlib_msgs.GoalID
+	status  *actionlib_msgs.GoalStatus
+	payload ros.Message
+}
+
+// Take apart a generated action message, which must be a pointer to a struct
+// with a Header field, a GoalID or Status field, and a payloadField field.
+func splitActionMessage(msg ros.Message, payloadField string) (*actionMessage, error) {
+	v := reflect.ValueOf(msg)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%T is not a generated action message", msg)
+	}
+	v = v.Elem()
+	fields := &actionMessage{msg: msg}
+	var ok bool
+	if f := v.FieldByName("Header"); f.IsValid() {
+		fields.header, ok = f.Addr().Interface().(*std_msgs.Header)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%T has no std_msgs/Header field named Header", msg)
+	}
+	if f := v.FieldByName("GoalID"); f.IsValid() {
+		fields.goalID, _ = f.Addr().Interface().(*actionlib_msgs.GoalID)
+	} else if f := v.FieldByName("Status"); f.IsValid() {
+		fields.status, _ = f.Addr().Interface().(*actionlib_msgs.GoalStatus)
+	}
+	if fields.goalID == nil && fields.status == nil {
+		return nil, fmt.Errorf("%T has no actionlib_msgs/GoalID field named GoalID or actionlib_msgs/GoalStatus field named Status", msg)
+	}
+	if f := v.FieldByName(payloadField); f.IsValid() {
+		fields.payload, ok = f.Addr().Interface().(ros.Message)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%T has no message field named %s", msg, payloadField)
+	}
+	return fields, nil
+}
+
+// Create a new action message of msgType holding a copy of payload.
+func newActionMessage(msgType ros.MessageType, payloadField string, payload ros.Message) (*actionMessage, error) {
+	fields, err := splitActionMessage(msgType.NewMessage(), payloadField)
+	if err != nil {
+		return nil, err
+	}
+	if payload != nil {
+		dst := reflect.ValueOf(fields.payload).Elem()
+		src := reflect.ValueOf(payload)
+		if src.Type() != dst.Addr().Type() {
+			return nil, fmt.Errorf("expected %s of type %s; got %T", strings.ToLower(payloadField), dst.Addr().Type(), payload)
+		}
+		dst.Set(src.Elem())
+	}
+	return fields, nil
+}
+
+// Check that the generated types of an action have the expected fields.
+func checkActionType(actionType ActionType) error {
+	for _, t := range []struct {
+		msgType ros.MessageType
+		payload string
+	}{
+		{actionType.ActionGoalType(), "Goal"},
+		{actionType.ActionResultType(), "Result"},
+		{actionType.ActionFeedbackType(), "Feedback"},
+	} {
+		if _, err := splitActionMessage(t.msgType.NewMessage(), t.payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Call a callback with as many of args as it takes, the way subscriber
+// callbacks are called.  A nil callback is ignored.
+func invokeCallback(callback interface{}, args ...interface{}) {
+	if callback == nil {
+		return
+	}
+	fun := reflect.ValueOf(callback)
+	n := fun.Type().NumIn()
+	if n > len(args) {
+		n = len(args)
+	}
+	in := make([]reflect.Value, n)
+	for i := range in {
+		if args[i] == nil {
+			in[i] = reflect.Zero(fun.Type().In(i))
+		} else {
+			in[i] = reflect.ValueOf(args[i])
+		}
+	}
+	fun.Call(in)
+}
+
+// Check that a callback is a function taking at most maxArgs arguments.
+func checkCallback(name string, callback interface{}, maxArgs int) error {
+	if callback == nil {
+		return nil
+	}
+	t := reflect.TypeOf(callback)
+	if t.Kind() != reflect.Func || t.NumIn() > maxArgs {
+		return fmt.Errorf("%s must be a function of at most %d arguments; got %T", name, maxArgs, callback)
+	}
+	return nil
+}
+
+var goalCount uint64
+
+// Generate a goal ID unique to this process, in the form actionlib uses.
+func generateGoalID(nodeName string, stamp ros.Time) actionlib_msgs.GoalID {
+	n := atomic.AddUint64(&goalCount, 1)
+	return actionlib_msgs.GoalID{
+		Stamp: stamp,
+		ID:    fmt.Sprintf("%s-%d-%d.%03d", nodeName, n, stamp.Sec, stamp.NSec/1000000),
+	}
+}
@@ -0,0 +1,252 @@
+package actionlib
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ppg/rosgo/master"
+	"github.com/ppg/rosgo/msgs/control_msgs"
+	"github.com/ppg/rosgo/ros"
+)
+
+var gripperCommandAction = NewActionType(
+	control_msgs.MsgGripperCommandActionGoal,
+	control_msgs.MsgGripperCommandActionResult,
+	control_msgs.MsgGripperCommandActionFeedback)
+
+// startTestNodes runs a master in-process and returns spinning server and
+// client nodes connected to it.
+func startTestNodes(t *testing.T) (ros.Node, ros.Node) {
+	m, err := master.Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(m.Shutdown)
+	t.Setenv("ROS_MASTER_URI", m.Uri())
+	t.Setenv("ROS_IP", "127.0.0.1")
+	var nodes []ros.Node
+	for _, name := range []string{"server", "client"} {
+		node, err := ros.NewNode(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		node.Logger().SetSeverity(ros.LogLevelFatal)
+		t.Cleanup(node.Shutdown)
+		go node.Spin()
+		nodes = append(nodes, node)
+	}
+	return nodes[0], nodes[1]
+}
+
+func newTestClient(t *testing.T, node ros.Node) SimpleActionClient {
+	client, err := NewSimpleActionClient(node, "gripper", gripperCommandAction)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !client.WaitForServer(5 * time.Second) {
+		t.Fatal("server did not connect")
+	}
+	return client
+}
+
+func TestActionType(t *testing.T) {
+	if name := gripperCommandAction.Name(); name != "control_msgs/GripperCommand" {
+		t.Errorf("expected control_msgs/GripperCommand; got %s", name)
+	}
+	if err := checkActionType(gripperCommandAction); err != nil {
+		t.Error(err)
+	}
+	bad := NewActionType(control_msgs.MsgGripperCommandGoal, control_msgs.MsgGripperCommandActionResult, control_msgs.MsgGripperCommandActionFeedback)
+	if err := checkActionType(bad); err == nil {
+		t.Error("expected an action type with a bare goal to be rejected")
+	}
+	if _, err := newActionMessage(control_msgs.MsgGripperCommandActionGoal, "Goal", &control_msgs.GripperCommandResult{}); err == nil {
+		t.Error("expected a goal of the wrong type to be rejected")
+	}
+}
+
+func TestSimpleActionSucceeded(t *testing.T) {
+	serverNode, clientNode := startTestNodes(t)
+	var server SimpleActionServer
+	ready := make(chan struct{})
+	server, err := NewSimpleActionServer(serverNode, "gripper", gripperCommandAction, func(goal *control_msgs.GripperCommandGoal) {
+		<-ready
+		// Feedback isn't queued for clients, so keep sending it for a
+		// while.
+		for i := 0; i < 10; i++ {
+			server.PublishFeedback(&control_msgs.GripperCommandFeedback{Position: goal.Command.Position / 2})
+			time.Sleep(20 * time.Millisecond)
+		}
+		server.SetSucceeded(&control_msgs.GripperCommandResult{Position: goal.Command.Position, ReachedGoal: true}, "done")
+	}, StatusFrequency(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Shutdown()
+	close(ready)
+	client := newTestClient(t, clientNode)
+
+	active := make(chan struct{}, 1)
+	feedback := make(chan float64, 1)
+	done := make(chan Status, 1)
+	err = client.SendGoalWithCallbacks(&control_msgs.GripperCommandGoal{Command: control_msgs.GripperCommand{Position: 0.5}},
+		func(status Status, result *control_msgs.GripperCommandResult) { done <- status },
+		func() { active <- struct{}{} },
+		func(fb *control_msgs.GripperCommandFeedback) {
+			select {
+			case feedback <- fb.Position:
+			default:
+			}
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !client.WaitForResult(5 * time.Second) {
+		t.Fatal("goal did not finish")
+	}
+	if state := client.GetState(); state != Succeeded {
+		t.Errorf("expected SUCCEEDED; got %s", state)
+	}
+	if text := client.GetGoalStatusText(); text != "done" {
+		t.Errorf("expected status text done; got %q", text)
+	}
+	result, ok := client.GetResult().(*control_msgs.GripperCommandResult)
+	if !ok || result.Position != 0.5 || !result.ReachedGoal {
+		t.Errorf("unexpected result %#v", client.GetResult())
+	}
+	select {
+	case status := <-done:
+		if status != Succeeded {
+			t.Errorf("done callback got %s", status)
+		}
+	case <-time.After(time.Second):
+		t.Error("done callback not called")
+	}
+	select {
+	case <-active:
+	default:
+		t.Error("active callback not called")
+	}
+	select {
+	case position := <-feedback:
+		if position != 0.25 {
+			t.Errorf("expected feedback position 0.25; got %v", position)
+		}
+	default:
+		t.Error("feedback callback not called")
+	}
+}
+
+func TestSimpleActionPreempted(t *testing.T) {
+	serverNode, clientNode := startTestNodes(t)
+	var server SimpleActionServer
+	ready := make(chan struct{})
+	server, err := NewSimpleActionServer(serverNode, "gripper", gripperCommandAction, func(goal *control_msgs.GripperCommandGoal) {
+		<-ready
+		for !server.IsPreemptRequested() {
+			time.Sleep(10 * time.Millisecond)
+		}
+		server.SetPreempted(nil, "stopped")
+	}, StatusFrequency(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Shutdown()
+	close(ready)
+	client := newTestClient(t, clientNode)
+
+	if err := client.SendGoal(&control_msgs.GripperCommandGoal{}); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for client.GetSimpleState() != SimpleActive {
+		if time.Now().After(deadline) {
+			t.Fatal("goal did not become active")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := client.CancelGoal(); err != nil {
+		t.Fatal(err)
+	}
+	if !client.WaitForResult(5 * time.Second) {
+		t.Fatal("goal did not finish")
+	}
+	if state := client.GetState(); state != Preempted {
+		t.Errorf("expected PREEMPTED; got %s", state)
+	}
+}
+
+func TestActionServerRejected(t *testing.T) {
+	serverNode, clientNode := startTestNodes(t)
+	server, err := NewActionServer(serverNode, "gripper", gripperCommandAction, func(goal *ServerGoalHandle) {
+		if err := goal.SetRejected(nil, "busy"); err != nil {
+			t.Error(err)
+		}
+		if err := goal.SetSucceeded(nil, ""); err == nil {
+			t.Error("expected a rejected goal not to succeed")
+		}
+	}, nil, StatusFrequency(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Shutdown()
+	client := newTestClient(t, clientNode)
+
+	status, err := client.SendGoalAndWait(&control_msgs.GripperCommandGoal{}, 5*time.Second, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != Rejected {
+		t.Errorf("expected REJECTED; got %s", status)
+	}
+	if text := client.GetGoalStatusText(); text != "busy" {
+		t.Errorf("expected status text busy; got %q", text)
+	}
+}
+
+func TestTwoClientsShareTopics(t *testing.T) {
+	serverNode, clientNode := startTestNodes(t)
+	server, err := NewActionServer(serverNode, "gripper", gripperCommandAction, func(goal *ServerGoalHandle) {
+		goal.SetRejected(nil, "busy")
+	}, nil, StatusFrequency(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Shutdown()
+	first := newTestClient(t, clientNode)
+	second := newTestClient(t, clientNode)
+	defer second.Shutdown()
+
+	// The clients share the node's publishers and subscribers, which must
+	// outlive the first one.
+	first.Shutdown()
+	status, err := second.SendGoalAndWait(&control_msgs.GripperCommandGoal{}, 5*time.Second, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != Rejected {
+		t.Errorf("expected REJECTED; got %s", status)
+	}
+}
+
+func TestCommTransitions(t *testing.T) {
+	c := &defaultSimpleActionClient{}
+	var active, done int
+	goal := &clientGoal{
+		state:          CommWaitingForGoalAck,
+		done:           make(chan struct{}),
+		activeCallback: func() { active++ },
+		doneCallback:   func() { done++ },
+	}
+	// A goal which the server finished before the client saw it pending
+	// still passes through ACTIVE.
+	goal.status.Status = uint8(Succeeded)
+	runCalls(c.transition(goal, commTransitions[goal.state][Succeeded]...))
+	if goal.state != CommWaitingForResult || goal.simpleState != SimpleActive || active != 1 {
+		t.Errorf("unexpected state %s/%s after SUCCEEDED; active called %d times", goal.state, goal.simpleState, active)
+	}
+	runCalls(c.transition(goal, CommDone))
+	if goal.simpleState != SimpleDone || done != 1 {
+		t.Errorf("unexpected state %s after DONE; done called %d times", goal.simpleState, done)
+	}
+}
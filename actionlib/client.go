@@ -0,0 +1,513 @@
+package actionlib
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ppg/rosgo/msgs/actionlib_msgs"
+	"github.com/ppg/rosgo/ros"
+)
+
+// CommState is the state of a goal as tracked by a client from the statuses
+// and result the server publishes.
+type CommState int
+
+const (
+	CommWaitingForGoalAck CommState = iota
+	CommPending
+	CommActive
+	CommWaitingForResult
+	CommWaitingForCancelAck
+	CommRecalling
+	CommPreempting
+	CommDone
+)
+
+var commStateNames = []string{
+	"WAITING_FOR_GOAL_ACK", "PENDING", "ACTIVE", "WAITING_FOR_RESULT",
+	"WAITING_FOR_CANCEL_ACK", "RECALLING", "PREEMPTING", "DONE",
+}
+
+func (s CommState) String() string {
+	if s >= 0 && int(s) < len(commStateNames) {
+		return commStateNames[s]
+	}
+	return fmt.Sprintf("CommState(%d)", int(s))
+}
+
+// The states a goal passes through when the server reports a status for it,
+// by its current state.  Statuses which aren't listed don't change the
+// state.
+var commTransitions = map[CommState]map[Status][]CommState{
+	CommWaitingForGoalAck: {
+		Pending:    {CommPending},
+		Active:     {CommActive},
+		Rejected:   {CommPending, CommWaitingForResult},
+		Recalling:  {CommPending, CommRecalling},
+		Recalled:   {CommPending, CommWaitingForResult},
+		Preempted:  {CommActive, CommPreempting, CommWaitingForResult},
+		Succeeded:  {CommActive, CommWaitingForResult},
+		Aborted:    {CommActive, CommWaitingForResult},
+		Preempting: {CommActive, CommPreempting},
+	},
+	CommPending: {
+		Active:     {CommActive},
+		Rejected:   {CommWaitingForResult},
+		Recalling:  {CommRecalling},
+		Recalled:   {CommRecalling, CommWaitingForResult},
+		Preempted:  {CommActive, CommPreempting, CommWaitingForResult},
+		Succeeded:  {CommActive, CommWaitingForResult},
+		Aborted:    {CommActive, CommWaitingForResult},
+		Preempting: {CommActive, CommPreempting},
+	},
+	CommActive: {
+		Preempted:  {CommPreempting, CommWaitingForResult},
+		Succeeded:  {CommWaitingForResult},
+		Aborted:    {CommWaitingForResult},
+		Preempting: {CommPreempting},
+	},
+	CommWaitingForCancelAck: {
+		Rejected:   {CommWaitingForResult},
+		Recalling:  {CommRecalling},
+		Recalled:   {CommRecalling, CommWaitingForResult},
+		Preempted:  {CommPreempting, CommWaitingForResult},
+		Succeeded:  {CommPreempting, CommWaitingForResult},
+		Aborted:    {CommPreempting, CommWaitingForResult},
+		Preempting: {CommPreempting},
+	},
+	CommRecalling: {
+		Rejected:   {CommWaitingForResult},
+		Recalled:   {CommWaitingForResult},
+		Preempted:  {CommPreempting, CommWaitingForResult},
+		Succeeded:  {CommPreempting, CommWaitingForResult},
+		Aborted:    {CommPreempting, CommWaitingForResult},
+		Preempting: {CommPreempting},
+	},
+	CommPreempting: {
+		Preempted: {CommWaitingForResult},
+		Succeeded: {CommWaitingForResult},
+		Aborted:   {CommWaitingForResult},
+	},
+}
+
+// SimpleGoalState is the coarse state of the goal of a SimpleActionClient.
+type SimpleGoalState int
+
+const (
+	SimplePending SimpleGoalState = iota
+	SimpleActive
+	SimpleDone
+)
+
+func (s SimpleGoalState) String() string {
+	switch s {
+	case SimplePending:
+		return "PENDING"
+	case SimpleActive:
+		return "ACTIVE"
+	case SimpleDone:
+		return "DONE"
+	}
+	return fmt.Sprintf("SimpleGoalState(%d)", int(s))
+}
+
+// SimpleActionClient sends goals to an action server and tracks one goal at
+// a time.
+type SimpleActionClient interface {
+	// Wait until the server is connected.  A timeout of 0 waits forever.
+	// Returns whether the server connected.
+	WaitForServer(timeout time.Duration) bool
+	// Send a goal, such as *control_msgs.FollowJointTrajectoryGoal, and
+	// stop tracking the previous one.
+	SendGoal(goal ros.Message) error
+	// Send a goal like SendGoal.  doneCallback is called with the final
+	// Status and the result, such as
+	// *control_msgs.FollowJointTrajectoryResult, activeCallback without
+	// arguments when the server accepts the goal, and feedbackCallback
+	// with each feedback message.  Callbacks may be nil or take fewer
+	// arguments, and are called from the node's Spin.
+	SendGoalWithCallbacks(goal ros.Message, doneCallback, activeCallback, feedbackCallback interface{}) error
+	// Send a goal and wait up to executeTimeout for it to finish.  If it
+	// doesn't, cancel it and wait up to preemptTimeout for the server to
+	// finish it.  Returns the status of the goal.
+	SendGoalAndWait(goal ros.Message, executeTimeout, preemptTimeout time.Duration) (Status, error)
+	// Wait until the goal is done.  A timeout of 0 waits forever.  Returns
+	// whether the goal is done.
+	WaitForResult(timeout time.Duration) bool
+	// The result of the goal, or nil if there is none yet.
+	GetResult() ros.Message
+	// The status of the goal.  RECALLING is reported as PENDING and
+	// PREEMPTING as ACTIVE.  LOST is returned when no goal is tracked.
+	GetState() Status
+	// The state of the goal as the client sees it.
+	GetSimpleState() SimpleGoalState
+	// The text the server gave with the goal's status.
+	GetGoalStatusText() string
+	// Ask the server to cancel the goal.
+	CancelGoal() error
+	// Ask the server to cancel every goal, from any client.
+	CancelAllGoals()
+	// Ask the server to cancel every goal stamped at or before stamp.
+	CancelGoalsAtAndBeforeTime(stamp ros.Time)
+	// Forget the goal without canceling it.
+	StopTrackingGoal()
+	Shutdown()
+}
+
+// A goal sent by a SimpleActionClient.
+type clientGoal struct {
+	id               actionlib_msgs.GoalID
+	state            CommState
+	simpleState      SimpleGoalState
+	status           actionlib_msgs.GoalStatus // Latest status from the server.
+	result           ros.Message
+	done             chan struct{} // Closed when the goal is done.
+	doneCallback     interface{}
+	activeCallback   interface{}
+	feedbackCallback interface{}
+}
+
+type defaultSimpleActionClient struct {
+	node       ros.Node
+	actionType ActionType
+
+	mutex          sync.Mutex
+	goal           *clientGoal
+	statusReceived bool
+
+	goalPub     ros.Publisher
+	cancelPub   ros.Publisher
+	statusSub   ros.Subscriber
+	resultSub   ros.Subscriber
+	feedbackSub ros.Subscriber
+	shutdown    sync.Once
+}
+
+// Create a client for the action named action.
+func NewSimpleActionClient(node ros.Node, action string, actionType ActionType) (SimpleActionClient, error) {
+	return newDefaultSimpleActionClient(node, action, actionType)
+}
+
+func newDefaultSimpleActionClient(node ros.Node, action string, actionType ActionType) (*defaultSimpleActionClient, error) {
+	if err := checkActionType(actionType); err != nil {
+		return nil, err
+	}
+	c := new(defaultSimpleActionClient)
+	c.node = node
+	c.actionType = actionType
+
+	var err error
+	if c.goalPub, err = advertise(node, action+"/goal", actionType.ActionGoalType(), ros.PublisherQueueSize(10)); err != nil {
+		return nil, err
+	}
+	if c.cancelPub, err = advertise(node, action+"/cancel", actionlib_msgs.MsgGoalID, ros.PublisherQueueSize(10)); err != nil {
+		c.Shutdown()
+		return nil, err
+	}
+	if c.statusSub, err = subscribe(node, action+"/status", actionlib_msgs.MsgGoalStatusArray, c.receiveStatus); err != nil {
+		c.Shutdown()
+		return nil, err
+	}
+	if c.resultSub, err = subscribe(node, action+"/result", actionType.ActionResultType(), c.receiveResult); err != nil {
+		c.Shutdown()
+		return nil, err
+	}
+	if c.feedbackSub, err = subscribe(node, action+"/feedback", actionType.ActionFeedbackType(), c.receiveFeedback); err != nil {
+		c.Shutdown()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *defaultSimpleActionClient) isServerConnected() bool {
+	c.mutex.Lock()
+	statusReceived := c.statusReceived
+	c.mutex.Unlock()
+	return statusReceived &&
+		c.goalPub.GetNumSubscribers() > 0 &&
+		c.cancelPub.GetNumSubscribers() > 0 &&
+		c.resultSub.GetNumPublishers() > 0 &&
+		c.feedbackSub.GetNumPublishers() > 0
+}
+
+func (c *defaultSimpleActionClient) WaitForServer(timeout time.Duration) bool {
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		deadline = time.After(timeout)
+	}
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for !c.isServerConnected() {
+		select {
+		case <-ticker.C:
+		case <-deadline:
+			return false
+		}
+	}
+	return true
+}
+
+func (c *defaultSimpleActionClient) SendGoal(goal ros.Message) error {
+	return c.SendGoalWithCallbacks(goal, nil, nil, nil)
+}
+
+func (c *defaultSimpleActionClient) SendGoalWithCallbacks(goal ros.Message, doneCallback, activeCallback, feedbackCallback interface{}) error {
+	if err := checkCallback("doneCallback", doneCallback, 2); err != nil {
+		return err
+	}
+	if err := checkCallback("activeCallback", activeCallback, 0); err != nil {
+		return err
+	}
+	if err := checkCallback("feedbackCallback", feedbackCallback, 1); err != nil {
+		return err
+	}
+	msg, err := newActionMessage(c.actionType.ActionGoalType(), "Goal", goal)
+	if err != nil {
+		return err
+	}
+	now := c.node.Now()
+	msg.header.Stamp = now
+	*msg.goalID = generateGoalID(c.node.Name(), now)
+
+	c.mutex.Lock()
+	c.goal = &clientGoal{
+		id:               *msg.goalID,
+		state:            CommWaitingForGoalAck,
+		simpleState:      SimplePending,
+		status:           actionlib_msgs.GoalStatus{GoalID: *msg.goalID, Status: uint8(Pending)},
+		done:             make(chan struct{}),
+		doneCallback:     doneCallback,
+		activeCallback:   activeCallback,
+		feedbackCallback: feedbackCallback,
+	}
+	c.mutex.Unlock()
+	c.goalPub.Publish(msg.msg)
+	return nil
+}
+
+func (c *defaultSimpleActionClient) SendGoalAndWait(goal ros.Message, executeTimeout, preemptTimeout time.Duration) (Status, error) {
+	if err := c.SendGoal(goal); err != nil {
+		return Lost, err
+	}
+	if !c.WaitForResult(executeTimeout) {
+		if err := c.CancelGoal(); err != nil {
+			return Lost, err
+		}
+		c.WaitForResult(preemptTimeout)
+	}
+	return c.GetState(), nil
+}
+
+// Move a goal through states.  Returns the callbacks to call once the mutex
+// is released.  The caller must hold c.mutex.
+func (c *defaultSimpleActionClient) transition(goal *clientGoal, states ...CommState) []func() {
+	var calls []func()
+	for _, state := range states {
+		goal.state = state
+		switch state {
+		case CommActive, CommPreempting:
+			if goal.simpleState == SimplePending {
+				goal.simpleState = SimpleActive
+				if goal.activeCallback != nil {
+					calls = append(calls, func() { invokeCallback(goal.activeCallback) })
+				}
+			}
+		case CommDone:
+			goal.simpleState = SimpleDone
+			close(goal.done)
+			if goal.doneCallback != nil {
+				status, result := Status(goal.status.Status), goal.result
+				calls = append(calls, func() { invokeCallback(goal.doneCallback, status, result) })
+			}
+		}
+	}
+	return calls
+}
+
+func runCalls(calls []func()) {
+	for _, call := range calls {
+		call()
+	}
+}
+
+func (c *defaultSimpleActionClient) receiveStatus(msg *actionlib_msgs.GoalStatusArray) {
+	c.mutex.Lock()
+	c.statusReceived = true
+	goal := c.goal
+	if goal == nil || goal.state == CommDone {
+		c.mutex.Unlock()
+		return
+	}
+	var calls []func()
+	found := false
+	for _, status := range msg.StatusList {
+		if status.GoalID.ID == goal.id.ID {
+			found = true
+			goal.status = status
+			calls = c.transition(goal, commTransitions[goal.state][Status(status.Status)]...)
+			break
+		}
+	}
+	// The server forgot a goal it had acknowledged without sending its
+	// result.
+	if !found && goal.state != CommWaitingForGoalAck && goal.state != CommWaitingForResult {
+		goal.status.Status = uint8(Lost)
+		calls = c.transition(goal, CommDone)
+	}
+	c.mutex.Unlock()
+	runCalls(calls)
+}
+
+func (c *defaultSimpleActionClient) receiveResult(msg ros.Message) {
+	fields, err := splitActionMessage(msg, "Result")
+	if err != nil {
+		c.node.Logger().Error(err)
+		return
+	}
+	c.mutex.Lock()
+	goal := c.goal
+	if goal == nil || goal.state == CommDone || fields.status.GoalID.ID != goal.id.ID {
+		c.mutex.Unlock()
+		return
+	}
+	goal.status = *fields.status
+	goal.result = fields.payload
+	var states []CommState
+	states = append(states, commTransitions[goal.state][Status(goal.status.Status)]...)
+	calls := c.transition(goal, append(states, CommDone)...)
+	c.mutex.Unlock()
+	runCalls(calls)
+}
+
+func (c *defaultSimpleActionClient) receiveFeedback(msg ros.Message) {
+	fields, err := splitActionMessage(msg, "Feedback")
+	if err != nil {
+		c.node.Logger().Error(err)
+		return
+	}
+	c.mutex.Lock()
+	goal := c.goal
+	if goal == nil || goal.state == CommDone || fields.status.GoalID.ID != goal.id.ID {
+		c.mutex.Unlock()
+		return
+	}
+	callback := goal.feedbackCallback
+	c.mutex.Unlock()
+	invokeCallback(callback, fields.payload)
+}
+
+func (c *defaultSimpleActionClient) WaitForResult(timeout time.Duration) bool {
+	c.mutex.Lock()
+	goal := c.goal
+	c.mutex.Unlock()
+	if goal == nil {
+		return false
+	}
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		deadline = time.After(timeout)
+	}
+	select {
+	case <-goal.done:
+		return true
+	case <-deadline:
+		return false
+	}
+}
+
+func (c *defaultSimpleActionClient) GetResult() ros.Message {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.goal == nil {
+		return nil
+	}
+	return c.goal.result
+}
+
+func (c *defaultSimpleActionClient) GetState() Status {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.goal == nil {
+		return Lost
+	}
+	switch status := Status(c.goal.status.Status); status {
+	case Recalling:
+		return Pending
+	case Preempting:
+		return Active
+	default:
+		return status
+	}
+}
+
+func (c *defaultSimpleActionClient) GetSimpleState() SimpleGoalState {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.goal == nil {
+		return SimpleDone
+	}
+	return c.goal.simpleState
+}
+
+func (c *defaultSimpleActionClient) GetGoalStatusText() string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.goal == nil {
+		return ""
+	}
+	return c.goal.status.Text
+}
+
+func (c *defaultSimpleActionClient) CancelGoal() error {
+	c.mutex.Lock()
+	goal := c.goal
+	if goal == nil {
+		c.mutex.Unlock()
+		return fmt.Errorf("no goal to cancel")
+	}
+	switch goal.state {
+	case CommWaitingForResult, CommDone, CommRecalling, CommPreempting:
+		// The server already knows the goal is finishing.
+		c.mutex.Unlock()
+		return nil
+	}
+	calls := c.transition(goal, CommWaitingForCancelAck)
+	id := goal.id
+	c.mutex.Unlock()
+	runCalls(calls)
+	c.cancelPub.Publish(&id)
+	return nil
+}
+
+func (c *defaultSimpleActionClient) CancelAllGoals() {
+	c.cancelPub.Publish(&actionlib_msgs.GoalID{})
+}
+
+func (c *defaultSimpleActionClient) CancelGoalsAtAndBeforeTime(stamp ros.Time) {
+	c.cancelPub.Publish(&actionlib_msgs.GoalID{Stamp: stamp})
+}
+
+func (c *defaultSimpleActionClient) StopTrackingGoal() {
+	c.mutex.Lock()
+	c.goal = nil
+	c.mutex.Unlock()
+}
+
+func (c *defaultSimpleActionClient) Shutdown() {
+	c.shutdown.Do(func() {
+		// Without a goal, the callbacks left on shared subscribers do
+		// nothing.
+		c.StopTrackingGoal()
+		for _, sub := range []ros.Subscriber{c.statusSub, c.resultSub, c.feedbackSub} {
+			if sub != nil {
+				release(sub)
+			}
+		}
+		for _, pub := range []ros.Publisher{c.goalPub, c.cancelPub} {
+			if pub != nil {
+				release(pub)
+			}
+		}
+	})
+}
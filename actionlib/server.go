@@ -0,0 +1,411 @@
+package actionlib
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ppg/rosgo/msgs/actionlib_msgs"
+	"github.com/ppg/rosgo/msgs/std_msgs"
+	"github.com/ppg/rosgo/ros"
+)
+
+// ActionServer receives goals and cancel requests for an action and reports
+// the status of its goals.
+type ActionServer interface {
+	Shutdown()
+}
+
+// ServerOption configures an action server.
+type ServerOption func(*serverOptions)
+
+type serverOptions struct {
+	statusFrequency   float64
+	statusListTimeout time.Duration
+}
+
+func newServerOptions(opts []ServerOption) serverOptions {
+	options := serverOptions{statusFrequency: 5, statusListTimeout: 5 * time.Second}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// StatusFrequency sets how many times a second the server publishes the
+// status of its goals when they don't change.  The default is 5.
+func StatusFrequency(hz float64) ServerOption {
+	return func(opts *serverOptions) {
+		opts.statusFrequency = hz
+	}
+}
+
+// StatusListTimeout sets how long finished goals are still reported in the
+// status list.  The default is 5 seconds.
+func StatusListTimeout(timeout time.Duration) ServerOption {
+	return func(opts *serverOptions) {
+		opts.statusListTimeout = timeout
+	}
+}
+
+// A goal known to the server.  handle is nil for goals the server only
+// knows of from a cancel request.
+type statusTracker struct {
+	status          actionlib_msgs.GoalStatus
+	handle          *ServerGoalHandle
+	destructionTime time.Time // When a finished goal is dropped from the list.
+}
+
+type defaultActionServer struct {
+	node           ros.Node
+	actionType     ActionType
+	goalCallback   func(*ServerGoalHandle)
+	cancelCallback func(*ServerGoalHandle)
+	options        serverOptions
+
+	mutex      sync.Mutex
+	statuses   []*statusTracker
+	lastCancel ros.Time
+
+	statusPub   ros.Publisher
+	resultPub   ros.Publisher
+	feedbackPub ros.Publisher
+	goalSub     ros.Subscriber
+	cancelSub   ros.Subscriber
+	done        chan struct{}
+	shutdown    sync.Once
+}
+
+// Create a server for the action named action.  goalCallback is called with
+// each new goal, which it should accept or reject, and cancelCallback with
+// each goal a client asked to cancel.  Both are called from the node's Spin.
+func NewActionServer(node ros.Node, action string, actionType ActionType,
+	goalCallback, cancelCallback func(*ServerGoalHandle), opts ...ServerOption) (ActionServer, error) {
+	return newDefaultActionServer(node, action, actionType, goalCallback, cancelCallback, newServerOptions(opts))
+}
+
+func newDefaultActionServer(node ros.Node, action string, actionType ActionType,
+	goalCallback, cancelCallback func(*ServerGoalHandle), options serverOptions) (*defaultActionServer, error) {
+	if err := checkActionType(actionType); err != nil {
+		return nil, err
+	}
+	s := new(defaultActionServer)
+	s.node = node
+	s.actionType = actionType
+	s.goalCallback = goalCallback
+	s.cancelCallback = cancelCallback
+	s.options = options
+	s.done = make(chan struct{})
+
+	var err error
+	if s.statusPub, err = advertise(node, action+"/status", actionlib_msgs.MsgGoalStatusArray, ros.Latch(), ros.PublisherQueueSize(50)); err != nil {
+		return nil, err
+	}
+	if s.resultPub, err = advertise(node, action+"/result", actionType.ActionResultType(), ros.PublisherQueueSize(50)); err != nil {
+		s.Shutdown()
+		return nil, err
+	}
+	if s.feedbackPub, err = advertise(node, action+"/feedback", actionType.ActionFeedbackType(), ros.PublisherQueueSize(50)); err != nil {
+		s.Shutdown()
+		return nil, err
+	}
+	if s.goalSub, err = subscribe(node, action+"/goal", actionType.ActionGoalType(), s.receiveGoal); err != nil {
+		s.Shutdown()
+		return nil, err
+	}
+	if s.cancelSub, err = subscribe(node, action+"/cancel", actionlib_msgs.MsgGoalID, s.receiveCancel); err != nil {
+		s.Shutdown()
+		return nil, err
+	}
+	s.publishStatus()
+	go s.publishStatusLoop()
+	return s, nil
+}
+
+func (s *defaultActionServer) publishStatusLoop() {
+	if s.options.statusFrequency <= 0 {
+		return
+	}
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / s.options.statusFrequency))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.publishStatus()
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// Publish the status of every goal, first dropping finished goals which
+// have been reported for long enough.
+func (s *defaultActionServer) publishStatus() {
+	s.mutex.Lock()
+	now := time.Now()
+	msg := &actionlib_msgs.GoalStatusArray{Header: std_msgs.Header{Stamp: s.node.Now()}}
+	statuses := s.statuses[:0]
+	for _, tracker := range s.statuses {
+		if !tracker.destructionTime.IsZero() && now.After(tracker.destructionTime) {
+			continue
+		}
+		statuses = append(statuses, tracker)
+		msg.StatusList = append(msg.StatusList, tracker.status)
+	}
+	for i := len(statuses); i < len(s.statuses); i++ {
+		s.statuses[i] = nil
+	}
+	s.statuses = statuses
+	s.mutex.Unlock()
+	s.statusPub.Publish(msg)
+}
+
+func (s *defaultActionServer) publishResult(status actionlib_msgs.GoalStatus, result ros.Message) error {
+	msg, err := newActionMessage(s.actionType.ActionResultType(), "Result", result)
+	if err != nil {
+		return err
+	}
+	msg.header.Stamp = s.node.Now()
+	*msg.status = status
+	s.resultPub.Publish(msg.msg)
+	return nil
+}
+
+func (s *defaultActionServer) publishFeedback(status actionlib_msgs.GoalStatus, feedback ros.Message) error {
+	msg, err := newActionMessage(s.actionType.ActionFeedbackType(), "Feedback", feedback)
+	if err != nil {
+		return err
+	}
+	msg.header.Stamp = s.node.Now()
+	*msg.status = status
+	s.feedbackPub.Publish(msg.msg)
+	return nil
+}
+
+func (s *defaultActionServer) receiveGoal(msg ros.Message) {
+	if s.isShutdown() {
+		// The subscriber is shared with another server.
+		return
+	}
+	fields, err := splitActionMessage(msg, "Goal")
+	if err != nil {
+		s.node.Logger().Error(err)
+		return
+	}
+	id := *fields.goalID
+	if id.Stamp.IsZero() {
+		id.Stamp = s.node.Now()
+	}
+	if id.ID == "" {
+		id = generateGoalID(s.node.Name(), id.Stamp)
+	}
+
+	s.mutex.Lock()
+	for _, tracker := range s.statuses {
+		if tracker.status.GoalID.ID != id.ID {
+			continue
+		}
+		// A cancel request for the goal arrived before the goal itself.
+		// Otherwise this is a goal we already have.
+		if Status(tracker.status.Status) == Recalling {
+			tracker.status.Status = uint8(Recalled)
+			tracker.destructionTime = time.Now().Add(s.options.statusListTimeout)
+			status := tracker.status
+			s.mutex.Unlock()
+			if err := s.publishResult(status, nil); err != nil {
+				s.node.Logger().Error(err)
+			}
+			s.publishStatus()
+			return
+		}
+		s.mutex.Unlock()
+		return
+	}
+	tracker := &statusTracker{status: actionlib_msgs.GoalStatus{GoalID: id, Status: uint8(Pending)}}
+	handle := &ServerGoalHandle{server: s, goal: fields.payload, tracker: tracker}
+	tracker.handle = handle
+	s.statuses = append(s.statuses, tracker)
+	canceled := !s.lastCancel.IsZero() && id.Stamp.Cmp(s.lastCancel) <= 0
+	s.mutex.Unlock()
+
+	if canceled {
+		handle.SetCanceled(nil, "This goal handle was canceled by the action server because its timestamp is before the timestamp of the last cancel request")
+		return
+	}
+	if s.goalCallback != nil {
+		s.goalCallback(handle)
+	}
+}
+
+// Handle a cancel request.  An empty ID and zero stamp cancel every goal;
+// otherwise the goal with the ID and every goal stamped at or before the
+// stamp are canceled.
+func (s *defaultActionServer) receiveCancel(id *actionlib_msgs.GoalID) {
+	if s.isShutdown() {
+		return
+	}
+	cancelAll := id.ID == "" && id.Stamp.IsZero()
+	var canceled []*ServerGoalHandle
+	found := false
+
+	s.mutex.Lock()
+	for _, tracker := range s.statuses {
+		matchesID := id.ID != "" && tracker.status.GoalID.ID == id.ID
+		found = found || matchesID
+		if !cancelAll && !matchesID && (id.Stamp.IsZero() || tracker.status.GoalID.Stamp.Cmp(id.Stamp) > 0) {
+			continue
+		}
+		if tracker.handle != nil && tracker.handle.setCancelRequested() {
+			canceled = append(canceled, tracker.handle)
+		}
+	}
+	// Remember a cancel request for a goal which hasn't arrived yet, so the
+	// goal is canceled when it does.
+	if id.ID != "" && !found {
+		s.statuses = append(s.statuses, &statusTracker{
+			status:          actionlib_msgs.GoalStatus{GoalID: *id, Status: uint8(Recalling)},
+			destructionTime: time.Now().Add(s.options.statusListTimeout),
+		})
+	}
+	if id.Stamp.Cmp(s.lastCancel) > 0 {
+		s.lastCancel = id.Stamp
+	}
+	s.mutex.Unlock()
+
+	if len(canceled) > 0 {
+		s.publishStatus()
+	}
+	if s.cancelCallback != nil {
+		for _, handle := range canceled {
+			s.cancelCallback(handle)
+		}
+	}
+}
+
+func (s *defaultActionServer) isShutdown() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *defaultActionServer) Shutdown() {
+	s.shutdown.Do(func() {
+		close(s.done)
+		for _, sub := range []ros.Subscriber{s.goalSub, s.cancelSub} {
+			if sub != nil {
+				release(sub)
+			}
+		}
+		for _, pub := range []ros.Publisher{s.statusPub, s.resultPub, s.feedbackPub} {
+			if pub != nil {
+				release(pub)
+			}
+		}
+	})
+}
+
+// ServerGoalHandle is a goal received by an ActionServer.  Its methods move
+// the goal through the server side of the goal state machine and publish
+// its new status, and its result when it finishes.
+type ServerGoalHandle struct {
+	server  *defaultActionServer
+	goal    ros.Message
+	tracker *statusTracker
+}
+
+// The goal message, such as *control_msgs.FollowJointTrajectoryGoal.
+func (h *ServerGoalHandle) GetGoal() ros.Message {
+	return h.goal
+}
+
+func (h *ServerGoalHandle) GetGoalID() actionlib_msgs.GoalID {
+	h.server.mutex.Lock()
+	defer h.server.mutex.Unlock()
+	return h.tracker.status.GoalID
+}
+
+func (h *ServerGoalHandle) GetGoalStatus() actionlib_msgs.GoalStatus {
+	h.server.mutex.Lock()
+	defer h.server.mutex.Unlock()
+	return h.tracker.status
+}
+
+// Move the goal to the status transitions maps its current status to, and
+// publish the result if it is finished.
+func (h *ServerGoalHandle) transition(action string, transitions map[Status]Status, result ros.Message, text string) error {
+	s := h.server
+	s.mutex.Lock()
+	current := Status(h.tracker.status.Status)
+	next, ok := transitions[current]
+	if !ok {
+		s.mutex.Unlock()
+		return fmt.Errorf("cannot %s goal %s with status %s", action, h.tracker.status.GoalID.ID, current)
+	}
+	h.tracker.status.Status = uint8(next)
+	h.tracker.status.Text = text
+	if next.IsTerminal() {
+		h.tracker.destructionTime = time.Now().Add(s.options.statusListTimeout)
+	}
+	status := h.tracker.status
+	s.mutex.Unlock()
+
+	if next.IsTerminal() {
+		if err := s.publishResult(status, result); err != nil {
+			return err
+		}
+	}
+	s.publishStatus()
+	return nil
+}
+
+// Accept a pending goal.  A goal whose cancellation was requested before
+// it was accepted becomes PREEMPTING.
+func (h *ServerGoalHandle) SetAccepted(text string) error {
+	return h.transition("accept", map[Status]Status{Pending: Active, Recalling: Preempting}, nil, text)
+}
+
+// Reject a goal which hasn't been accepted.
+func (h *ServerGoalHandle) SetRejected(result ros.Message, text string) error {
+	return h.transition("reject", map[Status]Status{Pending: Rejected, Recalling: Rejected}, result, text)
+}
+
+// Confirm the cancellation of a goal: goals which weren't accepted become
+// RECALLED and goals which were become PREEMPTED.
+func (h *ServerGoalHandle) SetCanceled(result ros.Message, text string) error {
+	return h.transition("cancel", map[Status]Status{
+		Pending: Recalled, Recalling: Recalled, Active: Preempted, Preempting: Preempted,
+	}, result, text)
+}
+
+// Finish an accepted goal which failed.
+func (h *ServerGoalHandle) SetAborted(result ros.Message, text string) error {
+	return h.transition("abort", map[Status]Status{Active: Aborted, Preempting: Aborted}, result, text)
+}
+
+// Finish an accepted goal which was achieved.
+func (h *ServerGoalHandle) SetSucceeded(result ros.Message, text string) error {
+	return h.transition("succeed", map[Status]Status{Active: Succeeded, Preempting: Succeeded}, result, text)
+}
+
+// Publish feedback, such as *control_msgs.FollowJointTrajectoryFeedback,
+// about the goal.
+func (h *ServerGoalHandle) PublishFeedback(feedback ros.Message) error {
+	return h.server.publishFeedback(h.GetGoalStatus(), feedback)
+}
+
+// Mark the goal as having a cancel request.  Returns whether the status
+// changed.  The caller must hold the server's mutex.
+func (h *ServerGoalHandle) setCancelRequested() bool {
+	switch Status(h.tracker.status.Status) {
+	case Pending:
+		h.tracker.status.Status = uint8(Recalling)
+	case Active:
+		h.tracker.status.Status = uint8(Preempting)
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,281 @@
+package actionlib
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/ppg/rosgo/ros"
+)
+
+// SimpleActionServer works on one goal at a time.  A new goal preempts the
+// current one, and goals older than the current or next goal are canceled.
+type SimpleActionServer interface {
+	// Whether a goal is waiting to be accepted.
+	IsNewGoalAvailable() bool
+	// Whether the current goal should stop, because it was canceled or a
+	// new goal arrived.
+	IsPreemptRequested() bool
+	// Whether the current goal is ACTIVE or PREEMPTING.
+	IsActive() bool
+	// Accept the waiting goal, canceling the current goal if it is still
+	// active, and return it.  Only needed without an execute callback.
+	AcceptNewGoal() (ros.Message, error)
+	// Finish the current goal.  A nil result sends an empty result.
+	SetSucceeded(result ros.Message, text string) error
+	SetAborted(result ros.Message, text string) error
+	SetPreempted(result ros.Message, text string) error
+	// Publish feedback about the current goal.
+	PublishFeedback(feedback ros.Message) error
+	// Call callback from the node's Spin when a new goal arrives.
+	RegisterGoalCallback(callback func())
+	// Call callback from the node's Spin when preemption of the current
+	// goal is requested.
+	RegisterPreemptCallback(callback func())
+	Shutdown()
+}
+
+const canceledByNewGoal = "This goal was canceled because another goal was received by the simple action server"
+
+type defaultSimpleActionServer struct {
+	server          *defaultActionServer
+	executeCallback interface{}
+
+	mutex                 sync.Mutex
+	currentGoal           *ServerGoalHandle
+	nextGoal              *ServerGoalHandle
+	newGoal               bool
+	preemptRequest        bool
+	newGoalPreemptRequest bool
+	goalCallback          func()
+	preemptCallback       func()
+
+	newGoalChan chan struct{}
+	done        chan struct{}
+	shutdown    sync.Once
+}
+
+// Create a simple server for the action named action.  executeCallback,
+// such as func(goal *control_msgs.FollowJointTrajectoryGoal), is called in
+// its own goroutine with each goal once it is accepted, and must finish the
+// goal with SetSucceeded, SetAborted or SetPreempted before returning.  If
+// executeCallback is nil, goals are accepted with AcceptNewGoal instead,
+// usually from a goal callback.
+func NewSimpleActionServer(node ros.Node, action string, actionType ActionType,
+	executeCallback interface{}, opts ...ServerOption) (SimpleActionServer, error) {
+	if err := checkCallback("executeCallback", executeCallback, 1); err != nil {
+		return nil, err
+	}
+	s := new(defaultSimpleActionServer)
+	s.executeCallback = executeCallback
+	s.newGoalChan = make(chan struct{}, 1)
+	s.done = make(chan struct{})
+	server, err := newDefaultActionServer(node, action, actionType, s.receiveGoal, s.receivePreempt, newServerOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	s.server = server
+	if executeCallback != nil {
+		go s.executeLoop()
+	}
+	return s, nil
+}
+
+// Whether the current goal is ACTIVE or PREEMPTING.  The caller must hold
+// s.mutex.
+func (s *defaultSimpleActionServer) isActive() bool {
+	if s.currentGoal == nil {
+		return false
+	}
+	status := Status(s.currentGoal.GetGoalStatus().Status)
+	return status == Active || status == Preempting
+}
+
+func (s *defaultSimpleActionServer) receiveGoal(goal *ServerGoalHandle) {
+	stamp := goal.GetGoalID().Stamp
+	s.mutex.Lock()
+	newer := func(other *ServerGoalHandle) bool {
+		if other == nil {
+			return true
+		}
+		otherStamp := other.GetGoalID().Stamp
+		return stamp.Cmp(otherStamp) >= 0
+	}
+	if !newer(s.currentGoal) || !newer(s.nextGoal) {
+		s.mutex.Unlock()
+		goal.SetCanceled(nil, canceledByNewGoal)
+		return
+	}
+	// The goal waiting to be accepted is replaced.
+	var replaced *ServerGoalHandle
+	if s.nextGoal != nil && s.nextGoal != s.currentGoal {
+		replaced = s.nextGoal
+	}
+	s.nextGoal = goal
+	s.newGoal = true
+	s.newGoalPreemptRequest = false
+	var preemptCallback func()
+	if s.isActive() {
+		s.preemptRequest = true
+		preemptCallback = s.preemptCallback
+	}
+	goalCallback := s.goalCallback
+	s.mutex.Unlock()
+
+	if replaced != nil {
+		replaced.SetCanceled(nil, canceledByNewGoal)
+	}
+	if preemptCallback != nil {
+		preemptCallback()
+	}
+	if goalCallback != nil {
+		goalCallback()
+	}
+	select {
+	case s.newGoalChan <- struct{}{}:
+	default:
+	}
+}
+
+func (s *defaultSimpleActionServer) receivePreempt(goal *ServerGoalHandle) {
+	s.mutex.Lock()
+	var preemptCallback func()
+	if goal == s.currentGoal {
+		s.preemptRequest = true
+		preemptCallback = s.preemptCallback
+	} else if goal == s.nextGoal {
+		s.newGoalPreemptRequest = true
+	}
+	s.mutex.Unlock()
+	if preemptCallback != nil {
+		preemptCallback()
+	}
+}
+
+func (s *defaultSimpleActionServer) executeLoop() {
+	logger := s.server.node.Logger()
+	for {
+		select {
+		case <-s.newGoalChan:
+		case <-s.done:
+			return
+		}
+		for s.IsNewGoalAvailable() {
+			goal, err := s.AcceptNewGoal()
+			if err != nil {
+				logger.Error(err)
+				break
+			}
+			invokeCallback(s.executeCallback, goal)
+			if s.IsActive() {
+				logger.Warn("Your executeCallback did not set the goal to a terminal status. This is a bug in your ActionServer implementation. Fix your code! For now, the ActionServer will set this goal to aborted")
+				if err := s.SetAborted(nil, "This goal was aborted by the simple action server. The user should have set a terminal status on this goal and did not"); err != nil {
+					logger.Error(err)
+				}
+			}
+		}
+	}
+}
+
+func (s *defaultSimpleActionServer) IsNewGoalAvailable() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.newGoal
+}
+
+func (s *defaultSimpleActionServer) IsPreemptRequested() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.preemptRequest
+}
+
+func (s *defaultSimpleActionServer) IsActive() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.isActive()
+}
+
+func (s *defaultSimpleActionServer) AcceptNewGoal() (ros.Message, error) {
+	s.mutex.Lock()
+	if !s.newGoal || s.nextGoal == nil {
+		s.mutex.Unlock()
+		return nil, errors.New("no new goal to accept")
+	}
+	var preempted *ServerGoalHandle
+	if s.isActive() && s.currentGoal != s.nextGoal {
+		preempted = s.currentGoal
+	}
+	s.currentGoal = s.nextGoal
+	s.newGoal = false
+	s.preemptRequest = s.newGoalPreemptRequest
+	s.newGoalPreemptRequest = false
+	goal := s.currentGoal
+	s.mutex.Unlock()
+
+	if preempted != nil {
+		preempted.SetCanceled(nil, canceledByNewGoal)
+	}
+	if err := goal.SetAccepted("This goal has been accepted by the simple action server"); err != nil {
+		return nil, err
+	}
+	return goal.GetGoal(), nil
+}
+
+func (s *defaultSimpleActionServer) current() (*ServerGoalHandle, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.currentGoal == nil {
+		return nil, errors.New("no current goal")
+	}
+	return s.currentGoal, nil
+}
+
+func (s *defaultSimpleActionServer) SetSucceeded(result ros.Message, text string) error {
+	goal, err := s.current()
+	if err != nil {
+		return err
+	}
+	return goal.SetSucceeded(result, text)
+}
+
+func (s *defaultSimpleActionServer) SetAborted(result ros.Message, text string) error {
+	goal, err := s.current()
+	if err != nil {
+		return err
+	}
+	return goal.SetAborted(result, text)
+}
+
+func (s *defaultSimpleActionServer) SetPreempted(result ros.Message, text string) error {
+	goal, err := s.current()
+	if err != nil {
+		return err
+	}
+	return goal.SetCanceled(result, text)
+}
+
+func (s *defaultSimpleActionServer) PublishFeedback(feedback ros.Message) error {
+	goal, err := s.current()
+	if err != nil {
+		return err
+	}
+	return goal.PublishFeedback(feedback)
+}
+
+func (s *defaultSimpleActionServer) RegisterGoalCallback(callback func()) {
+	s.mutex.Lock()
+	s.goalCallback = callback
+	s.mutex.Unlock()
+}
+
+func (s *defaultSimpleActionServer) RegisterPreemptCallback(callback func()) {
+	s.mutex.Lock()
+	s.preemptCallback = callback
+	s.mutex.Unlock()
+}
+
+func (s *defaultSimpleActionServer) Shutdown() {
+	s.shutdown.Do(func() {
+		close(s.done)
+		s.server.Shutdown()
+	})
+}
@@ -61,9 +61,34 @@ std_msgs/ColorRGBA c
 uint32[] dyn_ary
 uint32[2] fix_ary
 #std_msgs/ColorRGBA[] msg_ary
+
+================================================================================
+MSG: std_msgs/Header
+# Standard metadata for higher-level stamped data types.
+# This is generally used to communicate timestamped data 
+# in a particular coordinate frame.
+# 
+# sequence ID: consecutively increasing ID 
+uint32 seq
+#Two-integer timestamp that is expressed as:
+# * stamp.sec: seconds (stamp_secs) since epoch (in Python the variable is called 'secs')
+# * stamp.nsec: nanoseconds since stamp_secs (in Python the variable is called 'nsecs')
+# time-handling sugar is provided by the client library
+time stamp
+#Frame this data is associated with
+# 0: no frame
+# 1: global frame
+string frame_id
+
+================================================================================
+MSG: std_msgs/ColorRGBA
+float32 r
+float32 g
+float32 b
+float32 a
 `,
 		"msgs/AllFieldTypes",
-		"d001ba01d11826b42fc4dbbd1ffacb2c",
+		"5406fac98ad8897d5c798fda29d3f362",
 	}
 )
 
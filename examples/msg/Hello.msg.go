@@ -38,7 +38,7 @@ var (
 		`string data
 `,
 		"msgs/Hello",
-		"5b89344b4d080ca499c0648063c40738",
+		"992ce8a1687cec8c8bd883ec73ca41d1",
 	}
 )
 
@@ -30,7 +30,7 @@ func (t *_SrvAddTwoInts) NewService() ros.Service {
 var (
 	SrvAddTwoInts = &_SrvAddTwoInts{
 		"srvs/AddTwoInts",
-		"99a935100584c0b9379c3804982ec212",
+		"f0b6d69ea10b0cf210cb349d58d59e8f",
 		`int32 a
 int32 b
 ---
@@ -81,7 +81,7 @@ var (
 int32 b
 `,
 		"srvs/AddTwoIntsRequest",
-		"81c207b89fc46a3422318ff3ded86fd3",
+		"ef8322123148e475e3e93a1f609b2f70",
 	}
 )
 
@@ -148,7 +148,7 @@ var (
 int32 sum
 `,
 		"srvs/AddTwoIntsResponse",
-		"7d4aa90c6e313eab4e41216cd69bbb1f",
+		"0ba699c25c9418c0366f3595c0c8e8ec",
 	}
 )
 
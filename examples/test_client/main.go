@@ -15,13 +15,20 @@ func main() {
 		os.Exit(1)
 	}
 
-	node := ros.NewNode("client")
+	node, err := ros.NewNode("client")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 	defer node.Shutdown()
 	logger := node.Logger()
 	logger.SetSeverity(ros.LogLevelDebug)
-	cli := node.NewServiceClient("/add_two_ints", srvs.SrvAddTwoInts)
+	cli, err := node.NewServiceClient("/add_two_ints", srvs.SrvAddTwoInts)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 	defer cli.Shutdown()
-	var err error
 	var a, b int64
 	a, err = strconv.ParseInt(os.Args[1], 10, 32)
 	if err != nil {
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/ppg/rosgo/examples/msg"
 	"github.com/ppg/rosgo/ros"
@@ -12,9 +13,16 @@ func callback(msg *msgs.Hello) {
 }
 
 func main() {
-	node := ros.NewNode("/listener")
+	node, err := ros.NewNode("/listener")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 	defer node.Shutdown()
 	node.Logger().SetSeverity(ros.LogLevelDebug)
-	node.NewSubscriber("/chatter", msgs.MsgHello, callback)
+	if _, err := node.NewSubscriber("/chatter", msgs.MsgHello, callback); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 	node.Spin()
 }
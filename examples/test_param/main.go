@@ -7,7 +7,10 @@ import (
 )
 
 func main() {
-	node := ros.NewNode("/test_param")
+	node, err := ros.NewNode("/test_param")
+	if err != nil {
+		log.Fatal(err)
+	}
 	defer node.Shutdown()
 
 	if hasParam, err := node.HasParam("/rosdistro"); err != nil {
@@ -15,13 +15,17 @@ func callback(srv *srvs.AddTwoInts) error {
 }
 
 func main() {
-	node := ros.NewNode("server")
+	node, err := ros.NewNode("server")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 	defer node.Shutdown()
 	logger := node.Logger()
 	logger.SetSeverity(ros.LogLevelDebug)
-	server := node.NewServiceServer("/add_two_ints", srvs.SrvAddTwoInts, callback)
-	if server == nil {
-		fmt.Println("Failed to initialize '/add_two_ints' service server")
+	server, err := node.NewServiceServer("/add_two_ints", srvs.SrvAddTwoInts, callback)
+	if err != nil {
+		fmt.Println(err)
 		os.Exit(1)
 	}
 	defer server.Shutdown()
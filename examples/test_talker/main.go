@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/ppg/rosgo/examples/msg"
@@ -9,10 +10,18 @@ import (
 )
 
 func main() {
-	node := ros.NewNode("/talker")
+	node, err := ros.NewNode("/talker")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 	defer node.Shutdown()
 	node.Logger().SetSeverity(ros.LogLevelDebug)
-	pub := node.NewPublisher("/chatter", msgs.MsgHello)
+	pub, err := node.NewPublisher("/chatter", msgs.MsgHello)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 
 	for node.OK() {
 		node.SpinOnce()
@@ -0,0 +1,459 @@
+// Package master implements a ROS master in Go: the Master API, which keeps
+// track of the publishers, subscribers and services of nodes, and the
+// Parameter Server API.  It can stand in for roscore, and tests can start one
+// in-process on a random port.
+package master
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ppg/rosgo/xmlrpc"
+)
+
+const (
+	apiStatusError   int32 = -1
+	apiStatusFailure int32 = 0
+	apiStatusSuccess int32 = 1
+)
+
+// Caller ID the master uses when it calls slave APIs.
+const masterCallerId = "/master"
+
+// Time the master allows a slave API call before giving up on it.
+const slaveCallTimeout = 5 * time.Second
+
+type service struct {
+	callerId string
+	uri      string
+}
+
+type slaveCall struct {
+	method string
+	args   []interface{}
+}
+
+// Master is a running ROS master.  All of its state is guarded by mutex;
+// calls to slave APIs are made from separate goroutines so a slow node can't
+// hold up the master.
+type Master struct {
+	mutex            sync.Mutex
+	uri              string
+	server           *http.Server
+	ctx              context.Context // Canceled by Shutdown.
+	cancel           context.CancelFunc
+	waitGroup        sync.WaitGroup      // Goroutines making slave API calls.
+	nodes            map[string]string   // Caller ID to slave API URI.
+	topicTypes       map[string]string   // Topic to message type.
+	publishers       map[string][]string // Topic to caller IDs in registration order.
+	subscribers      map[string][]string // Topic to caller IDs in registration order.
+	services         map[string]service
+	params           map[string]interface{}
+	paramSubscribers map[string][]string    // Parameter namespace, ending in '/', to caller IDs.
+	pendingCalls     map[string][]slaveCall // Slave API URI to calls not yet made.
+}
+
+// Start a master listening on address, such as ":11311".  Use port 0 to
+// listen on a random port, and Uri to find out which one was chosen.
+func Start(address string) (*Master, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	m := newMaster()
+	tcpAddr := listener.Addr().(*net.TCPAddr)
+	m.uri = fmt.Sprintf("http://%s/", net.JoinHostPort(advertisedHost(tcpAddr.IP), strconv.Itoa(tcpAddr.Port)))
+	m.server = &http.Server{Handler: m.handler()}
+	go m.server.Serve(listener)
+	return m, nil
+}
+
+func newMaster() *Master {
+	m := new(Master)
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+	m.nodes = make(map[string]string)
+	m.topicTypes = make(map[string]string)
+	m.publishers = make(map[string][]string)
+	m.subscribers = make(map[string][]string)
+	m.services = make(map[string]service)
+	m.params = make(map[string]interface{})
+	m.paramSubscribers = make(map[string][]string)
+	m.pendingCalls = make(map[string][]slaveCall)
+	return m
+}
+
+// Choose the host name to put in the master URI.  A master listening on all
+// interfaces advertises ROS_HOSTNAME, ROS_IP or the host name, in that
+// order.
+func advertisedHost(ip net.IP) string {
+	if ip != nil && !ip.IsUnspecified() {
+		return ip.String()
+	}
+	if host := os.Getenv("ROS_HOSTNAME"); host != "" {
+		return host
+	}
+	if host := os.Getenv("ROS_IP"); host != "" {
+		return host
+	}
+	if host, err := os.Hostname(); err == nil {
+		return host
+	}
+	return "localhost"
+}
+
+// The XML-RPC URI of the master, suitable for ROS_MASTER_URI.
+func (m *Master) Uri() string {
+	return m.uri
+}
+
+// Stop accepting API calls, close the connections of API clients and abandon
+// calls to slave APIs, returning once none are being made.
+func (m *Master) Shutdown() {
+	m.mutex.Lock()
+	m.cancel()
+	m.mutex.Unlock()
+	m.server.Close()
+	m.waitGroup.Wait()
+}
+
+func (m *Master) handler() *xmlrpc.Handler {
+	return xmlrpc.NewHandler(map[string]xmlrpc.Method{
+		"registerService":      m.registerService,
+		"unregisterService":    m.unregisterService,
+		"registerSubscriber":   m.registerSubscriber,
+		"unregisterSubscriber": m.unregisterSubscriber,
+		"registerPublisher":    m.registerPublisher,
+		"unregisterPublisher":  m.unregisterPublisher,
+		"lookupNode":           m.lookupNode,
+		"lookupService":        m.lookupService,
+		"getPublishedTopics":   m.getPublishedTopics,
+		"getTopicTypes":        m.getTopicTypes,
+		"getSystemState":       m.getSystemState,
+		"getUri":               m.getUri,
+		"getPid":               m.getPid,
+		"deleteParam":          m.deleteParam,
+		"setParam":             m.setParam,
+		"getParam":             m.getParam,
+		"searchParam":          m.searchParam,
+		"subscribeParam":       m.subscribeParam,
+		"unsubscribeParam":     m.unsubscribeParam,
+		"hasParam":             m.hasParam,
+		"getParamNames":        m.getParamNames,
+	})
+}
+
+// Build XMLRPC ready array from ROS API result triplet.
+func rosApiResult(code int32, message string, value interface{}) (interface{}, error) {
+	return []interface{}{code, message, value}, nil
+}
+
+// Queue a call to the slave API at uri.  Calls to one slave are made in
+// order from a goroutine which lives while there are calls to make.  Calls
+// queued after Shutdown are dropped.  The caller must hold m.mutex.
+func (m *Master) callSlave(uri string, method string, args ...interface{}) {
+	if m.ctx.Err() != nil {
+		return
+	}
+	calls, running := m.pendingCalls[uri]
+	m.pendingCalls[uri] = append(calls, slaveCall{method, args})
+	if !running {
+		m.waitGroup.Add(1)
+		go m.runSlaveCalls(uri)
+	}
+}
+
+func (m *Master) runSlaveCalls(uri string) {
+	defer m.waitGroup.Done()
+	for {
+		m.mutex.Lock()
+		calls := m.pendingCalls[uri]
+		if len(calls) == 0 || m.ctx.Err() != nil {
+			delete(m.pendingCalls, uri)
+			m.mutex.Unlock()
+			return
+		}
+		m.pendingCalls[uri] = nil
+		m.mutex.Unlock()
+
+		for _, call := range calls {
+			if m.ctx.Err() != nil {
+				break
+			}
+			ctx, cancel := context.WithTimeout(m.ctx, slaveCallTimeout)
+			if _, err := xmlrpc.CallContext(ctx, uri, call.method, call.args...); err != nil {
+				log.Printf("Failed to call %s on %s: %s", call.method, uri, err)
+			}
+			cancel()
+		}
+	}
+}
+
+// Record the slave API of a node.  A node which registers again with a
+// different API replaces the old one, which is told to shut down.  The
+// caller must hold m.mutex.
+func (m *Master) registerNode(callerId string, callerApi string) {
+	if api, ok := m.nodes[callerId]; ok && api != callerApi {
+		m.removeNode(callerId)
+		m.callSlave(api, "shutdown", masterCallerId, fmt.Sprintf("new node registered with same name %s", callerId))
+	}
+	m.nodes[callerId] = callerApi
+}
+
+// Drop every registration of a node.  The caller must hold m.mutex.
+func (m *Master) removeNode(callerId string) {
+	for topic, callerIds := range m.publishers {
+		if contains(callerIds, callerId) {
+			m.setRegistrations(m.publishers, topic, remove(callerIds, callerId))
+			m.notifySubscribers(topic)
+		}
+	}
+	for topic, callerIds := range m.subscribers {
+		m.setRegistrations(m.subscribers, topic, remove(callerIds, callerId))
+	}
+	for key, callerIds := range m.paramSubscribers {
+		m.setRegistrations(m.paramSubscribers, key, remove(callerIds, callerId))
+	}
+	for name, s := range m.services {
+		if s.callerId == callerId {
+			delete(m.services, name)
+		}
+	}
+	delete(m.nodes, callerId)
+}
+
+// Forget a node once it has nothing registered.  The caller must hold
+// m.mutex.
+func (m *Master) pruneNode(callerId string) {
+	for _, registrations := range []map[string][]string{m.publishers, m.subscribers, m.paramSubscribers} {
+		for _, callerIds := range registrations {
+			if contains(callerIds, callerId) {
+				return
+			}
+		}
+	}
+	for _, s := range m.services {
+		if s.callerId == callerId {
+			return
+		}
+	}
+	delete(m.nodes, callerId)
+}
+
+func (m *Master) setRegistrations(registrations map[string][]string, key string, callerIds []string) {
+	if len(callerIds) == 0 {
+		delete(registrations, key)
+	} else {
+		registrations[key] = callerIds
+	}
+}
+
+// Slave API URIs of the given nodes.  The caller must hold m.mutex.
+func (m *Master) apis(callerIds []string) []string {
+	apis := []string{}
+	for _, callerId := range callerIds {
+		apis = append(apis, m.nodes[callerId])
+	}
+	return apis
+}
+
+// Send the current publishers of topic to its subscribers.  The caller must
+// hold m.mutex.
+func (m *Master) notifySubscribers(topic string) {
+	publisherApis := m.apis(m.publishers[topic])
+	for _, callerId := range m.subscribers[topic] {
+		m.callSlave(m.nodes[callerId], "publisherUpdate", masterCallerId, topic, publisherApis)
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+func remove(list []string, s string) []string {
+	var result []string
+	for _, x := range list {
+		if x != s {
+			result = append(result, x)
+		}
+	}
+	return result
+}
+
+func (m *Master) registerService(callerId string, serviceName string, serviceApi string, callerApi string) (interface{}, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.registerNode(callerId, callerApi)
+	m.services[serviceName] = service{callerId, serviceApi}
+	return rosApiResult(apiStatusSuccess, fmt.Sprintf("Registered [%s] as provider of [%s]", callerId, serviceName), int32(1))
+}
+
+func (m *Master) unregisterService(callerId string, serviceName string, serviceApi string) (interface{}, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	s, ok := m.services[serviceName]
+	if !ok || s.callerId != callerId || s.uri != serviceApi {
+		return rosApiResult(apiStatusSuccess, fmt.Sprintf("[%s] is not a provider of [%s]", callerId, serviceName), int32(0))
+	}
+	delete(m.services, serviceName)
+	m.pruneNode(callerId)
+	return rosApiResult(apiStatusSuccess, fmt.Sprintf("Unregistered [%s] as provider of [%s]", callerId, serviceName), int32(1))
+}
+
+func (m *Master) registerSubscriber(callerId string, topic string, topicType string, callerApi string) (interface{}, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.registerNode(callerId, callerApi)
+	if !contains(m.subscribers[topic], callerId) {
+		m.subscribers[topic] = append(m.subscribers[topic], callerId)
+	}
+	if _, ok := m.topicTypes[topic]; !ok && topicType != "*" {
+		m.topicTypes[topic] = topicType
+	}
+	return rosApiResult(apiStatusSuccess, fmt.Sprintf("Subscribed to [%s]", topic), m.apis(m.publishers[topic]))
+}
+
+func (m *Master) unregisterSubscriber(callerId string, topic string, callerApi string) (interface{}, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.nodes[callerId] != callerApi || !contains(m.subscribers[topic], callerId) {
+		return rosApiResult(apiStatusSuccess, fmt.Sprintf("[%s] is not a subscriber of [%s]", callerId, topic), int32(0))
+	}
+	m.setRegistrations(m.subscribers, topic, remove(m.subscribers[topic], callerId))
+	m.pruneNode(callerId)
+	return rosApiResult(apiStatusSuccess, fmt.Sprintf("Unregistered [%s] as subscriber of [%s]", callerId, topic), int32(1))
+}
+
+func (m *Master) registerPublisher(callerId string, topic string, topicType string, callerApi string) (interface{}, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.registerNode(callerId, callerApi)
+	if !contains(m.publishers[topic], callerId) {
+		m.publishers[topic] = append(m.publishers[topic], callerId)
+	}
+	if topicType != "*" {
+		m.topicTypes[topic] = topicType
+	}
+	m.notifySubscribers(topic)
+	return rosApiResult(apiStatusSuccess, fmt.Sprintf("Registered [%s] as publisher of [%s]", callerId, topic), m.apis(m.subscribers[topic]))
+}
+
+func (m *Master) unregisterPublisher(callerId string, topic string, callerApi string) (interface{}, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.nodes[callerId] != callerApi || !contains(m.publishers[topic], callerId) {
+		return rosApiResult(apiStatusSuccess, fmt.Sprintf("[%s] is not a publisher of [%s]", callerId, topic), int32(0))
+	}
+	m.setRegistrations(m.publishers, topic, remove(m.publishers[topic], callerId))
+	m.notifySubscribers(topic)
+	m.pruneNode(callerId)
+	return rosApiResult(apiStatusSuccess, fmt.Sprintf("Unregistered [%s] as publisher of [%s]", callerId, topic), int32(1))
+}
+
+func (m *Master) lookupNode(callerId string, nodeName string) (interface{}, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	api, ok := m.nodes[nodeName]
+	if !ok {
+		return rosApiResult(apiStatusError, fmt.Sprintf("unknown node [%s]", nodeName), "")
+	}
+	return rosApiResult(apiStatusSuccess, fmt.Sprintf("node api for [%s]", nodeName), api)
+}
+
+func (m *Master) lookupService(callerId string, serviceName string) (interface{}, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	s, ok := m.services[serviceName]
+	if !ok {
+		return rosApiResult(apiStatusError, fmt.Sprintf("no provider for [%s]", serviceName), "")
+	}
+	return rosApiResult(apiStatusSuccess, fmt.Sprintf("rosrpc URI: [%s]", s.uri), s.uri)
+}
+
+// Published topics and their types.  Only topics under subgraph are listed
+// unless it is empty.
+func (m *Master) getPublishedTopics(callerId string, subgraph string) (interface{}, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if subgraph != "" && !strings.HasSuffix(subgraph, "/") {
+		subgraph += "/"
+	}
+	topics := [][]string{}
+	for _, topic := range sortedKeys(m.publishers) {
+		if strings.HasPrefix(topic, subgraph) {
+			topics = append(topics, []string{topic, m.topicTypes[topic]})
+		}
+	}
+	return rosApiResult(apiStatusSuccess, "current topics", topics)
+}
+
+func (m *Master) getTopicTypes(callerId string) (interface{}, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	types := [][]string{}
+	for _, topic := range sortedKeys(m.topicTypes) {
+		types = append(types, []string{topic, m.topicTypes[topic]})
+	}
+	return rosApiResult(apiStatusSuccess, "current topic types", types)
+}
+
+// Publishers, subscribers and services as lists of [name, [caller IDs]].
+func (m *Master) getSystemState(callerId string) (interface{}, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	services := make(map[string][]string)
+	for name, s := range m.services {
+		services[name] = []string{s.callerId}
+	}
+	state := []interface{}{
+		registrationState(m.publishers),
+		registrationState(m.subscribers),
+		registrationState(services),
+	}
+	return rosApiResult(apiStatusSuccess, "current system state", state)
+}
+
+func registrationState(registrations map[string][]string) []interface{} {
+	state := []interface{}{}
+	for _, name := range sortedKeys(registrations) {
+		state = append(state, []interface{}{name, registrations[name]})
+	}
+	return state
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch m := m.(type) {
+	case map[string][]string:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]string:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (m *Master) getUri(callerId string) (interface{}, error) {
+	return rosApiResult(apiStatusSuccess, "", m.uri)
+}
+
+func (m *Master) getPid(callerId string) (interface{}, error) {
+	return rosApiResult(apiStatusSuccess, "", int32(os.Getpid()))
+}
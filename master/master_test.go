@@ -0,0 +1,282 @@
+package master
+
+import (
+	"net"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ppg/rosgo/xmlrpc"
+)
+
+func startTestMaster(t *testing.T) *Master {
+	m, err := Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// call invokes a Master API method and checks that it succeeded.
+func call(t *testing.T, m *Master, method string, args ...interface{}) interface{} {
+	t.Helper()
+	result, err := xmlrpc.Call(m.Uri(), method, args...)
+	if err != nil {
+		t.Fatalf("%s failed: %s", method, err)
+	}
+	triplet := result.([]interface{})
+	if code := triplet[0].(int32); code != apiStatusSuccess {
+		t.Fatalf("%s returned %d: %s", method, code, triplet[1])
+	}
+	return triplet[2]
+}
+
+// callStatus invokes a Master API method and returns its status code.
+func callStatus(t *testing.T, m *Master, method string, args ...interface{}) int32 {
+	t.Helper()
+	result, err := xmlrpc.Call(m.Uri(), method, args...)
+	if err != nil {
+		t.Fatalf("%s failed: %s", method, err)
+	}
+	return result.([]interface{})[0].(int32)
+}
+
+type slaveUpdate struct {
+	method string
+	key    string
+	value  interface{}
+}
+
+// startTestSlave serves a slave API which records publisherUpdate and
+// paramUpdate calls.
+func startTestSlave(t *testing.T) (string, chan slaveUpdate, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	updates := make(chan slaveUpdate, 10)
+	handler := xmlrpc.NewHandler(map[string]xmlrpc.Method{
+		"publisherUpdate": func(callerId string, topic string, publishers []interface{}) (interface{}, error) {
+			updates <- slaveUpdate{"publisherUpdate", topic, publishers}
+			return []interface{}{int32(1), "", int32(0)}, nil
+		},
+		"paramUpdate": func(callerId string, key string, value interface{}) (interface{}, error) {
+			updates <- slaveUpdate{"paramUpdate", key, value}
+			return []interface{}{int32(1), "", int32(0)}, nil
+		},
+	})
+	go http.Serve(listener, handler)
+	return "http://" + listener.Addr().String() + "/", updates, func() { listener.Close() }
+}
+
+func expectUpdate(t *testing.T, updates chan slaveUpdate, expected slaveUpdate) {
+	t.Helper()
+	select {
+	case update := <-updates:
+		if !reflect.DeepEqual(update, expected) {
+			t.Errorf("expected %v; got %v", expected, update)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("%s was not called", expected.method)
+	}
+}
+
+func TestPublisherUpdate(t *testing.T) {
+	m := startTestMaster(t)
+	defer m.Shutdown()
+	subApi, updates, stop := startTestSlave(t)
+	defer stop()
+	const pubApi = "http://127.0.0.1:1/"
+
+	publishers := call(t, m, "registerSubscriber", "/listener", "/chatter", "std_msgs/String", subApi)
+	if len(publishers.([]interface{})) != 0 {
+		t.Errorf("expected no publishers; got %v", publishers)
+	}
+	subscribers := call(t, m, "registerPublisher", "/talker", "/chatter", "std_msgs/String", pubApi)
+	if !reflect.DeepEqual(subscribers, []interface{}{subApi}) {
+		t.Errorf("expected subscribers [%s]; got %v", subApi, subscribers)
+	}
+	expectUpdate(t, updates, slaveUpdate{"publisherUpdate", "/chatter", []interface{}{pubApi}})
+
+	state := call(t, m, "getSystemState", "/test")
+	// The XML-RPC parser returns empty arrays as nil.
+	expected := []interface{}{
+		[]interface{}{[]interface{}{"/chatter", []interface{}{"/talker"}}},
+		[]interface{}{[]interface{}{"/chatter", []interface{}{"/listener"}}},
+		[]interface{}(nil),
+	}
+	if !reflect.DeepEqual(state, expected) {
+		t.Errorf("expected system state %v; got %v", expected, state)
+	}
+	topics := call(t, m, "getPublishedTopics", "/test", "")
+	if !reflect.DeepEqual(topics, []interface{}{[]interface{}{"/chatter", "std_msgs/String"}}) {
+		t.Errorf("unexpected published topics %v", topics)
+	}
+	if api := call(t, m, "lookupNode", "/test", "/talker"); api != pubApi {
+		t.Errorf("expected %s for /talker; got %v", pubApi, api)
+	}
+
+	if n := call(t, m, "unregisterPublisher", "/talker", "/chatter", pubApi); n != int32(1) {
+		t.Errorf("expected 1 publisher unregistered; got %v", n)
+	}
+	expectUpdate(t, updates, slaveUpdate{"publisherUpdate", "/chatter", []interface{}(nil)})
+	if code := callStatus(t, m, "lookupNode", "/test", "/talker"); code != apiStatusError {
+		t.Errorf("expected unregistered node to be unknown; got status %d", code)
+	}
+}
+
+func TestServices(t *testing.T) {
+	m := startTestMaster(t)
+	defer m.Shutdown()
+	const serviceApi = "rosrpc://127.0.0.1:2"
+
+	call(t, m, "registerService", "/server", "/add_two_ints", serviceApi, "http://127.0.0.1:1/")
+	if uri := call(t, m, "lookupService", "/client", "/add_two_ints"); uri != serviceApi {
+		t.Errorf("expected %s; got %v", serviceApi, uri)
+	}
+	if n := call(t, m, "unregisterService", "/server", "/add_two_ints", "rosrpc://127.0.0.1:3"); n != int32(0) {
+		t.Errorf("service with another URI was unregistered")
+	}
+	if n := call(t, m, "unregisterService", "/server", "/add_two_ints", serviceApi); n != int32(1) {
+		t.Errorf("expected 1 service unregistered; got %v", n)
+	}
+	if code := callStatus(t, m, "lookupService", "/client", "/add_two_ints"); code != apiStatusError {
+		t.Errorf("expected lookup of unregistered service to fail; got status %d", code)
+	}
+}
+
+func TestParams(t *testing.T) {
+	m := startTestMaster(t)
+	defer m.Shutdown()
+
+	call(t, m, "setParam", "/ns/node", "gain", 1.5)
+	call(t, m, "setParam", "/ns/node", "~rate", int32(10))
+	call(t, m, "setParam", "/ns/node", "/robot", map[string]interface{}{"name": "r2", "wheels": int32(4)})
+
+	if v := call(t, m, "getParam", "/other", "/ns/gain"); v != 1.5 {
+		t.Errorf("expected 1.5; got %v", v)
+	}
+	if v := call(t, m, "getParam", "/ns/node", "~rate"); v != int32(10) {
+		t.Errorf("expected 10; got %v", v)
+	}
+	ns := call(t, m, "getParam", "/other", "/ns")
+	expected := map[string]interface{}{"gain": 1.5, "node": map[string]interface{}{"rate": int32(10)}}
+	if !reflect.DeepEqual(ns, expected) {
+		t.Errorf("expected %v; got %v", expected, ns)
+	}
+	if found := call(t, m, "searchParam", "/ns/sub/node", "gain"); found != "/ns/gain" {
+		t.Errorf("expected search to find /ns/gain; got %v", found)
+	}
+	if found := call(t, m, "searchParam", "/ns/node", "robot/name"); found != "/robot/name" {
+		t.Errorf("expected search to find /robot/name; got %v", found)
+	}
+	names := call(t, m, "getParamNames", "/other")
+	expectedNames := []interface{}{"/ns/gain", "/ns/node/rate", "/robot/name", "/robot/wheels"}
+	if !reflect.DeepEqual(names, expectedNames) {
+		t.Errorf("expected names %v; got %v", expectedNames, names)
+	}
+
+	call(t, m, "deleteParam", "/other", "/robot/name")
+	if has := call(t, m, "hasParam", "/other", "/robot/name"); has != false {
+		t.Error("deleted parameter still set")
+	}
+	if has := call(t, m, "hasParam", "/other", "/robot/wheels"); has != true {
+		t.Error("sibling of deleted parameter was removed")
+	}
+	if code := callStatus(t, m, "getParam", "/other", "/robot/name"); code != apiStatusError {
+		t.Errorf("expected getParam of deleted parameter to fail; got status %d", code)
+	}
+}
+
+func TestParamSubscription(t *testing.T) {
+	m := startTestMaster(t)
+	defer m.Shutdown()
+	api, updates, stop := startTestSlave(t)
+	defer stop()
+
+	call(t, m, "setParam", "/setter", "/robot/name", "r2")
+	value := call(t, m, "subscribeParam", "/watcher", api, "/robot")
+	if !reflect.DeepEqual(value, map[string]interface{}{"name": "r2"}) {
+		t.Errorf("unexpected initial value %v", value)
+	}
+
+	call(t, m, "setParam", "/setter", "/robot/name", "c3po")
+	expectUpdate(t, updates, slaveUpdate{"paramUpdate", "/robot/", map[string]interface{}{"name": "c3po"}})
+	call(t, m, "setParam", "/setter", "/robot", "gone")
+	expectUpdate(t, updates, slaveUpdate{"paramUpdate", "/robot/", "gone"})
+	call(t, m, "deleteParam", "/setter", "/robot")
+	expectUpdate(t, updates, slaveUpdate{"paramUpdate", "/robot/", map[string]interface{}{}})
+
+	// Changes made by the subscriber itself and to unrelated keys aren't
+	// sent back.
+	call(t, m, "setParam", "/watcher", "/robot", "mine")
+	call(t, m, "setParam", "/setter", "/robots", int32(2))
+	// Deletions are sent to every subscriber, the deleter included.
+	call(t, m, "deleteParam", "/watcher", "/robot")
+	expectUpdate(t, updates, slaveUpdate{"paramUpdate", "/robot/", map[string]interface{}{}})
+	if n := call(t, m, "unsubscribeParam", "/watcher", api, "/robot"); n != int32(1) {
+		t.Errorf("expected 1 subscription removed; got %v", n)
+	}
+	call(t, m, "setParam", "/setter", "/robot", "after")
+	select {
+	case update := <-updates:
+		t.Errorf("unexpected update %v", update)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// Shutdown abandons calls to a slave which never answers, and the master
+// stops answering API calls.
+func TestShutdown(t *testing.T) {
+	m := startTestMaster(t)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+	api := "http://" + listener.Addr().String() + "/"
+	call(t, m, "registerSubscriber", "/listener", "/chatter", "std_msgs/String", api)
+	call(t, m, "registerPublisher", "/talker", "/chatter", "std_msgs/String", "http://127.0.0.1:1/")
+
+	done := make(chan struct{})
+	go func() {
+		m.Shutdown()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(slaveCallTimeout / 2):
+		t.Fatal("Shutdown waited for the slave")
+	}
+	if _, err := xmlrpc.Call(m.Uri(), "getUri", "/other"); err == nil {
+		t.Error("expected API calls to fail after Shutdown")
+	}
+}
+
+func TestResolveKey(t *testing.T) {
+	tests := []struct {
+		callerId, key, expected string
+	}{
+		{"/node", "/abs", "/abs"},
+		{"/node", "rel", "/rel"},
+		{"/ns/node", "rel/sub", "/ns/rel/sub"},
+		{"/ns/node", "~priv", "/ns/node/priv"},
+		{"/ns/node", "/", "/"},
+		{"/ns/node", "/trailing/", "/trailing"},
+	}
+	for _, test := range tests {
+		if actual := resolveKey(test.callerId, test.key); actual != test.expected {
+			t.Errorf("resolveKey(%q, %q) = %q; expected %q", test.callerId, test.key, actual, test.expected)
+		}
+	}
+}
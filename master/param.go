@@ -0,0 +1,238 @@
+package master
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Resolve a parameter key relative to the namespace of callerId.  The result
+// is absolute and has no trailing slash unless it is the root namespace.
+func resolveKey(callerId string, key string) string {
+	switch {
+	case strings.HasPrefix(key, "/"):
+	case strings.HasPrefix(key, "~"):
+		key = callerId + "/" + key[1:]
+	default:
+		key = path.Dir(callerId) + "/" + key
+	}
+	return path.Clean("/" + key)
+}
+
+// Split an absolute key into the names of its namespaces.
+func splitKey(key string) []string {
+	var names []string
+	for _, name := range strings.Split(key, "/") {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Look up the value of key in the parameter tree.  Namespaces are
+// map[string]interface{} values.
+func (m *Master) lookupParam(key string) (interface{}, bool) {
+	var value interface{} = m.params
+	for _, name := range splitKey(key) {
+		ns, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = ns[name]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// Store value at key, creating namespaces as needed.  A map value replaces
+// the whole namespace.
+func (m *Master) storeParam(key string, value interface{}) {
+	names := splitKey(key)
+	if len(names) == 0 {
+		if ns, ok := value.(map[string]interface{}); ok {
+			m.params = copyParam(ns).(map[string]interface{})
+		}
+		return
+	}
+	ns := m.params
+	for _, name := range names[:len(names)-1] {
+		child, ok := ns[name].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			ns[name] = child
+		}
+		ns = child
+	}
+	ns[names[len(names)-1]] = copyParam(value)
+}
+
+// Copy namespaces so values handed out can't alias the tree.
+func copyParam(value interface{}) interface{} {
+	ns, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+	result := make(map[string]interface{}, len(ns))
+	for k, v := range ns {
+		result[k] = copyParam(v)
+	}
+	return result
+}
+
+// Tell nodes subscribed to key, or to a namespace above or below it, about
+// their new values.  The node changedBy isn't told; pass "" to tell every
+// node.  The caller must hold m.mutex.
+func (m *Master) notifyParamSubscribers(key string, changedBy string) {
+	if key != "/" {
+		key += "/"
+	}
+	for subscribed, callerIds := range m.paramSubscribers {
+		if !strings.HasPrefix(subscribed, key) && !strings.HasPrefix(key, subscribed) {
+			continue
+		}
+		value, ok := m.lookupParam(subscribed)
+		if !ok {
+			value = map[string]interface{}{}
+		}
+		for _, callerId := range callerIds {
+			if callerId != changedBy {
+				m.callSlave(m.nodes[callerId], "paramUpdate", masterCallerId, subscribed, copyParam(value))
+			}
+		}
+	}
+}
+
+func (m *Master) deleteParam(callerId string, key string) (interface{}, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	key = resolveKey(callerId, key)
+	names := splitKey(key)
+	if len(names) == 0 {
+		return rosApiResult(apiStatusError, "cannot delete root of parameter tree", int32(0))
+	}
+	parent, ok := m.lookupParam(path.Dir(key))
+	ns, isNs := parent.(map[string]interface{})
+	if !ok || !isNs {
+		return rosApiResult(apiStatusError, fmt.Sprintf("parameter [%s] is not set", key), int32(0))
+	}
+	if _, ok := ns[names[len(names)-1]]; !ok {
+		return rosApiResult(apiStatusError, fmt.Sprintf("parameter [%s] is not set", key), int32(0))
+	}
+	delete(ns, names[len(names)-1])
+	// Like rosmaster, tell the caller too, unlike in setParam.
+	m.notifyParamSubscribers(key, "")
+	return rosApiResult(apiStatusSuccess, fmt.Sprintf("parameter %s deleted", key), int32(0))
+}
+
+func (m *Master) setParam(callerId string, key string, value interface{}) (interface{}, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	key = resolveKey(callerId, key)
+	if _, ok := value.(map[string]interface{}); !ok && key == "/" {
+		return rosApiResult(apiStatusError, "cannot set root of parameter tree to a non-namespace value", int32(0))
+	}
+	m.storeParam(key, value)
+	m.notifyParamSubscribers(key, callerId)
+	return rosApiResult(apiStatusSuccess, fmt.Sprintf("parameter %s set", key), int32(0))
+}
+
+func (m *Master) getParam(callerId string, key string) (interface{}, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	key = resolveKey(callerId, key)
+	value, ok := m.lookupParam(key)
+	if !ok {
+		return rosApiResult(apiStatusError, fmt.Sprintf("Parameter [%s] is not set", key), int32(0))
+	}
+	return rosApiResult(apiStatusSuccess, fmt.Sprintf("Parameter [%s]", key), copyParam(value))
+}
+
+// Find key in the caller's namespace or the closest namespace above it.
+func (m *Master) searchParam(callerId string, key string) (interface{}, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if strings.HasPrefix(key, "/") {
+		if _, ok := m.lookupParam(key); ok {
+			return rosApiResult(apiStatusSuccess, fmt.Sprintf("Found [%s]", key), key)
+		}
+	} else if names := splitKey(key); len(names) > 0 && !strings.HasPrefix(key, "~") {
+		for ns := path.Dir(callerId); ; ns = path.Dir(ns) {
+			if _, ok := m.lookupParam(path.Join(ns, names[0])); ok {
+				found := path.Join(ns, key)
+				return rosApiResult(apiStatusSuccess, fmt.Sprintf("Found [%s]", found), found)
+			}
+			if ns == "/" {
+				break
+			}
+		}
+	}
+	return rosApiResult(apiStatusError, fmt.Sprintf("Cannot find parameter [%s] in an upwards search", key), "")
+}
+
+// Register the caller for paramUpdate calls when key changes, and return its
+// current value.  An unset key has an empty namespace as its value.
+func (m *Master) subscribeParam(callerId string, callerApi string, key string) (interface{}, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	key = resolveKey(callerId, key)
+	m.registerNode(callerId, callerApi)
+	subscribed := key
+	if subscribed != "/" {
+		subscribed += "/"
+	}
+	if !contains(m.paramSubscribers[subscribed], callerId) {
+		m.paramSubscribers[subscribed] = append(m.paramSubscribers[subscribed], callerId)
+	}
+	value, ok := m.lookupParam(key)
+	if !ok {
+		value = map[string]interface{}{}
+	}
+	return rosApiResult(apiStatusSuccess, fmt.Sprintf("Subscribed to parameter [%s]", key), copyParam(value))
+}
+
+func (m *Master) unsubscribeParam(callerId string, callerApi string, key string) (interface{}, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	key = resolveKey(callerId, key)
+	subscribed := key
+	if subscribed != "/" {
+		subscribed += "/"
+	}
+	if m.nodes[callerId] != callerApi || !contains(m.paramSubscribers[subscribed], callerId) {
+		return rosApiResult(apiStatusSuccess, fmt.Sprintf("[%s] is not subscribed to parameter [%s]", callerId, key), int32(0))
+	}
+	m.setRegistrations(m.paramSubscribers, subscribed, remove(m.paramSubscribers[subscribed], callerId))
+	m.pruneNode(callerId)
+	return rosApiResult(apiStatusSuccess, fmt.Sprintf("Unsubscribed from parameter [%s]", key), int32(1))
+}
+
+func (m *Master) hasParam(callerId string, key string) (interface{}, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	key = resolveKey(callerId, key)
+	_, ok := m.lookupParam(key)
+	return rosApiResult(apiStatusSuccess, key, ok)
+}
+
+// Every parameter which isn't a namespace.
+func (m *Master) getParamNames(callerId string) (interface{}, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	names := []string{}
+	var walk func(prefix string, ns map[string]interface{})
+	walk = func(prefix string, ns map[string]interface{}) {
+		for name, value := range ns {
+			if child, ok := value.(map[string]interface{}); ok {
+				walk(prefix+name+"/", child)
+			} else {
+				names = append(names, prefix+name)
+			}
+		}
+	}
+	walk("/", m.params)
+	sort.Strings(names)
+	return rosApiResult(apiStatusSuccess, "Parameter names", names)
+}
@@ -47,7 +47,7 @@ string id
 
 `,
 		"actionlib_msgs/GoalID",
-		"80cf13439fb52033034dd028f646e989",
+		"302881f31927c1df708a2dbab0e80ee8",
 	}
 )
 
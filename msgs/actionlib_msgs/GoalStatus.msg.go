@@ -58,9 +58,22 @@ uint8 LOST            = 9   # An action client can determine that a goal is LOST
 #Allow for the user to associate a string with GoalStatus for debugging
 string text
 
+
+================================================================================
+MSG: actionlib_msgs/GoalID
+# The stamp should store the time at which this goal was requested.
+# It is used by an action server when it tries to preempt all
+# goals that were requested before a certain time
+time stamp
+
+# The id provides a way to associate feedback and
+# result message with specific goal requests. The id
+# specified must be unique.
+string id
+
 `,
 		"actionlib_msgs/GoalStatus",
-		"b6758985eced8e08e99ce12a55072791",
+		"d388f9b87b3c471f784434d671988d4a",
 	}
 )
 
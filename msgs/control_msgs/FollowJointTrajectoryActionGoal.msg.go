@@ -42,9 +42,106 @@ var (
 Header header
 actionlib_msgs/GoalID goal_id
 FollowJointTrajectoryGoal goal
+
+================================================================================
+MSG: std_msgs/Header
+# Standard metadata for higher-level stamped data types.
+# This is generally used to communicate timestamped data 
+# in a particular coordinate frame.
+# 
+# sequence ID: consecutively increasing ID 
+uint32 seq
+#Two-integer timestamp that is expressed as:
+# * stamp.sec: seconds (stamp_secs) since epoch (in Python the variable is called 'secs')
+# * stamp.nsec: nanoseconds since stamp_secs (in Python the variable is called 'nsecs')
+# time-handling sugar is provided by the client library
+time stamp
+#Frame this data is associated with
+# 0: no frame
+# 1: global frame
+string frame_id
+
+================================================================================
+MSG: actionlib_msgs/GoalID
+# The stamp should store the time at which this goal was requested.
+# It is used by an action server when it tries to preempt all
+# goals that were requested before a certain time
+time stamp
+
+# The id provides a way to associate feedback and
+# result message with specific goal requests. The id
+# specified must be unique.
+string id
+
+
+================================================================================
+MSG: control_msgs/FollowJointTrajectoryGoal
+# ====== DO NOT MODIFY! AUTOGENERATED FROM AN ACTION DEFINITION ======
+# The joint trajectory to follow
+trajectory_msgs/JointTrajectory trajectory
+
+# Tolerances for the trajectory.  If the measured joint values fall
+# outside the tolerances the trajectory goal is aborted.  Any
+# tolerances that are not specified (by being omitted or set to 0) are
+# set to the defaults for the action server (often taken from the
+# parameter server).
+
+# Tolerances applied to the joints as the trajectory is executed.  If
+# violated, the goal aborts with error_code set to
+# PATH_TOLERANCE_VIOLATED.
+JointTolerance[] path_tolerance
+
+# To report success, the joints must be within goal_tolerance of the
+# final trajectory value.  The goal must be achieved by time the
+# trajectory ends plus goal_time_tolerance.  (goal_time_tolerance
+# allows some leeway in time, so that the trajectory goal can still
+# succeed even if the joints reach the goal some time after the
+# precise end time of the trajectory).
+#
+# If the joints are not within goal_tolerance after "trajectory finish
+# time" + goal_time_tolerance, the goal aborts with error_code set to
+# GOAL_TOLERANCE_VIOLATED
+JointTolerance[] goal_tolerance
+duration goal_time_tolerance
+
+
+================================================================================
+MSG: trajectory_msgs/JointTrajectory
+Header header
+string[] joint_names
+JointTrajectoryPoint[] points
+================================================================================
+MSG: trajectory_msgs/JointTrajectoryPoint
+# Each trajectory point specifies either positions[, velocities[, accelerations]]
+# or positions[, effort] for the trajectory to be executed.
+# All specified values are in the same order as the joint names in JointTrajectory.msg
+
+float64[] positions
+float64[] velocities
+float64[] accelerations
+float64[] effort
+duration time_from_start
+
+================================================================================
+MSG: control_msgs/JointTolerance
+# The tolerances specify the amount the position, velocity, and
+# accelerations can vary from the setpoints.  For example, in the case
+# of trajectory control, when the actual position varies beyond
+# (desired position + position tolerance), the trajectory goal may
+# abort.
+# 
+# There are two special values for tolerances:
+#  * 0 - The tolerance is unspecified and will remain at whatever the default is
+#  * -1 - The tolerance is "erased".  If there was a default, the joint will be
+#         allowed to move without restriction.
+
+string name
+float64 position  # in radians or meters (for a revolute or prismatic joint, respectively)
+float64 velocity  # in rad/sec or m/sec
+float64 acceleration  # in rad/sec^2 or m/sec^2
 `,
 		"control_msgs/FollowJointTrajectoryActionGoal",
-		"ea97e347ff08322557a8ca22058939c7",
+		"cff5c1d533bf2f82dd0138d57f4304bb",
 	}
 )
 
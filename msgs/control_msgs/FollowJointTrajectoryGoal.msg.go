@@ -66,9 +66,62 @@ JointTolerance[] path_tolerance
 JointTolerance[] goal_tolerance
 duration goal_time_tolerance
 
+
+================================================================================
+MSG: trajectory_msgs/JointTrajectory
+Header header
+string[] joint_names
+JointTrajectoryPoint[] points
+================================================================================
+MSG: std_msgs/Header
+# Standard metadata for higher-level stamped data types.
+# This is generally used to communicate timestamped data 
+# in a particular coordinate frame.
+# 
+# sequence ID: consecutively increasing ID 
+uint32 seq
+#Two-integer timestamp that is expressed as:
+# * stamp.sec: seconds (stamp_secs) since epoch (in Python the variable is called 'secs')
+# * stamp.nsec: nanoseconds since stamp_secs (in Python the variable is called 'nsecs')
+# time-handling sugar is provided by the client library
+time stamp
+#Frame this data is associated with
+# 0: no frame
+# 1: global frame
+string frame_id
+
+================================================================================
+MSG: trajectory_msgs/JointTrajectoryPoint
+# Each trajectory point specifies either positions[, velocities[, accelerations]]
+# or positions[, effort] for the trajectory to be executed.
+# All specified values are in the same order as the joint names in JointTrajectory.msg
+
+float64[] positions
+float64[] velocities
+float64[] accelerations
+float64[] effort
+duration time_from_start
+
+================================================================================
+MSG: control_msgs/JointTolerance
+# The tolerances specify the amount the position, velocity, and
+# accelerations can vary from the setpoints.  For example, in the case
+# of trajectory control, when the actual position varies beyond
+# (desired position + position tolerance), the trajectory goal may
+# abort.
+# 
+# There are two special values for tolerances:
+#  * 0 - The tolerance is unspecified and will remain at whatever the default is
+#  * -1 - The tolerance is "erased".  If there was a default, the joint will be
+#         allowed to move without restriction.
+
+string name
+float64 position  # in radians or meters (for a revolute or prismatic joint, respectively)
+float64 velocity  # in rad/sec or m/sec
+float64 acceleration  # in rad/sec^2 or m/sec^2
 `,
 		"control_msgs/FollowJointTrajectoryGoal",
-		"d6e925ca987733b7452947e90e68dd68",
+		"69636787b6ecbde4d61d711979bc7ecb",
 	}
 )
 
@@ -56,18 +56,13 @@ string error_string
 
 `,
 		"control_msgs/FollowJointTrajectoryResult",
-		"8814c14eea6988befd0cc8e254fdcc7f",
+		"493383b18409bfb604b4e26c676401d2",
 	}
 )
 
 type FollowJointTrajectoryResult struct {
-	ErrorCode             int32
-	INVALIDGOAL           int32
-	INVALIDJOINTS         int32
-	OLDHEADERTIMESTAMP    int32
-	PATHTOLERANCEVIOLATED int32
-	GOALTOLERANCEVIOLATED int32
-	ErrorString           string
+	ErrorCode   int32
+	ErrorString string
 }
 
 func (m *FollowJointTrajectoryResult) Serialize(w io.Writer) (err error) {
@@ -75,26 +70,6 @@ func (m *FollowJointTrajectoryResult) Serialize(w io.Writer) (err error) {
 		return err
 	}
 
-	if err = ros.SerializeMessageField(w, "int32", &m.INVALIDGOAL); err != nil {
-		return err
-	}
-
-	if err = ros.SerializeMessageField(w, "int32", &m.INVALIDJOINTS); err != nil {
-		return err
-	}
-
-	if err = ros.SerializeMessageField(w, "int32", &m.OLDHEADERTIMESTAMP); err != nil {
-		return err
-	}
-
-	if err = ros.SerializeMessageField(w, "int32", &m.PATHTOLERANCEVIOLATED); err != nil {
-		return err
-	}
-
-	if err = ros.SerializeMessageField(w, "int32", &m.GOALTOLERANCEVIOLATED); err != nil {
-		return err
-	}
-
 	if err = ros.SerializeMessageField(w, "string", &m.ErrorString); err != nil {
 		return err
 	}
@@ -108,31 +83,6 @@ func (m *FollowJointTrajectoryResult) Deserialize(r io.Reader) (err error) {
 		return err
 	}
 
-	// INVALIDGOAL
-	if err = ros.DeserializeMessageField(r, "int32", &m.INVALIDGOAL); err != nil {
-		return err
-	}
-
-	// INVALIDJOINTS
-	if err = ros.DeserializeMessageField(r, "int32", &m.INVALIDJOINTS); err != nil {
-		return err
-	}
-
-	// OLDHEADERTIMESTAMP
-	if err = ros.DeserializeMessageField(r, "int32", &m.OLDHEADERTIMESTAMP); err != nil {
-		return err
-	}
-
-	// PATHTOLERANCEVIOLATED
-	if err = ros.DeserializeMessageField(r, "int32", &m.PATHTOLERANCEVIOLATED); err != nil {
-		return err
-	}
-
-	// GOALTOLERANCEVIOLATED
-	if err = ros.DeserializeMessageField(r, "int32", &m.GOALTOLERANCEVIOLATED); err != nil {
-		return err
-	}
-
 	// ErrorString
 	if err = ros.DeserializeMessageField(r, "string", &m.ErrorString); err != nil {
 		return err
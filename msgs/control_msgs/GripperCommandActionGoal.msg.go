@@ -42,9 +42,50 @@ var (
 Header header
 actionlib_msgs/GoalID goal_id
 GripperCommandGoal goal
+
+================================================================================
+MSG: std_msgs/Header
+# Standard metadata for higher-level stamped data types.
+# This is generally used to communicate timestamped data 
+# in a particular coordinate frame.
+# 
+# sequence ID: consecutively increasing ID 
+uint32 seq
+#Two-integer timestamp that is expressed as:
+# * stamp.sec: seconds (stamp_secs) since epoch (in Python the variable is called 'secs')
+# * stamp.nsec: nanoseconds since stamp_secs (in Python the variable is called 'nsecs')
+# time-handling sugar is provided by the client library
+time stamp
+#Frame this data is associated with
+# 0: no frame
+# 1: global frame
+string frame_id
+
+================================================================================
+MSG: actionlib_msgs/GoalID
+# The stamp should store the time at which this goal was requested.
+# It is used by an action server when it tries to preempt all
+# goals that were requested before a certain time
+time stamp
+
+# The id provides a way to associate feedback and
+# result message with specific goal requests. The id
+# specified must be unique.
+string id
+
+
+================================================================================
+MSG: control_msgs/GripperCommandGoal
+# ====== DO NOT MODIFY! AUTOGENERATED FROM AN ACTION DEFINITION ======
+GripperCommand command
+
+================================================================================
+MSG: control_msgs/GripperCommand
+float64 position
+float64 max_effort
 `,
 		"control_msgs/GripperCommandActionGoal",
-		"afbb0281892695d5519cbad8f2cc3fce",
+		"aa581f648a35ed681db2ec0bf7a82bea",
 	}
 )
 
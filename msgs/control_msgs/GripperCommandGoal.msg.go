@@ -37,9 +37,14 @@ var (
 	MsgGripperCommandGoal = &_MsgGripperCommandGoal{
 		`# ====== DO NOT MODIFY! AUTOGENERATED FROM AN ACTION DEFINITION ======
 GripperCommand command
+
+================================================================================
+MSG: control_msgs/GripperCommand
+float64 position
+float64 max_effort
 `,
 		"control_msgs/GripperCommandGoal",
-		"78de881eecae5a7cf4fbd37d6d76d1dd",
+		"86fd82f4ddc48a4cb6856cfa69217e43",
 	}
 )
 
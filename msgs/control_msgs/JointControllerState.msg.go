@@ -48,9 +48,27 @@ float64 i
 float64 d
 float64 i_clamp
 
+
+================================================================================
+MSG: std_msgs/Header
+# Standard metadata for higher-level stamped data types.
+# This is generally used to communicate timestamped data 
+# in a particular coordinate frame.
+# 
+# sequence ID: consecutively increasing ID 
+uint32 seq
+#Two-integer timestamp that is expressed as:
+# * stamp.sec: seconds (stamp_secs) since epoch (in Python the variable is called 'secs')
+# * stamp.nsec: nanoseconds since stamp_secs (in Python the variable is called 'nsecs')
+# time-handling sugar is provided by the client library
+time stamp
+#Frame this data is associated with
+# 0: no frame
+# 1: global frame
+string frame_id
 `,
 		"control_msgs/JointControllerState",
-		"8bdc19d6058e1654165f129c40b1bc86",
+		"c0d034a7bf20aeb1c37f3eccb7992b69",
 	}
 )
 
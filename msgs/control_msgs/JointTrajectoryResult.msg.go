@@ -38,7 +38,7 @@ var (
 		`# ====== DO NOT MODIFY! AUTOGENERATED FROM AN ACTION DEFINITION ======
 `,
 		"control_msgs/JointTrajectoryResult",
-		"7ac3b32c97133caf1b14edc99a50c37d",
+		"d41d8cd98f00b204e9800998ecf8427e",
 	}
 )
 
@@ -42,9 +42,27 @@ float64 position
 float64 velocity
 float64 error
 
+
+================================================================================
+MSG: std_msgs/Header
+# Standard metadata for higher-level stamped data types.
+# This is generally used to communicate timestamped data 
+# in a particular coordinate frame.
+# 
+# sequence ID: consecutively increasing ID 
+uint32 seq
+#Two-integer timestamp that is expressed as:
+# * stamp.sec: seconds (stamp_secs) since epoch (in Python the variable is called 'secs')
+# * stamp.nsec: nanoseconds since stamp_secs (in Python the variable is called 'nsecs')
+# time-handling sugar is provided by the client library
+time stamp
+#Frame this data is associated with
+# 0: no frame
+# 1: global frame
+string frame_id
 `,
 		"control_msgs/SingleJointPositionFeedback",
-		"19f3192c841f11fa1c6f55b158c637bc",
+		"8cee65610a3d08e0a1bded82f146f1fd",
 	}
 )
 
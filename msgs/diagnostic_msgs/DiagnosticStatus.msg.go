@@ -52,9 +52,14 @@ string message # a description of the status
 string hardware_id # a hardware unique string
 KeyValue[] values # an array of values associated with the status
 
+
+================================================================================
+MSG: diagnostic_msgs/KeyValue
+string key # what to label this value when viewing
+string value # a value to track over time
 `,
 		"diagnostic_msgs/DiagnosticStatus",
-		"bfaff5a20cf26a7d7255cbaa01dae095",
+		"d0ce08bc6e5ba34c7754f563a9cabaf1",
 	}
 )
 
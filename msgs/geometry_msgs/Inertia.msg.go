@@ -51,9 +51,21 @@ float64 ixz
 float64 iyy
 float64 iyz
 float64 izz
-`,
+
+================================================================================
+MSG: geometry_msgs/Vector3
+# This represents a vector in free space. 
+# It is only meant to represent a direction. Therefore, it does not
+# make sense to apply a translation to it (e.g., when applying a 
+# generic rigid transformation to a Vector3, tf2 will only apply the
+# rotation). If you want your data to be translatable too, use the
+# geometry_msgs/Point message instead.
+
+float64 x
+float64 y
+float64 z`,
 		"geometry_msgs/Inertia",
-		"f9eac69d7f8aaf44853616a958d7477f",
+		"1d26e4bb6c83ff141c5cf0d883c2b0fe",
 	}
 )
 
@@ -39,9 +39,34 @@ var (
 		`# This represents a Point with reference coordinate frame and timestamp
 Header header
 Point point
+
+================================================================================
+MSG: std_msgs/Header
+# Standard metadata for higher-level stamped data types.
+# This is generally used to communicate timestamped data 
+# in a particular coordinate frame.
+# 
+# sequence ID: consecutively increasing ID 
+uint32 seq
+#Two-integer timestamp that is expressed as:
+# * stamp.sec: seconds (stamp_secs) since epoch (in Python the variable is called 'secs')
+# * stamp.nsec: nanoseconds since stamp_secs (in Python the variable is called 'nsecs')
+# time-handling sugar is provided by the client library
+time stamp
+#Frame this data is associated with
+# 0: no frame
+# 1: global frame
+string frame_id
+
+================================================================================
+MSG: geometry_msgs/Point
+# This contains the position of a point in free space
+float64 x
+float64 y
+float64 z
 `,
 		"geometry_msgs/PointStamped",
-		"e948b3cf3f45aaeaedb063e8b966cf1f",
+		"c63aecb41bfdfd6b7e1fac37c7cbe7bf",
 	}
 )
 
@@ -39,9 +39,22 @@ var (
 	MsgPolygon = &_MsgPolygon{
 		`#A specification of a polygon where the first and last points are assumed to be connected
 Point32[] points
-`,
+
+================================================================================
+MSG: geometry_msgs/Point32
+# This contains the position of a point in free space(with 32 bits of precision).
+# It is recommeded to use Point wherever possible instead of Point32.  
+# 
+# This recommendation is to promote interoperability.  
+#
+# This message is designed to take up less space when sending
+# lots of points at once, as in the case of a PointCloud.  
+
+float32 x
+float32 y
+float32 z`,
 		"geometry_msgs/Polygon",
-		"9d3aa06749458d74b06b6e2ab6c8104d",
+		"cd60a26494a087f577976f0329fa120e",
 	}
 )
 
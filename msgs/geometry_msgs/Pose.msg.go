@@ -38,9 +38,25 @@ var (
 		`# A representation of pose in free space, composed of postion and orientation. 
 Point position
 Quaternion orientation
+
+================================================================================
+MSG: geometry_msgs/Point
+# This contains the position of a point in free space
+float64 x
+float64 y
+float64 z
+
+================================================================================
+MSG: geometry_msgs/Quaternion
+# This represents an orientation in free space in quaternion form.
+
+float64 x
+float64 y
+float64 z
+float64 w
 `,
 		"geometry_msgs/Pose",
-		"dc72bb5c46de72bac142dec5d13c6f57",
+		"e45d45a5a1ce597b249e23fb30fc871f",
 	}
 )
 
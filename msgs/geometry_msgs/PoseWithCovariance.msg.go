@@ -46,9 +46,31 @@ Pose pose
 # In order, the parameters are:
 # (x, y, z, rotation about X axis, rotation about Y axis, rotation about Z axis)
 float64[36] covariance
+
+================================================================================
+MSG: geometry_msgs/Pose
+# A representation of pose in free space, composed of postion and orientation. 
+Point position
+Quaternion orientation
+
+================================================================================
+MSG: geometry_msgs/Point
+# This contains the position of a point in free space
+float64 x
+float64 y
+float64 z
+
+================================================================================
+MSG: geometry_msgs/Quaternion
+# This represents an orientation in free space in quaternion form.
+
+float64 x
+float64 y
+float64 z
+float64 w
 `,
 		"geometry_msgs/PoseWithCovariance",
-		"4ec31161b30291389f54fb885685270a",
+		"c23e848cf1b7533a8d7c259073a97e6f",
 	}
 )
 
@@ -38,9 +38,21 @@ var (
 		`# This expresses velocity in free space broken into its linear and angular parts.
 Vector3  linear
 Vector3  angular
-`,
+
+================================================================================
+MSG: geometry_msgs/Vector3
+# This represents a vector in free space. 
+# It is only meant to represent a direction. Therefore, it does not
+# make sense to apply a translation to it (e.g., when applying a 
+# generic rigid transformation to a Vector3, tf2 will only apply the
+# rotation). If you want your data to be translatable too, use the
+# geometry_msgs/Point message instead.
+
+float64 x
+float64 y
+float64 z`,
 		"geometry_msgs/Twist",
-		"7b067cfe31b410bffd4e416af2c10eb0",
+		"9f195f881246fdfa2798d1d3eebca84a",
 	}
 )
 
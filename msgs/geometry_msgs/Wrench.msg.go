@@ -39,9 +39,21 @@ var (
 # its linear and angular parts.
 Vector3  force
 Vector3  torque
-`,
+
+================================================================================
+MSG: geometry_msgs/Vector3
+# This represents a vector in free space. 
+# It is only meant to represent a direction. Therefore, it does not
+# make sense to apply a translation to it (e.g., when applying a 
+# generic rigid transformation to a Vector3, tf2 will only apply the
+# rotation). If you want your data to be translatable too, use the
+# geometry_msgs/Point message instead.
+
+float64 x
+float64 y
+float64 z`,
 		"geometry_msgs/Wrench",
-		"2aae87faaa553ae28e07e684016d765c",
+		"4f539cf138b23283b520fd271b567936",
 	}
 )
 
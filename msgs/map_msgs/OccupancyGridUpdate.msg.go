@@ -44,9 +44,27 @@ int32 y
 uint32 width
 uint32 height
 int8[] data
+
+================================================================================
+MSG: std_msgs/Header
+# Standard metadata for higher-level stamped data types.
+# This is generally used to communicate timestamped data 
+# in a particular coordinate frame.
+# 
+# sequence ID: consecutively increasing ID 
+uint32 seq
+#Two-integer timestamp that is expressed as:
+# * stamp.sec: seconds (stamp_secs) since epoch (in Python the variable is called 'secs')
+# * stamp.nsec: nanoseconds since stamp_secs (in Python the variable is called 'nsecs')
+# time-handling sugar is provided by the client library
+time stamp
+#Frame this data is associated with
+# 0: no frame
+# 1: global frame
+string frame_id
 `,
 		"map_msgs/OccupancyGridUpdate",
-		"0e77ea0b2c75ef93e687bbf87cbdb9b5",
+		"b295be292b335c34718bd939deebe1c9",
 	}
 )
 
@@ -48,9 +48,31 @@ uint32 width
 uint32 height
 # The origin of the map [m, m, rad].  This is the real-world pose of the
 # cell (0,0) in the map.
-geometry_msgs/Pose origin`,
+geometry_msgs/Pose origin
+================================================================================
+MSG: geometry_msgs/Pose
+# A representation of pose in free space, composed of postion and orientation. 
+Point position
+Quaternion orientation
+
+================================================================================
+MSG: geometry_msgs/Point
+# This contains the position of a point in free space
+float64 x
+float64 y
+float64 z
+
+================================================================================
+MSG: geometry_msgs/Quaternion
+# This represents an orientation in free space in quaternion form.
+
+float64 x
+float64 y
+float64 z
+float64 w
+`,
 		"nav_msgs/MapMetaData",
-		"84f12218a664df5896e1e5f18e75d1b0",
+		"10cfc8a2818024d3248802c00c95f11b",
 	}
 )
 
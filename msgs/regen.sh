@@ -26,6 +26,6 @@ for dir in $msgs_dirs; do
   for file in $(find $share_dir/$dir/msg/ -d 1 -name '*.msg'); do
 		target=$dir/${file##*/}
     cp $file $target
-    ros-gen-go msg --package=$dir --in=$file --out=$target.go
+    ros-gen-go msg --include=$share_dir --package=$dir --in=$file --out=$target.go
 	done
 done
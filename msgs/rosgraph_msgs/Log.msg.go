@@ -57,9 +57,27 @@ string file # file the message came from
 string function # function the message came from
 uint32 line # line the message came from
 string[] topics # topic names that the node publishes
+
+================================================================================
+MSG: std_msgs/Header
+# Standard metadata for higher-level stamped data types.
+# This is generally used to communicate timestamped data 
+# in a particular coordinate frame.
+# 
+# sequence ID: consecutively increasing ID 
+uint32 seq
+#Two-integer timestamp that is expressed as:
+# * stamp.sec: seconds (stamp_secs) since epoch (in Python the variable is called 'secs')
+# * stamp.nsec: nanoseconds since stamp_secs (in Python the variable is called 'nsecs')
+# time-handling sugar is provided by the client library
+time stamp
+#Frame this data is associated with
+# 0: no frame
+# 1: global frame
+string frame_id
 `,
 		"rosgraph_msgs/Log",
-		"7e4c0d13ed75610b7a2bb18594155bf8",
+		"acffd30cd6b6de30f120938c17c593fb",
 	}
 )
 
@@ -87,9 +87,27 @@ float32[] cell_voltage   # An array of individual cell voltages for each cell in
                          # If individual voltages unknown but number of cells known set each to NaN
 string location          # The location into which the battery is inserted. (slot number or plug)
 string serial_number     # The best approximation of the battery serial number
+
+================================================================================
+MSG: std_msgs/Header
+# Standard metadata for higher-level stamped data types.
+# This is generally used to communicate timestamped data 
+# in a particular coordinate frame.
+# 
+# sequence ID: consecutively increasing ID 
+uint32 seq
+#Two-integer timestamp that is expressed as:
+# * stamp.sec: seconds (stamp_secs) since epoch (in Python the variable is called 'secs')
+# * stamp.nsec: nanoseconds since stamp_secs (in Python the variable is called 'nsecs')
+# time-handling sugar is provided by the client library
+time stamp
+#Frame this data is associated with
+# 0: no frame
+# 1: global frame
+string frame_id
 `,
 		"sensor_msgs/BatteryState",
-		"56ecc2d1da33cbae60aa83fa76d21ae1",
+		"476f837fa6771f6e16e3bf4ef96f8770",
 	}
 )
 
@@ -51,9 +51,27 @@ string format        # Specifies the format of the data
                      #   Acceptable values:
                      #     jpeg, png
 uint8[] data         # Compressed image buffer
+
+================================================================================
+MSG: std_msgs/Header
+# Standard metadata for higher-level stamped data types.
+# This is generally used to communicate timestamped data 
+# in a particular coordinate frame.
+# 
+# sequence ID: consecutively increasing ID 
+uint32 seq
+#Two-integer timestamp that is expressed as:
+# * stamp.sec: seconds (stamp_secs) since epoch (in Python the variable is called 'secs')
+# * stamp.nsec: nanoseconds since stamp_secs (in Python the variable is called 'nsecs')
+# time-handling sugar is provided by the client library
+time stamp
+#Frame this data is associated with
+# 0: no frame
+# 1: global frame
+string frame_id
 `,
 		"sensor_msgs/CompressedImage",
-		"d3795018f0b7f0b8b50b7ea3b4a7519b",
+		"8f7a12909da2c9d3332d540a0977563f",
 	}
 )
 
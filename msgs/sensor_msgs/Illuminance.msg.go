@@ -56,9 +56,27 @@ var (
 
  float64 illuminance     # Measurement of the Photometric Illuminance in Lux.
 
- float64 variance        # 0 is interpreted as variance unknown`,
+ float64 variance        # 0 is interpreted as variance unknown
+================================================================================
+MSG: std_msgs/Header
+# Standard metadata for higher-level stamped data types.
+# This is generally used to communicate timestamped data 
+# in a particular coordinate frame.
+# 
+# sequence ID: consecutively increasing ID 
+uint32 seq
+#Two-integer timestamp that is expressed as:
+# * stamp.sec: seconds (stamp_secs) since epoch (in Python the variable is called 'secs')
+# * stamp.nsec: nanoseconds since stamp_secs (in Python the variable is called 'nsecs')
+# time-handling sugar is provided by the client library
+time stamp
+#Frame this data is associated with
+# 0: no frame
+# 1: global frame
+string frame_id
+`,
 		"sensor_msgs/Illuminance",
-		"10cf55f229d37dfb4f7e5cce8fb091ec",
+		"8cf5febb0952fca9d650c3d11a81a188",
 	}
 )
 
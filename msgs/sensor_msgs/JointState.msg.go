@@ -64,9 +64,27 @@ string[] name
 float64[] position
 float64[] velocity
 float64[] effort
+
+================================================================================
+MSG: std_msgs/Header
+# Standard metadata for higher-level stamped data types.
+# This is generally used to communicate timestamped data 
+# in a particular coordinate frame.
+# 
+# sequence ID: consecutively increasing ID 
+uint32 seq
+#Two-integer timestamp that is expressed as:
+# * stamp.sec: seconds (stamp_secs) since epoch (in Python the variable is called 'secs')
+# * stamp.nsec: nanoseconds since stamp_secs (in Python the variable is called 'nsecs')
+# time-handling sugar is provided by the client library
+time stamp
+#Frame this data is associated with
+# 0: no frame
+# 1: global frame
+string frame_id
 `,
 		"sensor_msgs/JointState",
-		"c7c9a251ee705ea0c14a564fedcb85b1",
+		"3066dcd76a6cfaef579bd0f34173e9fd",
 	}
 )
 
@@ -42,9 +42,27 @@ var (
 Header header           # timestamp in the header is the time the data is received from the joystick
 float32[] axes          # the axes measurements from a joystick
 int32[] buttons         # the buttons measurements from a joystick 
+
+================================================================================
+MSG: std_msgs/Header
+# Standard metadata for higher-level stamped data types.
+# This is generally used to communicate timestamped data 
+# in a particular coordinate frame.
+# 
+# sequence ID: consecutively increasing ID 
+uint32 seq
+#Two-integer timestamp that is expressed as:
+# * stamp.sec: seconds (stamp_secs) since epoch (in Python the variable is called 'secs')
+# * stamp.nsec: nanoseconds since stamp_secs (in Python the variable is called 'nsecs')
+# time-handling sugar is provided by the client library
+time stamp
+#Frame this data is associated with
+# 0: no frame
+# 1: global frame
+string frame_id
 `,
 		"sensor_msgs/Joy",
-		"3575d357a5c35c83d263fbb0c5bf3988",
+		"5a9ea5f83505693b71e785041e67a8bb",
 	}
 )
 
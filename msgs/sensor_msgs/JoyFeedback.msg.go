@@ -52,7 +52,7 @@ float32 intensity
 
 `,
 		"sensor_msgs/JoyFeedback",
-		"6a3facdc77535982cb1741407e0f8e73",
+		"f4dcd73460360d98f36e55ee7f2e46f1",
 	}
 )
 
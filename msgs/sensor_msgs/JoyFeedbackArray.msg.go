@@ -38,9 +38,27 @@ func (t *_MsgJoyFeedbackArray) NewMessage() ros.Message {
 var (
 	MsgJoyFeedbackArray = &_MsgJoyFeedbackArray{
 		`# This message publishes values for multiple feedback at once. 
-JoyFeedback[] array`,
+JoyFeedback[] array
+================================================================================
+MSG: sensor_msgs/JoyFeedback
+# Declare of the type of feedback
+uint8 TYPE_LED    = 0
+uint8 TYPE_RUMBLE = 1
+uint8 TYPE_BUZZER = 2
+
+uint8 type
+
+# This will hold an id number for each type of each feedback.
+# Example, the first led would be id=0, the second would be id=1
+uint8 id
+
+# Intensity of the feedback, from 0.0 to 1.0, inclusive.  If device is
+# actually binary, driver should treat 0<=x<0.5 as off, 0.5<=x<=1 as on.
+float32 intensity
+
+`,
 		"sensor_msgs/JoyFeedbackArray",
-		"bdbcb223d565a59aeff3f78ba874e698",
+		"cde5730a895b1fc4dee6f91b754b213d",
 	}
 )
 
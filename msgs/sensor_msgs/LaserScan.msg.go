@@ -67,9 +67,27 @@ float32[] ranges         # range data [m] (Note: values < range_min or > range_m
 float32[] intensities    # intensity data [device-specific units].  If your
                          # device does not provide intensities, please leave
                          # the array empty.
+
+================================================================================
+MSG: std_msgs/Header
+# Standard metadata for higher-level stamped data types.
+# This is generally used to communicate timestamped data 
+# in a particular coordinate frame.
+# 
+# sequence ID: consecutively increasing ID 
+uint32 seq
+#Two-integer timestamp that is expressed as:
+# * stamp.sec: seconds (stamp_secs) since epoch (in Python the variable is called 'secs')
+# * stamp.nsec: nanoseconds since stamp_secs (in Python the variable is called 'nsecs')
+# time-handling sugar is provided by the client library
+time stamp
+#Frame this data is associated with
+# 0: no frame
+# 1: global frame
+string frame_id
 `,
 		"sensor_msgs/LaserScan",
-		"d3b856c897413122882777076e64b0f7",
+		"90c7ef2dc6895d81024acba2ac42f369",
 	}
 )
 
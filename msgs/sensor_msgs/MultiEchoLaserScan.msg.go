@@ -68,9 +68,34 @@ LaserEcho[] ranges       # range data [m] (Note: NaNs, values < range_min or > r
                          # -Inf measurements are too close to determine exact distance.
 LaserEcho[] intensities  # intensity data [device-specific units].  If your
                          # device does not provide intensities, please leave
-                         # the array empty.`,
+                         # the array empty.
+================================================================================
+MSG: std_msgs/Header
+# Standard metadata for higher-level stamped data types.
+# This is generally used to communicate timestamped data 
+# in a particular coordinate frame.
+# 
+# sequence ID: consecutively increasing ID 
+uint32 seq
+#Two-integer timestamp that is expressed as:
+# * stamp.sec: seconds (stamp_secs) since epoch (in Python the variable is called 'secs')
+# * stamp.nsec: nanoseconds since stamp_secs (in Python the variable is called 'nsecs')
+# time-handling sugar is provided by the client library
+time stamp
+#Frame this data is associated with
+# 0: no frame
+# 1: global frame
+string frame_id
+
+================================================================================
+MSG: sensor_msgs/LaserEcho
+# This message is a submessage of MultiEchoLaserScan and is not intended
+# to be used separately.
+
+float32[] echoes  # Multiple values of ranges or intensities.
+                  # Each array represents data from the same angle increment.`,
 		"sensor_msgs/MultiEchoLaserScan",
-		"b80691870c47673fb3c9bf3c06e0a0ab",
+		"6fefb0c6da89d7c8abe4b339f5c2f8fb",
 	}
 )
 
@@ -59,21 +59,16 @@ uint16 SERVICE_GALILEO = 8
 uint16 service
 `,
 		"sensor_msgs/NavSatStatus",
-		"55f78e1710412e6c825c1b8cf022525a",
+		"331cdbddfa4bc96ffc3b9ad98900a54c",
 	}
 )
 
 type NavSatStatus struct {
-	STATUSNOFIX int8
-	Status      int8
-	Service     uint16
+	Status  int8
+	Service uint16
 }
 
 func (m *NavSatStatus) Serialize(w io.Writer) (err error) {
-	if err = ros.SerializeMessageField(w, "int8", &m.STATUSNOFIX); err != nil {
-		return err
-	}
-
 	if err = ros.SerializeMessageField(w, "int8", &m.Status); err != nil {
 		return err
 	}
@@ -86,11 +81,6 @@ func (m *NavSatStatus) Serialize(w io.Writer) (err error) {
 }
 
 func (m *NavSatStatus) Deserialize(r io.Reader) (err error) {
-	// STATUSNOFIX
-	if err = ros.DeserializeMessageField(r, "int8", &m.STATUSNOFIX); err != nil {
-		return err
-	}
-
 	// Status
 	if err = ros.DeserializeMessageField(r, "int8", &m.Status); err != nil {
 		return err
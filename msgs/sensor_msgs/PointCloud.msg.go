@@ -53,9 +53,67 @@ geometry_msgs/Point32[] points
 # and the data in each channel should correspond 1:1 with each point.
 # Channel names in common practice are listed in ChannelFloat32.msg.
 ChannelFloat32[] channels
+
+================================================================================
+MSG: std_msgs/Header
+# Standard metadata for higher-level stamped data types.
+# This is generally used to communicate timestamped data 
+# in a particular coordinate frame.
+# 
+# sequence ID: consecutively increasing ID 
+uint32 seq
+#Two-integer timestamp that is expressed as:
+# * stamp.sec: seconds (stamp_secs) since epoch (in Python the variable is called 'secs')
+# * stamp.nsec: nanoseconds since stamp_secs (in Python the variable is called 'nsecs')
+# time-handling sugar is provided by the client library
+time stamp
+#Frame this data is associated with
+# 0: no frame
+# 1: global frame
+string frame_id
+
+================================================================================
+MSG: geometry_msgs/Point32
+# This contains the position of a point in free space(with 32 bits of precision).
+# It is recommeded to use Point wherever possible instead of Point32.  
+# 
+# This recommendation is to promote interoperability.  
+#
+# This message is designed to take up less space when sending
+# lots of points at once, as in the case of a PointCloud.  
+
+float32 x
+float32 y
+float32 z
+================================================================================
+MSG: sensor_msgs/ChannelFloat32
+# This message is used by the PointCloud message to hold optional data
+# associated with each point in the cloud. The length of the values
+# array should be the same as the length of the points array in the
+# PointCloud, and each value should be associated with the corresponding
+# point.
+
+# Channel names in existing practice include:
+#   "u", "v" - row and column (respectively) in the left stereo image.
+#              This is opposite to usual conventions but remains for
+#              historical reasons. The newer PointCloud2 message has no
+#              such problem.
+#   "rgb" - For point clouds produced by color stereo cameras. uint8
+#           (R,G,B) values packed into the least significant 24 bits,
+#           in order.
+#   "intensity" - laser or pixel intensity.
+#   "distance"
+
+# The channel name should give semantics of the channel (e.g.
+# "intensity" instead of "value").
+string name
+
+# The values array should be 1-1 with the elements of the associated
+# PointCloud.
+float32[] values
 `,
 		"sensor_msgs/PointCloud",
-		"8fd83ffb348b4c6b507856d5fdaa54c2",
+		"d8e9c3f5afbdd8a130fd1d2763945fca",
 	}
 )
 
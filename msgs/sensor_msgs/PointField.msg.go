@@ -52,7 +52,7 @@ uint8  datatype  # Datatype enumeration, see above
 uint32 count     # How many elements in the field
 `,
 		"sensor_msgs/PointField",
-		"e121f1b64f22efa1152073fbaece31b7",
+		"268eacb2962780ceac86cbd17e328150",
 	}
 )
 
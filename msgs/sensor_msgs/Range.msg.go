@@ -75,9 +75,27 @@ float32 range           # range data [m]
                         # -Inf represents a detection within fixed distance.
                         # (Detection too close to the sensor to quantify)
                         # +Inf represents no detection within the fixed distance.
-                        # (Object out of range)`,
+                        # (Object out of range)
+================================================================================
+MSG: std_msgs/Header
+# Standard metadata for higher-level stamped data types.
+# This is generally used to communicate timestamped data 
+# in a particular coordinate frame.
+# 
+# sequence ID: consecutively increasing ID 
+uint32 seq
+#Two-integer timestamp that is expressed as:
+# * stamp.sec: seconds (stamp_secs) since epoch (in Python the variable is called 'secs')
+# * stamp.nsec: nanoseconds since stamp_secs (in Python the variable is called 'nsecs')
+# time-handling sugar is provided by the client library
+time stamp
+#Frame this data is associated with
+# 0: no frame
+# 1: global frame
+string frame_id
+`,
 		"sensor_msgs/Range",
-		"71e981252e448b5283f03f85f1f485dc",
+		"c005c34273dc426c67a020a87bc24148",
 	}
 )
 
@@ -47,9 +47,27 @@ var (
                            # 0.0 is no partial pressure of water vapor
                            # 1.0 represents partial pressure of saturation
 
- float64 variance          # 0 is interpreted as variance unknown`,
+ float64 variance          # 0 is interpreted as variance unknown
+================================================================================
+MSG: std_msgs/Header
+# Standard metadata for higher-level stamped data types.
+# This is generally used to communicate timestamped data 
+# in a particular coordinate frame.
+# 
+# sequence ID: consecutively increasing ID 
+uint32 seq
+#Two-integer timestamp that is expressed as:
+# * stamp.sec: seconds (stamp_secs) since epoch (in Python the variable is called 'secs')
+# * stamp.nsec: nanoseconds since stamp_secs (in Python the variable is called 'nsecs')
+# time-handling sugar is provided by the client library
+time stamp
+#Frame this data is associated with
+# 0: no frame
+# 1: global frame
+string frame_id
+`,
 		"sensor_msgs/RelativeHumidity",
-		"baf803f82c25997678cb475199aba400",
+		"8730015b05955b7e992ce29a2678d90f",
 	}
 )
 
@@ -43,9 +43,27 @@ Header header    # stamp is system time for which measurement was valid
 
 time   time_ref  # corresponding time from this external source
 string source    # (optional) name of time source
+
+================================================================================
+MSG: std_msgs/Header
+# Standard metadata for higher-level stamped data types.
+# This is generally used to communicate timestamped data 
+# in a particular coordinate frame.
+# 
+# sequence ID: consecutively increasing ID 
+uint32 seq
+#Two-integer timestamp that is expressed as:
+# * stamp.sec: seconds (stamp_secs) since epoch (in Python the variable is called 'secs')
+# * stamp.nsec: nanoseconds since stamp_secs (in Python the variable is called 'nsecs')
+# time-handling sugar is provided by the client library
+time stamp
+#Frame this data is associated with
+# 0: no frame
+# 1: global frame
+string frame_id
 `,
 		"sensor_msgs/TimeReference",
-		"ef08a08cc0648eb4e42fa2a172b1731a",
+		"fded64a0265108ba86c3d38fb11c0c16",
 	}
 )
 
@@ -45,9 +45,21 @@ MeshTriangle[] triangles
 
 # the actual vertices that make up the mesh
 geometry_msgs/Point[] vertices
+
+================================================================================
+MSG: shape_msgs/MeshTriangle
+# Definition of a triangle's vertices
+uint32[3] vertex_indices
+
+================================================================================
+MSG: geometry_msgs/Point
+# This contains the position of a point in free space
+float64 x
+float64 y
+float64 z
 `,
 		"shape_msgs/Mesh",
-		"de320644698bf336f8f37512079d2586",
+		"1ffdae9486cd3316a121c578b47a85cc",
 	}
 )
 
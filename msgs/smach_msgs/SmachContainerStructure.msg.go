@@ -54,9 +54,27 @@ string[] outcomes_to
 
 # The potential outcomes from this container
 string[] container_outcomes
+
+================================================================================
+MSG: std_msgs/Header
+# Standard metadata for higher-level stamped data types.
+# This is generally used to communicate timestamped data 
+# in a particular coordinate frame.
+# 
+# sequence ID: consecutively increasing ID 
+uint32 seq
+#Two-integer timestamp that is expressed as:
+# * stamp.sec: seconds (stamp_secs) since epoch (in Python the variable is called 'secs')
+# * stamp.nsec: nanoseconds since stamp_secs (in Python the variable is called 'nsecs')
+# time-handling sugar is provided by the client library
+time stamp
+#Frame this data is associated with
+# 0: no frame
+# 1: global frame
+string frame_id
 `,
 		"smach_msgs/SmachContainerStructure",
-		"01c82f66ac449a8d875e9aebdab7682e",
+		"3d3d1e0d0f99779ee9e58101a5dcf7ea",
 	}
 )
 
@@ -52,7 +52,7 @@ time stamp
 string frame_id
 `,
 		"std_msgs/Header",
-		"b570e0075f4022ff277e41bba54229f5",
+		"2176decaecbce78abc3b96ef049fabed",
 	}
 )
 
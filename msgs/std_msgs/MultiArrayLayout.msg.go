@@ -63,9 +63,14 @@ uint32 data_offset        # padding elements at front of data
 # dim[2].stride = 3
 #
 # multiarray(i,j,k) refers to the ith row, jth column, and kth channel.
-`,
+
+================================================================================
+MSG: std_msgs/MultiArrayDimension
+string label   # label of given dimension
+uint32 size    # size of given dimension (in type units)
+uint32 stride  # stride of given dimension`,
 		"std_msgs/MultiArrayLayout",
-		"9e109a43d67cb5458e2e5239bb985dee",
+		"0fed2a11c13e11c5571b4e2a995a91a3",
 	}
 )
 
@@ -38,7 +38,7 @@ var (
 		`uint16 data
 `,
 		"std_msgs/UInt16",
-		"fd863ad7ac309d337ff46193bc0af4c0",
+		"1df79edf208b629fe6b81923a544552d",
 	}
 )
 
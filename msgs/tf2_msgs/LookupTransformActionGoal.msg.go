@@ -42,9 +42,57 @@ var (
 Header header
 actionlib_msgs/GoalID goal_id
 LookupTransformGoal goal
+
+================================================================================
+MSG: std_msgs/Header
+# Standard metadata for higher-level stamped data types.
+# This is generally used to communicate timestamped data 
+# in a particular coordinate frame.
+# 
+# sequence ID: consecutively increasing ID 
+uint32 seq
+#Two-integer timestamp that is expressed as:
+# * stamp.sec: seconds (stamp_secs) since epoch (in Python the variable is called 'secs')
+# * stamp.nsec: nanoseconds since stamp_secs (in Python the variable is called 'nsecs')
+# time-handling sugar is provided by the client library
+time stamp
+#Frame this data is associated with
+# 0: no frame
+# 1: global frame
+string frame_id
+
+================================================================================
+MSG: actionlib_msgs/GoalID
+# The stamp should store the time at which this goal was requested.
+# It is used by an action server when it tries to preempt all
+# goals that were requested before a certain time
+time stamp
+
+# The id provides a way to associate feedback and
+# result message with specific goal requests. The id
+# specified must be unique.
+string id
+
+
+================================================================================
+MSG: tf2_msgs/LookupTransformGoal
+# ====== DO NOT MODIFY! AUTOGENERATED FROM AN ACTION DEFINITION ======
+#Simple API
+string target_frame
+string source_frame
+time source_time
+duration timeout
+
+#Advanced API
+time target_time
+string fixed_frame
+
+#Whether or not to use the advanced API
+bool advanced
+
 `,
 		"tf2_msgs/LookupTransformActionGoal",
-		"0dc122e6d207537854c1091c7efaea8b",
+		"f2e7bcdb75c847978d0351a13e699da5",
 	}
 )
 
@@ -39,7 +39,7 @@ var (
 
 `,
 		"tf2_msgs/LookupTransformFeedback",
-		"8caa57ae31d0a78f6b633986943eda51",
+		"d41d8cd98f00b204e9800998ecf8427e",
 	}
 )
 
@@ -48,9 +48,74 @@ Header header
 
 string[] joint_names
 MultiDOFJointTrajectoryPoint[] points
+
+================================================================================
+MSG: std_msgs/Header
+# Standard metadata for higher-level stamped data types.
+# This is generally used to communicate timestamped data 
+# in a particular coordinate frame.
+# 
+# sequence ID: consecutively increasing ID 
+uint32 seq
+#Two-integer timestamp that is expressed as:
+# * stamp.sec: seconds (stamp_secs) since epoch (in Python the variable is called 'secs')
+# * stamp.nsec: nanoseconds since stamp_secs (in Python the variable is called 'nsecs')
+# time-handling sugar is provided by the client library
+time stamp
+#Frame this data is associated with
+# 0: no frame
+# 1: global frame
+string frame_id
+
+================================================================================
+MSG: trajectory_msgs/MultiDOFJointTrajectoryPoint
+# Each multi-dof joint can specify a transform (up to 6 DOF)
+geometry_msgs/Transform[] transforms
+
+# There can be a velocity specified for the origin of the joint 
+geometry_msgs/Twist[] velocities
+
+# There can be an acceleration specified for the origin of the joint 
+geometry_msgs/Twist[] accelerations
+
+duration time_from_start
+
+================================================================================
+MSG: geometry_msgs/Transform
+# This represents the transform between two coordinate frames in free space.
+
+Vector3 translation
+Quaternion rotation
+
+================================================================================
+MSG: geometry_msgs/Vector3
+# This represents a vector in free space. 
+# It is only meant to represent a direction. Therefore, it does not
+# make sense to apply a translation to it (e.g., when applying a 
+# generic rigid transformation to a Vector3, tf2 will only apply the
+# rotation). If you want your data to be translatable too, use the
+# geometry_msgs/Point message instead.
+
+float64 x
+float64 y
+float64 z
+================================================================================
+MSG: geometry_msgs/Quaternion
+# This represents an orientation in free space in quaternion form.
+
+float64 x
+float64 y
+float64 z
+float64 w
+
+================================================================================
+MSG: geometry_msgs/Twist
+# This expresses velocity in free space broken into its linear and angular parts.
+Vector3  linear
+Vector3  angular
 `,
 		"trajectory_msgs/MultiDOFJointTrajectory",
-		"e19d4216caa321543b869ca5b1f5e224",
+		"ef145a45a5f47b77b7f5cdde4b16c942",
 	}
 )
 
@@ -62,9 +62,41 @@ duration lifetime       # How long the object should last before being automatic
 
 
 geometry_msgs/Point[] points # used for LINE_STRIP/LINE_LIST/POINTS/etc., 2D in pixel coords
-std_msgs/ColorRGBA[] outline_colors # a color for each line, point, etc.`,
+std_msgs/ColorRGBA[] outline_colors # a color for each line, point, etc.
+================================================================================
+MSG: std_msgs/Header
+# Standard metadata for higher-level stamped data types.
+# This is generally used to communicate timestamped data 
+# in a particular coordinate frame.
+# 
+# sequence ID: consecutively increasing ID 
+uint32 seq
+#Two-integer timestamp that is expressed as:
+# * stamp.sec: seconds (stamp_secs) since epoch (in Python the variable is called 'secs')
+# * stamp.nsec: nanoseconds since stamp_secs (in Python the variable is called 'nsecs')
+# time-handling sugar is provided by the client library
+time stamp
+#Frame this data is associated with
+# 0: no frame
+# 1: global frame
+string frame_id
+
+================================================================================
+MSG: geometry_msgs/Point
+# This contains the position of a point in free space
+float64 x
+float64 y
+float64 z
+
+================================================================================
+MSG: std_msgs/ColorRGBA
+float32 r
+float32 g
+float32 b
+float32 a
+`,
 		"visualization_msgs/ImageMarker",
-		"32f5635ee965c48f5d3f565629b204e2",
+		"1de93c67ec8858b831025a08fbf1b35c",
 	}
 )
 
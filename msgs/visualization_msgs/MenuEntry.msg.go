@@ -91,7 +91,7 @@ uint8 ROSLAUNCH=2
 uint8 command_type
 `,
 		"visualization_msgs/MenuEntry",
-		"79f8c1595a875595df6690a4d1a3ac66",
+		"b90ec63024573de83b57aa93eb39be2d",
 	}
 )
 
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"crypto/md5"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// searchPath collects repeated directory flags.  Each -I flag is either a
+// directory to search for packages or pkg:dir, naming the directory holding
+// pkg's .msg files as genmsg does.
+type searchPath []string
+
+func (s *searchPath) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *searchPath) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// msgContext loads the message definitions a spec depends on and computes
+// MD5 sums and full definition texts compatible with genmsg.
+type msgContext struct {
+	pkgDirs map[string][]string // Package to directories with its .msg files.
+	roots   []string            // Directories to search for packages.
+	specs   map[string]*MsgSpec // Loaded specs by full type name.
+	md5Sums map[string]string   // Computed MD5 sums by full type name.
+}
+
+// Create a context searching the -I flags in includes, then the entries of
+// ROS_PACKAGE_PATH.
+func newMsgContext(includes []string) *msgContext {
+	c := &msgContext{
+		pkgDirs: make(map[string][]string),
+		specs:   make(map[string]*MsgSpec),
+		md5Sums: make(map[string]string),
+	}
+	for _, include := range includes {
+		if i := strings.Index(include, ":"); i > 0 {
+			pkg := include[:i]
+			c.pkgDirs[pkg] = append(c.pkgDirs[pkg], include[i+1:])
+		} else {
+			c.roots = append(c.roots, include)
+		}
+	}
+	for _, root := range filepath.SplitList(os.Getenv("ROS_PACKAGE_PATH")) {
+		if root != "" {
+			c.roots = append(c.roots, root)
+		}
+	}
+	return c
+}
+
+// Register a spec which was parsed from a file given on the command line so
+// it isn't looked up again.
+func (c *msgContext) register(spec *MsgSpec) {
+	c.specs[spec.FullName()] = spec
+}
+
+// Find the .msg file of a full type name such as std_msgs/Header.
+func (c *msgContext) find(fullName string) (string, error) {
+	items := strings.SplitN(fullName, "/", 2)
+	pkg, name := items[0], items[1]
+	for _, dir := range c.pkgDirs[pkg] {
+		path := filepath.Join(dir, name+".msg")
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	for _, root := range c.roots {
+		// Catkin packages keep messages in msg/; the msgs tree in this
+		// repository keeps them directly in the package directory.
+		for _, path := range []string{
+			filepath.Join(root, pkg, "msg", name+".msg"),
+			filepath.Join(root, pkg, name+".msg"),
+		} {
+			if _, err := os.Stat(path); err == nil {
+				return path, nil
+			}
+		}
+		var found string
+		filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil || found != "" {
+				return filepath.SkipDir
+			}
+			if info.IsDir() && info.Name() == pkg {
+				candidate := filepath.Join(path, "msg", name+".msg")
+				if _, err := os.Stat(candidate); err == nil {
+					found = candidate
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		})
+		if found != "" {
+			return found, nil
+		}
+	}
+	return "", fmt.Errorf("cannot find definition of %s; add its package with -I or ROS_PACKAGE_PATH", fullName)
+}
+
+// Load the spec of a full type name, reading it from the search path the
+// first time.
+func (c *msgContext) load(fullName string) (*MsgSpec, error) {
+	if spec, ok := c.specs[fullName]; ok {
+		return spec, nil
+	}
+	path, err := c.find(fullName)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	items := strings.SplitN(fullName, "/", 2)
+	spec, err := parseMsgSpec(items[0], items[1], data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %s", path, err)
+	}
+	c.specs[fullName] = spec
+	return spec, nil
+}
+
+// Resolve the type of a field to a full type name.  Header means
+// std_msgs/Header and unqualified names refer to the spec's own package.
+func resolveType(packageName string, rosType string) string {
+	if rosType == "Header" {
+		return "std_msgs/Header"
+	}
+	if !strings.Contains(rosType, "/") {
+		return packageName + "/" + rosType
+	}
+	return rosType
+}
+
+// The text genmsg hashes for a spec: constants, then fields, without
+// comments or extra whitespace, and with the types of nested messages
+// replaced by their MD5 sums.
+func (c *msgContext) md5Text(spec *MsgSpec) (string, error) {
+	var lines []string
+	for _, constant := range spec.Constants {
+		lines = append(lines, fmt.Sprintf("%s %s=%s", constant.TypeName, constant.rosName, constant.Value))
+	}
+	for _, field := range spec.Fields {
+		if field.BuiltIn {
+			lines = append(lines, fmt.Sprintf("%s%s %s", field.TypeName, field.arraySuffix, field.rosName))
+			continue
+		}
+		sum, err := c.md5Sum(resolveType(spec.PackageName, field.TypeName))
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, fmt.Sprintf("%s %s", sum, field.rosName))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// The MD5 sum of a full type name.
+func (c *msgContext) md5Sum(fullName string) (string, error) {
+	if sum, ok := c.md5Sums[fullName]; ok {
+		return sum, nil
+	}
+	spec, err := c.load(fullName)
+	if err != nil {
+		return "", err
+	}
+	text, err := c.md5Text(spec)
+	if err != nil {
+		return "", err
+	}
+	sum := fmt.Sprintf("%x", md5.Sum([]byte(text)))
+	c.md5Sums[fullName] = sum
+	return sum, nil
+}
+
+// The MD5 sum of a service: the hash of its request and response texts.
+func (c *msgContext) srvMD5Sum(spec *SrvSpec) (string, error) {
+	reqText, err := c.md5Text(spec.RequestSpec)
+	if err != nil {
+		return "", err
+	}
+	resText, err := c.md5Text(spec.ResponseSpec)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", md5.Sum([]byte(reqText+resText))), nil
+}
+
+// Full type names of the messages a spec uses, directly or indirectly, in
+// the depth-first order genmsg lists them.
+func (c *msgContext) dependencies(spec *MsgSpec) ([]string, error) {
+	var deps []string
+	seen := make(map[string]bool)
+	for _, field := range spec.Fields {
+		if field.BuiltIn {
+			continue
+		}
+		fullName := resolveType(spec.PackageName, field.TypeName)
+		if seen[fullName] {
+			continue
+		}
+		seen[fullName] = true
+		dep, err := c.load(fullName)
+		if err != nil {
+			return nil, err
+		}
+		nested, err := c.dependencies(dep)
+		if err != nil {
+			return nil, err
+		}
+		deps = append(deps, fullName)
+		deps = append(deps, nested...)
+	}
+	return deps, nil
+}
+
+// The message_definition of a spec: its own text followed by the text of
+// every message it depends on, each in a MSG: section.
+func (c *msgContext) fullText(spec *MsgSpec) (string, error) {
+	deps, err := c.dependencies(spec)
+	if err != nil {
+		return "", err
+	}
+	text := spec.Raw + "\n"
+	added := make(map[string]bool)
+	for _, fullName := range deps {
+		if added[fullName] {
+			continue
+		}
+		added[fullName] = true
+		dep, err := c.load(fullName)
+		if err != nil {
+			return "", err
+		}
+		text += strings.Repeat("=", 80) + "\n"
+		text += "MSG: " + fullName + "\n"
+		text += dep.Raw + "\n"
+	}
+	return strings.TrimSuffix(text, "\n"), nil
+}
+
+// Fill in the MD5 sum and full text of a message spec.
+func (c *msgContext) complete(spec *MsgSpec) (err error) {
+	if spec.MD5Sum, err = c.md5Sum(spec.FullName()); err != nil {
+		return err
+	}
+	spec.Text, err = c.fullText(spec)
+	return err
+}
+
+// Register the request and response of a service.
+func (c *msgContext) registerSrv(spec *SrvSpec) {
+	c.register(spec.RequestSpec)
+	c.register(spec.ResponseSpec)
+}
+
+// Fill in the MD5 sums and full texts of a service and its request and
+// response.
+func (c *msgContext) completeSrv(spec *SrvSpec) (err error) {
+	for _, msgSpec := range []*MsgSpec{spec.RequestSpec, spec.ResponseSpec} {
+		if err = c.complete(msgSpec); err != nil {
+			return err
+		}
+	}
+	spec.MD5Sum, err = c.srvMD5Sum(spec)
+	return err
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMD5Sum(t *testing.T) {
+	// MD5 sums published by genmsg for the definitions in ../msgs.
+	expected := map[string]string{
+		"std_msgs/String":                "992ce8a1687cec8c8bd883ec73ca41d1",
+		"std_msgs/Header":                "2176decaecbce78abc3b96ef049fabed",
+		"actionlib_msgs/GoalStatusArray": "8b2b82f13216d0a8ea88bd3af735e619",
+		"sensor_msgs/NavSatStatus":       "331cdbddfa4bc96ffc3b9ad98900a54c",
+		"geometry_msgs/PoseStamped":      "d3812c3cbc69362b77dc0b19b345f8f5",
+		"visualization_msgs/Marker":      "4048c9de2a16f4ae8e0538085ebf1b97",
+	}
+	c := newMsgContext([]string{"../msgs"})
+	for fullName, sum := range expected {
+		actual, err := c.md5Sum(fullName)
+		if err != nil {
+			t.Errorf("%s: %s", fullName, err)
+		} else if actual != sum {
+			t.Errorf("%s: expected MD5 sum %s; got %s", fullName, sum, actual)
+		}
+	}
+}
+
+func TestSrvMD5Sum(t *testing.T) {
+	spec, err := parseSrvSpec("rospy_tutorials", "AddTwoInts", []byte("int64 a\nint64 b\n---\nint64 sum\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := newMsgContext(nil).srvMD5Sum(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum != "6a2e34150c00229791cc89ff309fff21" {
+		t.Errorf("expected MD5 sum 6a2e34150c00229791cc89ff309fff21; got %s", sum)
+	}
+}
+
+func TestFullText(t *testing.T) {
+	c := newMsgContext([]string{"../msgs"})
+	spec, err := c.load("geometry_msgs/PoseStamped")
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, err := c.fullText(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sections []string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(line, "MSG: ") {
+			sections = append(sections, line[5:])
+		}
+	}
+	expected := []string{"std_msgs/Header", "geometry_msgs/Pose", "geometry_msgs/Point", "geometry_msgs/Quaternion"}
+	if strings.Join(sections, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected sections %v; got %v", expected, sections)
+	}
+	if !strings.HasPrefix(text, spec.Raw) {
+		t.Error("full text doesn't start with the message's own definition")
+	}
+}
+
+func TestParseConstant(t *testing.T) {
+	tests := []struct {
+		line, rosType, rosName, value string
+	}{
+		{"int8 NO_FIX = -1 # unable to fix", "int8", "NO_FIX", "-1"},
+		{"float64 PI=3.14", "float64", "PI", "3.14"},
+		{"string GREETING = hello # world ", "string", "GREETING", "hello # world"},
+	}
+	for _, test := range tests {
+		constant, err := parseConstant(test.line)
+		if err != nil {
+			t.Errorf("%q: %s", test.line, err)
+			continue
+		}
+		if constant.TypeName != test.rosType || constant.rosName != test.rosName || constant.Value != test.value {
+			t.Errorf("%q: got %s %s=%s", test.line, constant.TypeName, constant.rosName, constant.Value)
+		}
+	}
+}
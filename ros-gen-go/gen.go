@@ -0,0 +1,284 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// The first line genaction writes in the messages it derives from an action.
+const actionAutogen = "# ====== DO NOT MODIFY! AUTOGENERATED FROM AN ACTION DEFINITION ======"
+
+// rosPackage is a package found by gen and the definition files in its msg/,
+// srv/ and action/ directories.
+type rosPackage struct {
+	name    string
+	dir     string
+	msgs    []string
+	srvs    []string
+	actions []string
+}
+
+// Find the packages under roots: directories with msg/, srv/ or action/
+// directories holding definitions.  Hidden directories, directories with a
+// CATKIN_IGNORE file, such as catkin's build space, and catkin devel and
+// install spaces below a root, which have a .catkin file and copies of the
+// messages, are skipped.
+func findPackages(roots []string) (map[string]*rosPackage, error) {
+	packages := make(map[string]*rosPackage)
+	for _, root := range roots {
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() {
+				return nil
+			}
+			if path != root && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "CATKIN_IGNORE")); err == nil {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, ".catkin")); err == nil && path != root {
+				return filepath.SkipDir
+			}
+			var ext string
+			switch info.Name() {
+			case "msg", "srv", "action":
+				ext = "." + info.Name()
+			default:
+				return nil
+			}
+			files, err := filepath.Glob(filepath.Join(path, "*"+ext))
+			if err != nil {
+				return err
+			}
+			if len(files) == 0 {
+				return filepath.SkipDir
+			}
+			dir := filepath.Dir(path)
+			name := filepath.Base(dir)
+			pkg, ok := packages[name]
+			if !ok {
+				pkg = &rosPackage{name: name, dir: dir}
+				packages[name] = pkg
+			} else if pkg.dir != dir {
+				return fmt.Errorf("package %s found in both %s and %s", name, pkg.dir, dir)
+			}
+			switch ext {
+			case ".msg":
+				pkg.msgs = files
+			case ".srv":
+				pkg.srvs = files
+			case ".action":
+				pkg.actions = files
+			}
+			return filepath.SkipDir
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return packages, nil
+}
+
+// actionMessage is a message genaction derives from an action.
+type actionMessage struct {
+	name string
+	data []byte
+}
+
+// The messages genaction derives from an action: the goal, result and
+// feedback, the action goal, result and feedback which wrap them with a
+// header and goal ID or status, and the action which holds all three.
+func actionMessages(name string, data []byte) ([]actionMessage, error) {
+	pieces := []string{""}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "---") {
+			pieces = append(pieces, "")
+			continue
+		}
+		pieces[len(pieces)-1] += line + "\n"
+	}
+	if len(pieces) != 3 {
+		return nil, fmt.Errorf("action must have goal, result and feedback sections separated by ---; found %d sections", len(pieces))
+	}
+	derived := func(lines ...string) []byte {
+		return []byte(actionAutogen + "\n\n" + strings.Join(lines, "\n") + "\n")
+	}
+	return []actionMessage{
+		{name + "Goal", []byte(actionAutogen + "\n" + pieces[0])},
+		{name + "Result", []byte(actionAutogen + "\n" + pieces[1])},
+		{name + "Feedback", []byte(actionAutogen + "\n" + pieces[2])},
+		{name + "ActionGoal", derived("Header header", "actionlib_msgs/GoalID goal_id", name+"Goal goal")},
+		{name + "ActionResult", derived("Header header", "actionlib_msgs/GoalStatus status", name+"Result result")},
+		{name + "ActionFeedback", derived("Header header", "actionlib_msgs/GoalStatus status", name+"Feedback feedback")},
+		{name + "Action", derived(name+"ActionGoal action_goal", name+"ActionResult action_result", name+"ActionFeedback action_feedback")},
+	}, nil
+}
+
+// generated is a file gen will write.
+type generated struct {
+	templateType string
+	fileInfo     FileInfo
+	spec         interface{}
+	packages     []string
+}
+
+func nameOf(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+// Parse every definition of a package, registering the specs with c so
+// other packages can refer to them.
+func parsePackage(c *msgContext, pkg *rosPackage) ([]*generated, error) {
+	var files []*generated
+	addMsg := func(path string, name string, data []byte) error {
+		spec, err := parseMsgSpec(pkg.name, name, data)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %s", path, err)
+		}
+		c.register(spec)
+		files = append(files, &generated{
+			templateType: "msg",
+			fileInfo: FileInfo{
+				InFile:      path,
+				InFileBase:  filepath.Base(path),
+				Raw:         string(data),
+				PackageName: pkg.name,
+				Name:        name,
+			},
+			spec:     spec,
+			packages: spec.Packages(),
+		})
+		return nil
+	}
+
+	for _, path := range pkg.msgs {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err = addMsg(path, nameOf(path), data); err != nil {
+			return nil, err
+		}
+	}
+	for _, path := range pkg.actions {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		msgs, err := actionMessages(nameOf(path), data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %s", path, err)
+		}
+		for _, msg := range msgs {
+			if err = addMsg(path, msg.name, msg.data); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, path := range pkg.srvs {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		spec, err := parseSrvSpec(pkg.name, nameOf(path), data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %s", path, err)
+		}
+		c.registerSrv(spec)
+		files = append(files, &generated{
+			templateType: "srv",
+			fileInfo: FileInfo{
+				InFile:      path,
+				InFileBase:  filepath.Base(path),
+				Raw:         string(data),
+				PackageName: pkg.name,
+				Name:        spec.Name,
+			},
+			spec:     spec,
+			packages: spec.Packages(),
+		})
+	}
+	return files, nil
+}
+
+// Generate a Go package in outDir for every package found in roots.  Each
+// package is imported as importPrefix/<package>; nested types are looked up
+// in the packages found first, then in includes and ROS_PACKAGE_PATH.
+func generatePackages(roots []string, outDir string) error {
+	packages, err := findPackages(roots)
+	if err != nil {
+		return err
+	}
+	if len(packages) == 0 {
+		return fmt.Errorf("no packages with msg, srv or action directories found in %s", strings.Join(roots, ", "))
+	}
+	var names []string
+	for name := range packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	c := newMsgContext(includes)
+	var files []*generated
+	for _, name := range names {
+		pkgFiles, err := parsePackage(c, packages[name])
+		if err != nil {
+			return err
+		}
+		files = append(files, pkgFiles...)
+	}
+
+	templates := make(map[string]*template.Template)
+	for _, templateType := range []string{"msg", "srv"} {
+		if templates[templateType], err = loadTemplate(templateType); err != nil {
+			return err
+		}
+	}
+	missing := make(map[string]bool)
+	for _, file := range files {
+		switch spec := file.spec.(type) {
+		case *MsgSpec:
+			err = c.complete(spec)
+		case *SrvSpec:
+			err = c.completeSrv(spec)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %s", file.fileInfo.InFile, err)
+		}
+		for _, pkg := range file.packages {
+			name := strings.TrimPrefix(pkg, importPrefix+"/")
+			if name != pkg && packages[name] == nil && !missing[name] {
+				missing[name] = true
+				log.Printf("WARNING: %s is imported but was not generated; add its package with --pkg-path", pkg)
+			}
+		}
+
+		source, err := render(templates[file.templateType], file.fileInfo, file.spec)
+		if err != nil {
+			return fmt.Errorf("%s: %s", file.fileInfo.InFile, err)
+		}
+
+		outfile := filepath.Join(outDir, file.fileInfo.PackageName, fmt.Sprintf("%s.%s.go", file.fileInfo.Name, file.templateType))
+		if dryRun {
+			fmt.Printf("// %s\n%s\n", outfile, source)
+			continue
+		}
+		if err = os.MkdirAll(filepath.Dir(outfile), 0755); err != nil {
+			return err
+		}
+		if err = ioutil.WriteFile(outfile, source, 0644); err != nil {
+			return fmt.Errorf("failed to write go file: %s", err)
+		}
+		log.Printf("Wrote %s from %s", outfile, file.fileInfo.InFile)
+	}
+	return nil
+}
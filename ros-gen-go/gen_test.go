@@ -0,0 +1,116 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The GripperCommand action as written in control_msgs, rebuilt from the
+// messages genaction derived from it in ../msgs/control_msgs.  genaction
+// writes each line of a section followed by a newline, so the last section
+// gains one from the end of the file.
+func gripperCommandAction(t *testing.T) []byte {
+	var sections []string
+	for _, name := range []string{"Goal", "Result", "Feedback"} {
+		data, err := ioutil.ReadFile(filepath.Join("../msgs/control_msgs", "GripperCommand"+name+".msg"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sections = append(sections, strings.TrimPrefix(string(data), actionAutogen+"\n"))
+	}
+	return []byte(strings.TrimSuffix(strings.Join(sections, "---\n"), "\n"))
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestActionMessages(t *testing.T) {
+	msgs, err := actionMessages("GripperCommand", gripperCommandAction(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 7 {
+		t.Fatalf("expected 7 messages; got %d", len(msgs))
+	}
+	for _, msg := range msgs {
+		expected, err := ioutil.ReadFile(filepath.Join("../msgs/control_msgs", msg.name+".msg"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(msg.data) != string(expected) {
+			t.Errorf("%s: expected\n%q\ngot\n%q", msg.name, expected, msg.data)
+		}
+	}
+
+	if _, err := actionMessages("Bad", []byte("int32 goal\n---\nint32 result\n")); err == nil {
+		t.Error("expected an action without feedback to fail")
+	}
+}
+
+func TestGeneratePackages(t *testing.T) {
+	defer func(prefix string, paths searchPath) {
+		importPrefix, includes = prefix, paths
+	}(importPrefix, includes)
+	importPrefix = "example.com/msgs"
+	includes = searchPath{"../msgs"}
+
+	ws := t.TempDir()
+	out := t.TempDir()
+	gripperCommand, err := ioutil.ReadFile("../msgs/control_msgs/GripperCommand.msg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(ws, "src/control_msgs/msg/GripperCommand.msg"), gripperCommand)
+	writeFile(t, filepath.Join(ws, "src/control_msgs/action/GripperCommand.action"), gripperCommandAction(t))
+	writeFile(t, filepath.Join(ws, "src/robot/srv/Grip.srv"), []byte("control_msgs/GripperCommand command\n---\nbool ok\n"))
+	writeFile(t, filepath.Join(ws, "src/ignored/CATKIN_IGNORE"), nil)
+	writeFile(t, filepath.Join(ws, "src/ignored/msg/Ignored.msg"), []byte("int32 x\n"))
+	// A workspace which has been built has the package's messages again in
+	// its devel space, with those genaction derived.
+	writeFile(t, filepath.Join(ws, "devel/.catkin"), []byte(filepath.Join(ws, "src")))
+	writeFile(t, filepath.Join(ws, "devel/share/control_msgs/msg/GripperCommand.msg"), gripperCommand)
+	writeFile(t, filepath.Join(ws, "devel/share/control_msgs/msg/GripperCommandGoal.msg"), []byte("float64 position\n"))
+	writeFile(t, filepath.Join(ws, "build/CATKIN_IGNORE"), nil)
+	writeFile(t, filepath.Join(ws, "build/control_msgs/msg/GripperCommand.msg"), gripperCommand)
+
+	if err := generatePackages([]string{ws}, out); err != nil {
+		t.Fatal(err)
+	}
+
+	// Generated messages match those generated one at a time in ../msgs.
+	for _, name := range []string{"GripperCommand", "GripperCommandGoal", "GripperCommandActionGoal", "GripperCommandAction"} {
+		actual, err := ioutil.ReadFile(filepath.Join(out, "control_msgs", name+".msg.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := ioutil.ReadFile(filepath.Join("../msgs/control_msgs", name+".msg.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		source := strings.Replace(string(actual), "example.com/msgs/", "github.com/ppg/rosgo/msgs/", -1)
+		source = strings.Replace(source, "// source: GripperCommand.action\n", "// source: "+name+".msg\n", 1)
+		if source != string(expected) {
+			t.Errorf("%s.msg.go differs from ../msgs/control_msgs:\n%s", name, actual)
+		}
+	}
+
+	srv, err := ioutil.ReadFile(filepath.Join(out, "robot", "Grip.srv.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(srv), "package robot\n") || !strings.Contains(string(srv), `"example.com/msgs/control_msgs"`) {
+		t.Errorf("Grip.srv.go doesn't import control_msgs from the prefix:\n%s", srv)
+	}
+	if _, err := os.Stat(filepath.Join(out, "ignored")); !os.IsNotExist(err) {
+		t.Error("package with CATKIN_IGNORE was generated")
+	}
+}
@@ -3,7 +3,6 @@ package main
 import (
 	"bufio"
 	"bytes"
-	"crypto/md5"
 	"fmt"
 	"go/parser"
 	"go/printer"
@@ -23,10 +22,13 @@ import (
 )
 
 var (
-	infile      string
-	outfile     string
-	packageName string
-	dryRun      bool
+	infile       string
+	outfile      string
+	packageName  string
+	dryRun       bool
+	includes     searchPath
+	pkgPaths     searchPath
+	importPrefix string
 )
 
 //go:generate go-bindata -o tmpl.go msg.partial.tmpl msg.tmpl srv.tmpl
@@ -39,9 +41,12 @@ func main() {
 	log.SetFlags(0)
 
 	flag.StringVarP(&infile, "in", "i", "", "input file")
-	flag.StringVarP(&outfile, "out", "o", "", "output file; defaults to '<input file>.go'")
+	flag.StringVarP(&outfile, "out", "o", "", "output file; defaults to '<input file>.go'; for gen, the directory to write packages to")
 	flag.StringVarP(&packageName, "package", "p", "", "package name for generated file; defaults to 'msgs' or 'srvs'")
 	flag.BoolVar(&dryRun, "dry-run", false, "output the file that would be generated to stdout")
+	flag.VarP(&includes, "include", "I", "directory to search for packages of nested message types, or pkg:dir with the .msg files of pkg; may be repeated, and ROS_PACKAGE_PATH is searched after")
+	flag.Var(&pkgPaths, "pkg-path", "for gen, a directory such as a catkin workspace to search for packages with msg/, srv/ or action/ directories; may be repeated")
+	flag.StringVar(&importPrefix, "import-prefix", "", "for gen, the import path the generated packages are written under, such as github.com/me/project/msgs")
 	flag.Parse()
 
 	if flag.NArg() < 1 {
@@ -50,46 +55,30 @@ func main() {
 		os.Exit(1)
 	}
 	templateType := flag.Arg(0)
-	if packageName == "" {
-		packageName = templateType + "s"
-	}
-
-	basename := fmt.Sprintf("%s.tmpl", templateType)
-	data, err := Asset(basename)
-	if err != nil {
-		log.Printf("unrecognized generator template: %s (%s)", templateType, err)
+	if flag.NArg() > 1 {
+		log.Printf("unrecognized arguments: %v", flag.Args()[1:])
 		flag.PrintDefaults()
 		os.Exit(1)
 	}
-	tmpl := template.New(basename)
-	tmpl = tmpl.Funcs(map[string]interface{}{
-		// HACK(ppg): Allow setting a loop variable a struct so we can use it
-		"setloopvar": func(setter loopVarSetter, value interface{}) interface{} {
-			setter.SetLoopVar(value)
-			return setter
-		},
-	})
-	tmpl, err = tmpl.Parse(string(data))
-	if err != nil {
-		log.Printf("unable to template %s: %s", templateType, err)
-		os.Exit(1)
+
+	if templateType == "gen" {
+		if len(pkgPaths) == 0 || outfile == "" || importPrefix == "" {
+			log.Printf("gen requires --pkg-path, --out and --import-prefix")
+			flag.PrintDefaults()
+			os.Exit(1)
+		}
+		if err := generatePackages(pkgPaths, outfile); err != nil {
+			log.Fatal(err)
+		}
+		return
 	}
 
-	data, err = Asset("msg.partial.tmpl")
-	if err != nil {
-		log.Printf("unrecognized generator template: %s (%s)", templateType, err)
-		flag.PrintDefaults()
-		os.Exit(1)
+	if packageName == "" {
+		packageName = templateType + "s"
 	}
-	tmpl2 := tmpl.New("msg.partial.tmpl")
-	_, err = tmpl2.Parse(string(data))
+	tmpl, err := loadTemplate(templateType)
 	if err != nil {
-		log.Printf("unable to template %s: %s", templateType, err)
-		os.Exit(1)
-	}
-
-	if flag.NArg() > 1 {
-		log.Printf("unrecognized arguments: %v", flag.Args()[1:])
+		log.Print(err)
 		flag.PrintDefaults()
 		os.Exit(1)
 	}
@@ -104,22 +93,22 @@ func main() {
 	}
 
 	// Read input file
-	data, err = ioutil.ReadFile(infile)
+	data, err := ioutil.ReadFile(infile)
 	if err != nil {
 		log.Fatalf("failed to read infile %s: %s", infile, err)
 	}
-	basename = filepath.Base(infile)
+	basename := filepath.Base(infile)
 	fileInfo := FileInfo{
 		InFile:      infile,
 		InFileBase:  filepath.Base(infile),
 		Raw:         string(data),
-		MD5Sum:      fmt.Sprintf("%x", md5.Sum(data)),
 		PackageName: packageName,
 		Name:        strings.TrimSuffix(basename, filepath.Ext(basename)),
 	}
 
 	// Parse by type
 	var spec interface{}
+	msgContext := newMsgContext(includes)
 	switch templateType {
 	case "msg":
 		var msgSpec *MsgSpec
@@ -127,6 +116,10 @@ func main() {
 		if err != nil {
 			log.Fatalf("failed to parse %s spec: %s", templateType, err)
 		}
+		msgContext.register(msgSpec)
+		if err = msgContext.complete(msgSpec); err != nil {
+			log.Fatalf("failed to resolve %s: %s", msgSpec.FullName(), err)
+		}
 		spec = msgSpec
 
 	case "srv":
@@ -135,52 +128,95 @@ func main() {
 		if err != nil {
 			log.Fatalf("failed to parse %s spec: %s", templateType, err)
 		}
+		msgContext.registerSrv(srvSpec)
+		if err = msgContext.completeSrv(srvSpec); err != nil {
+			log.Fatalf("failed to resolve %s/%s: %s", srvSpec.PackageName, srvSpec.Name, err)
+		}
 		spec = srvSpec
 
 	default:
 		log.Fatalf("no parser configured: %s", templateType)
 	}
 
-	buf := bytes.NewBuffer([]byte{})
-	err = tmpl.Execute(buf, map[string]interface{}{"FileInfo": fileInfo, "Spec": spec})
+	source, err := render(tmpl, fileInfo, spec)
 	if err != nil {
-		log.Fatalf("failed to generate Go file: %s", err)
+		log.Fatal(err)
 	}
 
-	fset := token.NewFileSet()
-	ast, err := parser.ParseFile(fset, "", buf.Bytes(), parser.ParseComments)
+	if dryRun {
+		fmt.Println(string(source))
+		return
+	}
+
+	err = ioutil.WriteFile(outfile, source, 0644)
 	if err != nil {
-		log.Fatalf("bad Go source code was generated: %s\n%s", err, buf.String())
+		log.Fatalf("failed to write go file: %s", err)
 	}
-	buf.Reset()
-	err = (&printer.Config{Mode: printer.TabIndent | printer.UseSpaces, Tabwidth: 8}).Fprint(buf, fset, ast)
+	log.Printf("Wrote %s from %s", outfile, infile)
+}
+
+// Load the template for a generator type along with the partial it uses.
+func loadTemplate(templateType string) (*template.Template, error) {
+	basename := fmt.Sprintf("%s.tmpl", templateType)
+	data, err := Asset(basename)
+	if err != nil {
+		return nil, fmt.Errorf("unrecognized generator template: %s (%s)", templateType, err)
+	}
+	tmpl := template.New(basename)
+	tmpl = tmpl.Funcs(map[string]interface{}{
+		// HACK(ppg): Allow setting a loop variable a struct so we can use it
+		"setloopvar": func(setter loopVarSetter, value interface{}) interface{} {
+			setter.SetLoopVar(value)
+			return setter
+		},
+	})
+	tmpl, err = tmpl.Parse(string(data))
 	if err != nil {
-		log.Fatalf("generated Go source code could not be reformatted: %s", err)
+		return nil, fmt.Errorf("unable to template %s: %s", templateType, err)
 	}
 
-	if dryRun {
-		fmt.Println(buf.String())
-		return
+	data, err = Asset("msg.partial.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("unrecognized generator template: %s (%s)", templateType, err)
 	}
+	if _, err = tmpl.New("msg.partial.tmpl").Parse(string(data)); err != nil {
+		return nil, fmt.Errorf("unable to template %s: %s", templateType, err)
+	}
+	return tmpl, nil
+}
 
-	err = ioutil.WriteFile(outfile, buf.Bytes(), 0644)
+// Execute a template for a spec and format the resulting Go source.
+func render(tmpl *template.Template, fileInfo FileInfo, spec interface{}) ([]byte, error) {
+	buf := bytes.NewBuffer([]byte{})
+	err := tmpl.Execute(buf, map[string]interface{}{"FileInfo": fileInfo, "Spec": spec})
 	if err != nil {
-		log.Fatalf("failed to write go file: %s", err)
+		return nil, fmt.Errorf("failed to generate Go file: %s", err)
 	}
-	log.Printf("Wrote %s from %s", outfile, infile)
+
+	fset := token.NewFileSet()
+	ast, err := parser.ParseFile(fset, "", buf.Bytes(), parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("bad Go source code was generated: %s\n%s", err, buf.String())
+	}
+	buf.Reset()
+	err = (&printer.Config{Mode: printer.TabIndent | printer.UseSpaces, Tabwidth: 8}).Fprint(buf, fset, ast)
+	if err != nil {
+		return nil, fmt.Errorf("generated Go source code could not be reformatted: %s", err)
+	}
+	return buf.Bytes(), nil
 }
 
 type FileInfo struct {
 	InFile      string
 	InFileBase  string
 	Raw         string
-	MD5Sum      string
 	PackageName string
 	Name        string
 }
 
 type MsgSpec struct {
 	Raw         string
+	Text        string // Raw followed by the definitions of nested types.
 	MD5Sum      string
 	PackageName string
 	Name        string
@@ -194,6 +230,15 @@ type MsgSpec struct {
 	packageMap map[string]struct{}
 }
 
+func (m MsgSpec) FullName() string {
+	return m.PackageName + "/" + m.Name
+}
+
+// TextLiteral returns Text as a Go raw string literal.
+func (m MsgSpec) TextLiteral() string {
+	return "`" + strings.Replace(m.Text, "`", "` + \"`\" + `", -1) + "`"
+}
+
 func (m MsgSpec) Packages() (ret []string) {
 	ret = make([]string, 0, len(m.packageMap))
 	for k := range m.packageMap {
@@ -206,7 +251,7 @@ func (m MsgSpec) Packages() (ret []string) {
 //byte FOO=1
 //byte BAR=2
 //string HOGE=hoge
-var constMatcher = regexp.MustCompile(`^([\w/]+)\s+(\w+)\s*=\s*(\w+)`)
+var constMatcher = regexp.MustCompile(`^([\w/]+)\s+(\w+)\s*=\s*(.*)$`)
 
 //Header h # go:package=github.com/ppg/rosgo/msgs/std_msgs
 //byte b
@@ -224,7 +269,6 @@ var goOptionMatcher = regexp.MustCompile(`go:(\w+)=([^\s]+)`)
 func parseMsgSpec(packageName, name string, data []byte) (*MsgSpec, error) {
 	spec := new(MsgSpec)
 	spec.Raw = string(data)
-	spec.MD5Sum = fmt.Sprintf("%x", md5.Sum(data))
 	spec.PackageName = packageName
 	spec.Name = name
 
@@ -235,32 +279,23 @@ func parseMsgSpec(packageName, name string, data []byte) (*MsgSpec, error) {
 	scanner := bufio.NewScanner(buf)
 	for scanner.Scan() {
 		line := scanner.Text()
-		line = strings.TrimSpace(line)
+		cleanLine := strings.TrimSpace(stripComment(line))
+		if cleanLine == "" {
+			continue
+		}
 
 		// Check constant first
-		items := constMatcher.FindStringSubmatch(line)
-		if items != nil {
-			//log.Println("constant", line)
-			//log.Printf("items(%d): %+v", len(items), items)
-			//for i := 0; i < len(items); i++ {
-			//	log.Printf("items[%d]: %+v", i, items[i])
-			//}
-			//items(4): [string HOGE=hoge string HOGE hoge]
-			//items[0]: string HOGE=hoge
-			//items[1]: string
-			//items[2]: HOGE
-			//items[3]: hoge
-			rosName := items[2]
-			rosType := items[1]
-			rosValue := items[3]
-			constant := newMsgConstant(rosName, rosType, rosValue)
-			//log.Printf("constant: %+v", constant)
+		if strings.Contains(cleanLine, "=") {
+			constant, err := parseConstant(line)
+			if err != nil {
+				return nil, err
+			}
 			spec.Constants = append(spec.Constants, constant)
 			continue
 		}
 
 		// Now check field
-		items = fieldMatcher.FindStringSubmatch(line)
+		items := fieldMatcher.FindStringSubmatch(cleanLine)
 		if items != nil {
 			//log.Println("field", line)
 			//log.Printf("items(%d): %+v", len(items), items)
@@ -283,7 +318,8 @@ func parseMsgSpec(packageName, name string, data []byte) (*MsgSpec, error) {
 			rosType := items[1]
 			isSliceOrArray := len(items[2]) > 0
 			arraySize := items[3]
-			field := newMsgField(rosName, rosType, isSliceOrArray, arraySize)
+			field := newMsgField(packageName, rosName, rosType, isSliceOrArray, arraySize)
+			field.arraySuffix = items[2]
 			if field.BuiltIn {
 				spec.HasBuiltIn = true
 			}
@@ -294,7 +330,9 @@ func parseMsgSpec(packageName, name string, data []byte) (*MsgSpec, error) {
 				}
 			}
 			if field.GoImportName != "" && field.GoImportName != packageName {
-				if pkg, ok := goOptions["package"]; ok {
+				if importPrefix != "" {
+					spec.packageMap[importPrefix+"/"+field.GoImportName] = struct{}{}
+				} else if pkg, ok := goOptions["package"]; ok {
 					spec.packageMap[pkg] = struct{}{}
 				} else if pkg, ok := builtInImports[field.GoImportName]; ok {
 					spec.packageMap[pkg] = struct{}{}
@@ -307,9 +345,7 @@ func parseMsgSpec(packageName, name string, data []byte) (*MsgSpec, error) {
 			continue
 		}
 
-		if len(line) != 0 && !strings.HasPrefix(line, "#") {
-			return nil, fmt.Errorf("unrecognized msg line: %s", line)
-		}
+		return nil, fmt.Errorf("unrecognized msg line: %s", line)
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -319,6 +355,28 @@ func parseMsgSpec(packageName, name string, data []byte) (*MsgSpec, error) {
 	return spec, nil
 }
 
+// Remove a trailing # comment from a line of a .msg file.
+func stripComment(line string) string {
+	if i := strings.Index(line, "#"); i >= 0 {
+		return line[:i]
+	}
+	return line
+}
+
+// Parse a constant line the way genmsg does: the value of a string constant
+// is everything after the '=', including any '#'.
+func parseConstant(line string) (*msgConstant, error) {
+	items := constMatcher.FindStringSubmatch(strings.TrimSpace(stripComment(line)))
+	if items == nil {
+		return nil, fmt.Errorf("unrecognized constant line: %s", line)
+	}
+	rosType, rosName, rosValue := items[1], items[2], items[3]
+	if rosType == "string" {
+		rosValue = line[strings.Index(line, "=")+1:]
+	}
+	return newMsgConstant(rosName, rosType, strings.TrimSpace(rosValue)), nil
+}
+
 type msgField struct {
 	IsArray      bool
 	ArraySize    int
@@ -330,17 +388,21 @@ type msgField struct {
 	GoZeroValue  string
 
 	LoopVar interface{}
+
+	rosName     string // Name as written in the .msg file.
+	arraySuffix string // Array brackets as written, such as [] or [3].
 }
 
 func (m *msgField) SetLoopVar(i interface{}) {
 	m.LoopVar = i
 }
 
-func newMsgField(rosName, rosType string, isArray bool, arraySize string) (field *msgField) {
+func newMsgField(packageName, rosName, rosType string, isArray bool, arraySize string) (field *msgField) {
 	//log.Printf("rosName: %s", rosName)
 	//log.Printf("rosType: %s", rosType)
 	field = new(msgField)
 	field.Name = snakeToCamel(rosName)
+	field.rosName = rosName
 	field.TypeName = rosType
 	field.IsArray = isArray
 	if isArray && len(arraySize) > 0 {
@@ -400,11 +462,14 @@ type msgConstant struct {
 	Name     string
 	TypeName string
 	Value    string
+
+	rosName string
 }
 
 func newMsgConstant(rosName, rosType, value string) (constant *msgConstant) {
 	constant = new(msgConstant)
 	constant.Name = snakeToCamel(rosName)
+	constant.rosName = rosName
 	constant.TypeName = rosType
 	constant.Value = value
 	return
@@ -438,7 +503,6 @@ func (s SrvSpec) Packages() (ret []string) {
 func parseSrvSpec(packageName, name string, data []byte) (*SrvSpec, error) {
 	spec := new(SrvSpec)
 	spec.Raw = string(data)
-	spec.MD5Sum = fmt.Sprintf("%x", md5.Sum(data))
 	spec.PackageName = packageName
 	spec.Name = name
 
@@ -463,11 +527,6 @@ func parseSrvSpec(packageName, name string, data []byte) (*SrvSpec, error) {
 		spec.packageMap[k] = struct{}{}
 	}
 
-	log.Printf("spec.MD5Sum: %s", spec.MD5Sum)
-	log.Printf("spec.RequestSpec.MD5Sum: %s", spec.RequestSpec.MD5Sum)
-	// FIXME(ppg): why does it expect the srv spec to have the MD5 sum of the request (and maybe response) instead of its md5sum?
-	spec.MD5Sum = spec.RequestSpec.MD5Sum
-
 	return spec, nil
 }
 
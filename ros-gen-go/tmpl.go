@@ -70,7 +70,7 @@ func (fi bindataFileInfo) Sys() interface{} {
 	return nil
 }
 
-var _msgPartialTmpl = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\xa4\x54\x51\x4f\xdb\x48\x10\x7e\xb6\x7f\xc5\x5c\x24\x90\x1d\x72\x0e\xba\x13\x2f\xe1\x72\x52\x25\x68\x55\xa9\xd0\x0a\x90\xfa\x80\x10\x6c\xe3\x49\xb2\xc2\x5e\xa3\xdd\x35\x21\x58\xf9\xef\x9d\xd9\x75\x4c\x1c\x92\x40\xdb\x48\x08\xcf\xec\xcc\x37\xdf\xec\x7c\xb3\x76\xfe\x80\x70\x7b\x66\x26\x55\x05\xc9\xb9\xc8\x11\x16\x0b\x30\x56\x97\x23\x0b\x55\x18\x58\x7c\xb2\x6c\x4a\x35\x09\x03\xc5\xc7\x4b\x23\x4f\x8f\x4c\x99\x2f\xcd\x45\x18\x8e\x4b\x35\x82\xc8\x42\x77\x0d\x2d\x86\x2b\x02\x89\xe2\x3a\x94\x51\x35\xda\x52\x2b\xb0\x09\xc3\xef\xce\xe5\x8f\xcd\xb9\xcc\x66\x77\xee\xd9\xc9\xd1\x65\x99\x6f\xce\xf6\xf4\xdf\xa8\x8d\xb3\x33\x34\x46\x4c\x98\x81\x2e\x4c\x52\x5b\x0c\x94\xc3\x60\x08\x0a\x67\xd1\x6a\x46\x18\x54\x55\xbf\x0b\xe7\x5f\xaf\x4e\x07\x70\x5e\xd0\x39\xa6\x60\x0b\x90\x4a\x5a\x29\x32\xf9\x8c\x30\x96\x98\xa5\x06\x84\x01\x3b\xc5\x39\x08\x8d\x20\xb2\x0c\x9e\x51\x17\xf0\x28\xb2\x12\x7b\x30\x9b\xca\xd1\x14\xa4\x81\x14\xc7\xa2\xcc\x2c\xa5\xc3\xa4\x80\x6e\x7f\xb1\x68\x1a\x70\xcc\x1f\x85\x86\x28\x0c\xd6\x86\x37\x84\xfd\xf5\x79\x12\xdf\xe0\x8e\x1d\x17\x62\x46\xf6\x5d\x8f\xec\x0e\xdb\xdf\xc4\xe8\x9e\x1a\xaa\xe3\xfa\x2b\x39\x9d\x26\xc6\xdf\x62\xed\x5a\x84\x71\x18\x3a\xcd\x6c\xd6\x4b\x55\xfd\x0d\x5a\x28\xba\xa3\xe4\xa3\xef\x94\x49\xb7\xb8\x54\x20\xc7\x90\x7c\x36\x1f\xb4\x16\x73\xf2\x5c\x7b\xcf\xc4\x42\xe2\x5c\x97\x7c\x4d\x87\x74\xc0\x59\x2f\x1e\x67\xa3\x4a\xe9\xe3\xa6\xf9\xe2\x90\x4f\xc5\x15\xf1\xa9\xe1\x3d\x03\x7f\xf8\x32\xdc\x1c\xba\xad\xc1\x5e\xa2\xf6\xe3\x88\x66\x20\x8b\xe4\xbb\x96\x16\x75\x0c\x11\x6a\x0d\xf4\x57\xd0\xf7\xb6\x5e\x00\xd8\xdd\xee\x20\x0c\xfa\x7d\x70\x20\x60\x98\x6a\x26\xad\xcd\x90\x59\x48\xa1\x28\x83\x61\x87\xf0\x43\x2a\xa1\xe7\xbe\x58\x34\xeb\x2d\xed\x2f\x2e\xf8\xd4\xc5\xf6\xa0\x94\xca\xfe\xfb\x4f\x94\xa1\x8a\xf2\x64\x95\x73\x4c\xe2\xa2\xaa\x0c\xf5\x17\x09\x4f\x66\x6e\xa8\xb5\x1a\xc6\xb9\x4d\x4e\x99\xf7\x38\xea\x8c\x8a\x32\x4b\x41\x15\x16\x66\x8e\x91\x70\x24\x09\x70\x62\xa7\x03\xd8\x33\x9d\x1e\x83\xc4\x3c\x4a\x80\x71\xa1\xe1\x96\x1c\x19\x3a\x3d\xfb\x6e\x5b\x85\xa9\x0c\xd0\xaf\x2e\x3d\x74\x6b\xd0\x5c\x5f\xbd\x0f\xee\x76\xb8\x25\xa7\x97\x95\x69\x50\xad\x7d\xc6\x8e\x8f\xdb\xc4\xc1\xfd\x6a\xf2\x74\xe2\x1c\x0b\x4f\xc9\xcd\x2f\x33\xe8\x2f\xfb\xf7\xeb\xb6\xaf\x6f\x03\x81\x56\xf9\xa6\xb2\x57\x4e\xd0\x28\x6c\xb9\x71\xdb\xc5\x74\x82\xa6\x91\x93\x66\x39\x5d\xa0\x48\xdf\x2b\x27\x56\xce\x0a\x9a\x8f\x5a\x53\x17\x38\xc2\x14\xc8\xc0\x9b\x14\x16\xf0\x43\xe0\xfc\x5e\x3e\x8d\x52\x1a\xd1\x71\x66\xa4\xb7\x68\x6e\x9f\x53\x77\xdc\x50\x4b\x5d\x42\xb1\xb4\x34\x33\xf1\xca\x72\x75\x59\x47\x2b\x6d\xac\xe9\x6c\xd9\xd4\xeb\x15\x77\x4c\x1d\xc2\xff\xb0\xbe\xee\xdb\x04\xbe\xbd\x2c\x3d\xb5\x05\x64\x42\x4f\x70\x00\xf8\xf4\x80\x23\x8b\xe9\x70\x1d\xb6\x47\x6f\xa9\x1d\xee\xa5\xc4\xcf\xf5\xdd\x10\x5c\x8a\x2e\x68\xeb\x7f\x08\xb9\xb8\xc7\xe8\xfa\xe6\xd5\x5b\xd3\xa3\x87\xd9\x46\x0e\x24\x6e\xbd\x3b\x01\xf3\x92\xbc\x50\x87\xc7\xf4\xff\xbf\x97\x38\x32\x0f\x0e\x36\xad\xd4\x8a\x88\x5a\xe2\xd6\xef\x10\xf7\xb5\xbc\xf9\x95\x05\x23\x21\x07\x3b\x96\xec\x4f\x98\xbc\x63\xcd\x82\xb7\xb6\xec\x67\x00\x00\x00\xff\xff\xb8\xb1\x60\x8c\x91\x08\x00\x00")
+var _msgPartialTmpl = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\xa4\x94\xd1\x6f\xdb\x36\x10\xc6\x9f\xc5\xbf\xe2\x9b\x81\x06\x52\xaa\xca\xc5\x86\xbe\x24\xf3\x80\x01\xcd\x86\x01\x4d\x36\x2c\x01\xf6\x10\x04\x05\x67\x9d\x15\xa2\x14\x15\x50\x54\x1d\x57\xe0\xff\x3e\x1c\x25\x39\x92\x2b\x7b\x05\xfa\x66\xd2\x77\xdf\x7d\x77\xfc\x9d\xdc\xee\x89\xf0\xf1\xba\x2e\xda\x16\xd9\x8d\x2c\x09\xde\xa3\x76\xb6\x59\x3b\xb4\x22\x72\xf4\xec\xf8\xa8\x4c\x21\x22\xc3\x7f\x0f\x87\x32\x7f\x57\x37\xe5\x70\xf4\x42\x6c\x1a\xb3\x46\xec\x70\x7e\xa0\x96\xe0\x8e\x9e\x5d\x9c\xf4\xa1\xac\x6a\xc9\x35\xd6\xc0\x65\x2c\x7f\x3a\x97\x45\xe6\x73\xd9\xcd\xe9\xdc\xeb\xf7\xef\x6e\x9b\x72\x3e\xbb\xb3\xff\x3f\xb5\x69\x7b\x4d\x75\x2d\x0b\x8a\x13\xd8\xaa\xce\xfa\x13\xb7\x50\xe2\x62\x05\x43\xdb\x78\x9c\x21\xa2\xb6\x5d\x9e\xe3\xe6\xcf\xbb\xab\x0b\xdc\x54\x30\x44\x39\x5c\x05\x65\x94\x53\x52\xab\x2f\x84\x8d\x22\x9d\xd7\x90\x35\xdc\x23\xed\x20\x2d\x41\x6a\x8d\x2f\x64\x2b\x7c\x96\xba\xa1\x14\xdb\x47\xb5\x7e\x84\xaa\x91\xd3\x46\x36\xda\x41\x19\x14\x15\xce\x97\xde\xef\x1b\x08\xce\x3f\x4b\x8b\x58\x44\x53\xdb\x58\xe1\xec\xa0\x13\xf6\x1b\xf1\x99\x1f\xe2\x83\x72\x64\xa5\x86\xf7\xa9\x88\xa2\x05\x5f\xff\x25\xd7\x9f\x64\x41\x7d\xf4\x72\x94\xb9\xd8\xc7\x74\xb3\xec\xaf\xbc\x48\x84\x08\xe4\xcc\x53\xd3\xb6\x6f\x60\xa5\x29\x08\xd9\x6f\x5d\xbf\x6c\x7d\x1c\xdb\xb6\x50\x1b\x64\x7f\xd4\xbf\x5a\x2b\x77\xf0\xfe\xbe\xbb\x29\x1c\xb2\x70\x75\xcb\xc3\x7a\x0b\xef\xdb\x76\x7c\x13\xce\x64\x72\x78\xff\xb0\xff\xc5\x21\xbf\x57\x77\xbb\xa7\xa1\x05\xae\xf5\xa6\xff\xf3\xe5\x89\x4b\x9c\x8f\x2c\x24\xb8\x25\xdb\x3d\x4a\xbc\x85\xaa\xb2\x7f\x2c\x4f\x26\x41\x4c\xd6\x82\xac\xad\x6c\x72\xb4\x17\x80\xaf\xa7\x1d\x88\x68\xb9\x44\x10\x41\xcd\x56\xb5\x72\x4e\x13\xbb\x50\xd2\x08\xb0\x24\x56\xf8\x57\x19\x69\x77\x5d\xb1\x78\x9b\x0e\xe7\x0f\x21\xf8\x2a\xc4\xa6\x68\x94\x71\x3f\xfd\x18\x6b\x32\x71\x99\x8d\x3d\x27\x89\x88\xd4\x26\x48\xfd\xb0\x82\x51\x9a\x1d\x0e\x4c\x6c\x4a\x97\x5d\xb1\xef\x4d\xbc\x58\x57\x8d\xce\x61\x2a\x87\x2d\x57\x82\xe4\x99\x42\x93\x29\xdc\xe3\x05\x5e\xd5\x8b\x94\x45\x12\x11\x79\x01\x6c\x2a\x8b\x8f\x29\x48\x53\xa0\xba\xeb\x76\x52\x18\xad\x00\x80\xbe\xf4\x2a\x2c\xc3\x7e\x7c\xfd\x56\x84\x97\xe6\x96\x02\x2f\xa3\xd7\x58\xa4\x38\x63\xed\xe4\x72\x6a\x9c\x15\x81\xde\x3c\x59\x1b\x4a\xf8\xce\x12\x8f\x97\x74\x1d\x1e\xf3\x7b\xea\x4e\xc7\x37\x63\x60\x52\x7e\x5f\xb9\x23\x27\xda\x13\x36\xec\xdd\x71\x98\xde\x53\x3d\xf8\x8a\x2d\xe3\xf4\x37\xc9\xfc\x5b\x71\x62\x72\x46\x6a\x22\x9a\xa1\x0b\xc1\xf0\x72\x09\x16\x9e\x23\x2c\xe2\xcf\x41\xb8\xef\xf0\xd9\x93\xb2\x87\x8e\x33\x63\x7b\x84\xb9\x33\x4e\x3d\x31\xa1\x09\x5d\xd2\x30\x5a\x96\x9d\x74\x64\x71\x72\xe0\x68\xd4\xc6\x01\x67\x43\x53\x5f\xaf\x78\x70\x1a\x14\x7e\xc1\xe1\xba\x1f\x03\xfc\x78\x59\xb8\xaa\x82\x96\xb6\xa0\x0b\xd0\xf3\x13\xad\x1d\xe5\xab\x43\xd9\x14\x45\xe5\x56\xaf\xf2\x45\x1a\x46\xf9\x62\x70\x80\x2e\x9a\xf2\xbf\x42\x29\x3f\x51\x7c\xff\xf0\xd5\xb7\x26\x85\x32\x2e\x0e\x22\xc9\xe4\xbb\x13\xf1\x38\x14\x2f\xd4\xdb\x4b\x28\xfc\xfc\x12\x77\x09\xf5\xfa\x75\x3f\xdd\x09\xda\x23\x88\x26\x70\xdb\x6f\x80\xfb\x5e\x3d\xcc\xbd\xde\xb1\x05\xf3\x42\x44\x27\x96\xec\x7b\x9c\xcc\xd9\x98\x98\xf0\xd3\x39\xcd\x6d\xd9\x7f\x03\x00\x51\xf4\x75\x6d\x97\x08\x00\x00")
 
 func msgPartialTmplBytes() ([]byte, error) {
 	return bindataRead(
@@ -85,7 +85,7 @@ func msgPartialTmpl() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "msg.partial.tmpl", size: 2193, mode: os.FileMode(420), modTime: time.Unix(1470892363, 0)}
+	info := bindataFileInfo{name: "msg.partial.tmpl", size: 2199, mode: os.FileMode(420), modTime: time.Unix(1792319541, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
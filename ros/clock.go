@@ -0,0 +1,154 @@
+package ros
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// Topic simulated time is published on while /use_sim_time is set.
+const clockTopic = "/clock"
+
+var errSleepCanceled = errors.New("sleep canceled")
+
+// clockMessageType is rosgraph_msgs/Clock.  The generated message can't be
+// used here because the msgs packages import this one.
+type clockMessageType struct{}
+
+func (clockMessageType) Text() string {
+	return clockMessageText
+}
+
+func (clockMessageType) MD5Sum() string {
+	return "a9c97c1d230cfc112e270351a944ee47"
+}
+
+func (clockMessageType) Name() string {
+	return "rosgraph_msgs/Clock"
+}
+
+func (clockMessageType) NewMessage() Message {
+	return new(clockMessage)
+}
+
+type clockMessage struct {
+	Clock Time
+}
+
+func (m *clockMessage) Serialize(w io.Writer) error {
+	return SerializeMessageField(w, "time", &m.Clock)
+}
+
+func (m *clockMessage) Deserialize(r io.Reader) error {
+	return DeserializeMessageField(r, "time", &m.Clock)
+}
+
+// clock is a node's source of time.  It follows /clock once it is simulated
+// and reads the wall clock otherwise.  A nil *clock is the wall clock.
+type clock struct {
+	done <-chan struct{} // Closed when the node shuts down.
+
+	mutex     sync.Mutex
+	simulated bool
+	time      Time
+	changed   chan struct{} // Closed and replaced each time time is set.
+}
+
+func newClock(done <-chan struct{}) *clock {
+	return &clock{done: done, changed: make(chan struct{})}
+}
+
+// Switch to simulated time, which stays zero until set is called.
+func (c *clock) simulate() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.simulated = true
+}
+
+func (c *clock) isSimulated() bool {
+	if c == nil {
+		return false
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.simulated
+}
+
+// Set simulated time, waking everyone sleeping on it.
+func (c *clock) set(t Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.time = t
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+func (c *clock) now() Time {
+	if c == nil {
+		return Now()
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if !c.simulated {
+		return Now()
+	}
+	return c.time
+}
+
+// Sleep for d.  Simulated time may pause, in which case so does sleep, or
+// jump, in which case sleep wakes and returns ErrTimeJumpedBackward if it
+// moved back past the start of the sleep.
+func (c *clock) sleep(d Duration) error {
+	if c == nil {
+		return d.Sleep()
+	}
+	start := c.now()
+	return c.sleepUntil(start, start.Add(d), nil)
+}
+
+// Sleep until end, like sleep does for the time since start.  Closing cancel
+// wakes the sleep early with errSleepCanceled.
+func (c *clock) sleepUntil(start Time, end Time, cancel <-chan struct{}) error {
+	if !c.isSimulated() {
+		now := Now()
+		if now.Cmp(end) >= 0 {
+			return nil
+		}
+		remaining := end.Diff(now)
+		timer := time.NewTimer(time.Duration(remaining.ToNSec()))
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			return nil
+		case <-cancel:
+			return errSleepCanceled
+		case <-c.done:
+			return ErrShutdown
+		}
+	}
+	for {
+		c.mutex.Lock()
+		now, changed := c.time, c.changed
+		c.mutex.Unlock()
+		if now.Cmp(start) < 0 {
+			return ErrTimeJumpedBackward
+		}
+		if now.Cmp(end) >= 0 {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-cancel:
+			return errSleepCanceled
+		case <-c.done:
+			return ErrShutdown
+		}
+	}
+}
+
+const clockMessageText = `# roslib/Clock is used for publishing simulated time in ROS. 
+# This message simply communicates the current time.
+# For more information, see http://www.ros.org/wiki/Clock
+time clock
+`
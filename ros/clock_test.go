@@ -0,0 +1,113 @@
+package ros
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSimulatedTime(t *testing.T) {
+	m, args := startTestMaster(t)
+	defer m.Shutdown()
+	clockNode := newTestNode(t, "clock", args)
+	defer clockNode.Shutdown()
+	if err := clockNode.SetParam("/use_sim_time", true); err != nil {
+		t.Fatal(err)
+	}
+	pub, err := clockNode.NewPublisher("/clock", clockMessageType{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := newTestNode(t, "sim", args)
+	defer node.Shutdown()
+
+	if now := node.Now(); !now.IsZero() {
+		t.Errorf("expected zero time before /clock; got %v", now)
+	}
+	setClock := func(sec uint32, nsec uint32) {
+		pub.Publish(&clockMessage{NewTime(sec, nsec)})
+	}
+	// Wait for the subscription to connect.
+	deadline := time.Now().Add(5 * time.Second)
+	for node.Now() != NewTime(100, 0) {
+		if time.Now().After(deadline) {
+			t.Fatal("simulated time not received")
+		}
+		setClock(100, 0)
+		time.Sleep(10 * time.Millisecond)
+	}
+	sleep := func(d Duration) chan error {
+		done := make(chan error, 1)
+		go func() { done <- node.Sleep(d) }()
+		// Give the sleep time to start before the clock moves.
+		time.Sleep(50 * time.Millisecond)
+		return done
+	}
+	expectAwake := func(done chan error, expected error) {
+		t.Helper()
+		select {
+		case err := <-done:
+			if err != expected {
+				t.Errorf("expected %v; got %v", expected, err)
+			}
+		case <-time.After(time.Second):
+			t.Error("sleep did not return")
+		}
+	}
+	expectAsleep := func(done chan error) {
+		t.Helper()
+		select {
+		case err := <-done:
+			t.Errorf("sleep returned %v too early", err)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	done := sleep(NewDuration(5, 0))
+	setClock(102, 0)
+	expectAsleep(done)
+	setClock(105, 0)
+	expectAwake(done, nil)
+	if now := node.Now(); now != NewTime(105, 0) {
+		t.Errorf("expected time 105; got %v", now)
+	}
+
+	done = sleep(NewDuration(5, 0))
+	setClock(50, 0)
+	expectAwake(done, ErrTimeJumpedBackward)
+
+	rate := node.NewRate(1)
+	done = make(chan error, 1)
+	go func() { done <- rate.Sleep() }()
+	time.Sleep(50 * time.Millisecond)
+	setClock(50, 500000000)
+	expectAsleep(done)
+	setClock(51, 0)
+	expectAwake(done, nil)
+	if cycle := rate.CycleTime(); cycle != NewDuration(1, 0) {
+		t.Errorf("expected a cycle of 1s; got %v", cycle)
+	}
+
+	done = sleep(NewDuration(5, 0))
+	node.Shutdown()
+	expectAwake(done, ErrShutdown)
+}
+
+func TestWallTime(t *testing.T) {
+	m, args := startTestMaster(t)
+	defer m.Shutdown()
+	node := newTestNode(t, "wall", args)
+	defer node.Shutdown()
+
+	before := Now()
+	now := node.Now()
+	if now.Cmp(before) < 0 {
+		t.Errorf("expected wall time after %v; got %v", before, now)
+	}
+	start := time.Now()
+	if err := node.Sleep(NewDuration(0, 20000000)); err != nil {
+		t.Error(err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("expected to sleep 20ms; slept %s", elapsed)
+	}
+}
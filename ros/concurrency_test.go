@@ -0,0 +1,266 @@
+package ros
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Create, use and shut down publishers and subscribers from many goroutines
+// while the Slave API is queried, which the race detector checks.
+func TestConcurrentPublishSubscribe(t *testing.T) {
+	m, args := startTestMaster(t)
+	defer m.Shutdown()
+	talker := newTestNode(t, "talker", args)
+	defer talker.Shutdown()
+	listener := newTestNode(t, "listener", args)
+	defer listener.Shutdown()
+	go listener.SpinMultiThreaded(4)
+
+	stop := make(chan struct{})
+	var queries sync.WaitGroup
+	queries.Add(1)
+	go func() {
+		defer queries.Done()
+		for {
+			for _, node := range []*defaultNode{talker, listener} {
+				for _, method := range []string{"getBusInfo", "getBusStats", "getPublications", "getSubscriptions"} {
+					if _, err := callRosApi(node.xmlrpcUri, method, "/test"); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			}
+			select {
+			case <-stop:
+				return
+			default:
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Pairs of goroutines share a topic.
+			topic := fmt.Sprintf("chatter%d", i/2)
+			for j := 0; j < 5; j++ {
+				sub, err := listener.NewSubscriber(topic, testMessageType{}, func(msg *testMessage) {})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				pub, err := talker.NewPublisher(topic, testMessageType{})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				for k := 0; k < 10; k++ {
+					pub.Publish(&testMessage{"hello"})
+				}
+				pub.GetNumSubscribers()
+				sub.GetNumPublishers()
+				pub.GetConnections()
+				sub.GetConnections()
+				if j%2 == 1 {
+					pub.Shutdown()
+					sub.Shutdown()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(stop)
+	queries.Wait()
+}
+
+// A topic which keeps being subscribed and shut down still delivers to the
+// subscription which remains.
+func TestSubscribeAfterShutdown(t *testing.T) {
+	m, args := startTestMaster(t)
+	defer m.Shutdown()
+	talker := newTestNode(t, "talker", args)
+	defer talker.Shutdown()
+	listener := newTestNode(t, "listener", args)
+	defer listener.Shutdown()
+
+	for i := 0; i < 3; i++ {
+		sub, err := listener.NewSubscriber("chatter", testMessageType{}, func(msg *testMessage) {})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sub.Shutdown()
+		sub.Shutdown()
+	}
+	received := make(chan string, 10)
+	if _, err := listener.NewSubscriber("chatter", testMessageType{}, func(msg *testMessage) {
+		received <- msg.Data
+	}); err != nil {
+		t.Fatal(err)
+	}
+	go listener.Spin()
+	pub, err := talker.NewPublisher("chatter", testMessageType{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.After(5 * time.Second)
+	for waiting := true; waiting; {
+		pub.Publish(&testMessage{"hello"})
+		select {
+		case <-received:
+			waiting = false
+		case <-time.After(50 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("no message received")
+		}
+	}
+}
+
+// Shutting a node down while other goroutines use it neither hangs nor
+// races.
+func TestConcurrentNodeShutdown(t *testing.T) {
+	m, args := startTestMaster(t)
+	defer m.Shutdown()
+	talker := newTestNode(t, "talker", args)
+	listener := newTestNode(t, "listener", args)
+	defer listener.Shutdown()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			topic := fmt.Sprintf("chatter%d", i)
+			if _, err := listener.NewSubscriber(topic, testMessageType{}, func(msg *testMessage) {}); err != nil {
+				t.Error(err)
+				return
+			}
+			for talker.OK() {
+				// Fails once the node has shut down.
+				pub, err := talker.NewPublisher(topic, testMessageType{})
+				if err != nil {
+					return
+				}
+				pub.Publish(&testMessage{"hello"})
+			}
+		}(i)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		talker.SpinContext(ctx)
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		var shutdowns sync.WaitGroup
+		for i := 0; i < 3; i++ {
+			shutdowns.Add(1)
+			go func() {
+				defer shutdowns.Done()
+				talker.Shutdown()
+			}()
+		}
+		shutdowns.Wait()
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("shutdown hung")
+	}
+}
+
+// Hold back the master's answers to calls of method for /chatter until
+// release is closed, and fail those naming failTopic.
+func startSlowMaster(t *testing.T, masterUri string, method string, release chan struct{}, failTopic string) *httptest.Server {
+	target, err := url.Parse(masterUri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		if bytes.Contains(body, []byte(failTopic)) {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if bytes.Contains(body, []byte("<methodName>"+method+"<")) && bytes.Contains(body, []byte("/chatter")) {
+			<-release
+		}
+		proxy.ServeHTTP(w, r)
+	}))
+}
+
+// The Slave API keeps answering while the master is slow to register a
+// topic, and topics which fail to register are forgotten.
+func TestSlaveAPIDuringRegistration(t *testing.T) {
+	m, _ := startTestMaster(t)
+	defer m.Shutdown()
+	for _, method := range []string{"registerSubscriber", "registerPublisher"} {
+		release := make(chan struct{})
+		slow := startSlowMaster(t, m.Uri(), method, release, "/broken")
+		node := newTestNode(t, "node", []string{"__master:=" + slow.URL, "__ip:=127.0.0.1"})
+
+		registered := make(chan error, 1)
+		go func() {
+			var err error
+			if method == "registerSubscriber" {
+				_, err = node.NewSubscriber("chatter", testMessageType{}, func(*testMessage) {})
+			} else {
+				_, err = node.NewPublisher("chatter", testMessageType{})
+			}
+			registered <- err
+		}()
+		time.Sleep(50 * time.Millisecond)
+		answered := make(chan error, 1)
+		go func() {
+			_, err := callRosApi(node.xmlrpcUri, "getBusInfo", "/test")
+			answered <- err
+		}()
+		select {
+		case err := <-answered:
+			if err != nil {
+				t.Errorf("%s: getBusInfo failed: %s", method, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Errorf("%s: getBusInfo blocked while registering", method)
+		}
+		close(release)
+		if err := <-registered; err != nil {
+			t.Errorf("%s: %s", method, err)
+		}
+
+		if method == "registerSubscriber" {
+			if _, err := node.NewSubscriber("broken", testMessageType{}, func(*testMessage) {}); err == nil {
+				t.Error("expected subscribing to fail")
+			}
+		} else if _, err := node.NewPublisher("broken", testMessageType{}); err == nil {
+			t.Error("expected advertising to fail")
+		}
+		node.mutex.Lock()
+		_, subscribed := node.subscribers["/broken"]
+		_, published := node.publishers["/broken"]
+		node.mutex.Unlock()
+		if subscribed || published {
+			t.Errorf("%s: failed registration left /broken behind", method)
+		}
+		node.Shutdown()
+		slow.Close()
+	}
+}
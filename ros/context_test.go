@@ -0,0 +1,111 @@
+package ros
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSpinContext(t *testing.T) {
+	m, args := startTestMaster(t)
+	defer m.Shutdown()
+	node := newTestNode(t, "node", args)
+	defer node.Shutdown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := node.SpinContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected context.DeadlineExceeded; got %v", err)
+	}
+}
+
+// A master which never answers shows that deadlines reach the HTTP request.
+func TestParamContextDeadline(t *testing.T) {
+	release := make(chan struct{})
+	hung := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer hung.Close()
+	defer close(release)
+	node := &defaultNode{masterUri: hung.URL, qualifiedName: "/node", nameResolver: newNameResolver("/node", nil)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := node.GetParamContext(ctx, "/param")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded; got %v", err)
+	}
+	if errors.Is(err, ErrMasterUnreachable) {
+		t.Error("a canceled call shouldn't report the master as unreachable")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("call returned after %v", elapsed)
+	}
+}
+
+func TestServiceCallContextCanceled(t *testing.T) {
+	m, args := startTestMaster(t)
+	defer m.Shutdown()
+	node := newTestNode(t, "node", args)
+	defer node.Shutdown()
+
+	client, err := node.NewServiceClient("/add_two_ints", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := client.CallContext(ctx, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled; got %v", err)
+	}
+}
+
+func TestWaitForMessage(t *testing.T) {
+	m, args := startTestMaster(t)
+	defer m.Shutdown()
+	talker := newTestNode(t, "talker", args)
+	defer talker.Shutdown()
+	listener := newTestNode(t, "listener", args)
+	defer listener.Shutdown()
+
+	pub, err := talker.NewPublisher("chatter", testMessageType{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			pub.Publish(&testMessage{"hello"})
+			select {
+			case <-time.After(10 * time.Millisecond):
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	// Nothing spins the listener.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	msg, err := listener.WaitForMessage(ctx, "chatter", testMessageType{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data := msg.(*testMessage).Data; data != "hello" {
+		t.Errorf("expected hello; got %s", data)
+	}
+	if _, ok := listener.subscribers["/chatter"]; ok {
+		t.Error("expected the subscription for the wait to be removed")
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := listener.WaitForMessage(ctx, "silence", testMessageType{}); err != context.DeadlineExceeded {
+		t.Errorf("expected context.DeadlineExceeded; got %v", err)
+	}
+}
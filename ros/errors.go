@@ -0,0 +1,46 @@
+package ros
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrMD5Mismatch is reported when the two ends of a topic or service
+	// connection disagree on the message type or its MD5 sum.
+	ErrMD5Mismatch = errors.New("message type or MD5 sum mismatch")
+	// ErrMasterUnreachable is returned when the ROS master can't be
+	// contacted.
+	ErrMasterUnreachable = errors.New("ROS master unreachable")
+	// ErrServiceNotFound is returned when the master doesn't know the
+	// requested service.
+	ErrServiceNotFound = errors.New("service not found")
+	// ErrShutdown is returned by calls which were interrupted by the node
+	// shutting down.
+	ErrShutdown = errors.New("node shut down")
+	// ErrTimeJumpedBackward is returned by sleeps which were cut short by
+	// simulated time moving backward, such as when a bag restarts.
+	ErrTimeJumpedBackward = errors.New("time jumped backward")
+)
+
+// Error returned when a ROS API call completes with a failure status code.
+type apiStatusError struct {
+	code    int32
+	message string
+}
+
+func (e *apiStatusError) Error() string {
+	return fmt.Sprintf("ROS Master API call failed with code %d: %s", e.code, e.message)
+}
+
+// ParamError is returned when a parameter can't be converted to or from the
+// Go value given for it.  Path locates the offending value within the
+// parameter, such as /robot/wheels[1]/radius.
+type ParamError struct {
+	Path    string
+	Message string
+}
+
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("parameter %s: %s", e.Path, e.Message)
+}
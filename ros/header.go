@@ -51,8 +51,14 @@ func readConnectionHeader(r io.Reader) ([]header, error) {
 		if err != nil {
 			return nil, err
 		}
+		if int(size) > bufReader.Len() {
+			return nil, fmt.Errorf("Header field overruns header")
+		}
 		line := bufReader.Next(int(size))
 		sep := bytes.IndexByte(line, '=')
+		if sep < 0 {
+			return nil, fmt.Errorf("Header field has no '='")
+		}
 		key := string(line[0:sep])
 		value := string(line[sep+1:])
 		headers = append(headers, header{key, value})
@@ -90,3 +96,18 @@ func writeConnectionHeader(headers []header, w io.Writer) error {
 	}
 	return nil
 }
+
+// Encode headers without the leading total size, as UDPROS passes them
+// through XML-RPC.
+func encodeHeaderFields(headers []header) []byte {
+	var buf bytes.Buffer
+	writeConnectionHeader(headers, &buf)
+	return buf.Bytes()[4:]
+}
+
+func decodeHeaderFields(fields []byte) ([]header, error) {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, uint32(len(fields)))
+	buf.Write(fields)
+	return readConnectionHeader(&buf)
+}
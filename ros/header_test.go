@@ -133,3 +133,23 @@ func TestWriteConnectionHeader(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestHeaderFields(t *testing.T) {
+	headers := []header{{"topic", "/chatter"}, {"md5sum", "*"}}
+	decoded, err := decodeHeaderFields(encodeHeaderFields(headers))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 2 || decoded[0] != headers[0] || decoded[1] != headers[1] {
+		t.Errorf("expected %v; got %v", headers, decoded)
+	}
+
+	for _, fields := range [][]byte{
+		[]byte("junk"),
+		{0x03, 0x00, 0x00, 0x00, 'a', 'b', 'c'},
+	} {
+		if _, err := decodeHeaderFields(fields); err == nil {
+			t.Errorf("expected an error decoding %q", fields)
+		}
+	}
+}
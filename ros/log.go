@@ -2,8 +2,10 @@ package ros
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"sync/atomic"
 )
 
 type LogLevel int
@@ -32,85 +34,112 @@ type Logger interface {
 }
 
 type defaultLogger struct {
-	severity LogLevel
+	severity int32 // Accessed atomically.
+	output   *log.Logger
 }
 
 func NewDefaultLogger() *defaultLogger {
 	logger := new(defaultLogger)
-	logger.severity = LogLevelInfo
+	logger.severity = int32(LogLevelInfo)
 	return logger
 }
 
 func (logger *defaultLogger) Severity() LogLevel {
-	return logger.severity
+	return LogLevel(atomic.LoadInt32(&logger.severity))
 }
 
 func (logger *defaultLogger) SetSeverity(severity LogLevel) {
-	logger.severity = severity
+	atomic.StoreInt32(&logger.severity, int32(severity))
+}
+
+// Whether messages at level are logged.
+func (logger *defaultLogger) enabled(level LogLevel) bool {
+	return logger.Severity() <= level
+}
+
+// setOutput redirects log output to w instead of the standard logger.
+func (logger *defaultLogger) setOutput(w io.Writer) {
+	logger.output = log.New(w, "", log.LstdFlags)
+}
+
+func (logger *defaultLogger) println(msg string) {
+	if logger.output != nil {
+		logger.output.Println(msg)
+	} else {
+		log.Println(msg)
+	}
+}
+
+func (logger *defaultLogger) printf(format string, v ...interface{}) {
+	if logger.output != nil {
+		logger.output.Printf(format, v...)
+	} else {
+		log.Printf(format, v...)
+	}
 }
 
 func (logger *defaultLogger) Debug(v ...interface{}) {
-	if int(logger.severity) <= int(LogLevelDebug) {
+	if logger.enabled(LogLevelDebug) {
 		msg := fmt.Sprintf("[DEBUG] %s", fmt.Sprint(v...))
-		log.Println(msg)
+		logger.println(msg)
 	}
 }
 
 func (logger *defaultLogger) Debugf(format string, v ...interface{}) {
-	if int(logger.severity) <= int(LogLevelDebug) {
-		log.Printf("[DEBUG] "+format, v...)
+	if logger.enabled(LogLevelDebug) {
+		logger.printf("[DEBUG] "+format, v...)
 	}
 }
 
 func (logger *defaultLogger) Info(v ...interface{}) {
-	if int(logger.severity) <= int(LogLevelInfo) {
+	if logger.enabled(LogLevelInfo) {
 		msg := fmt.Sprintf("[INFO] %s", fmt.Sprint(v...))
-		log.Println(msg)
+		logger.println(msg)
 	}
 }
 
 func (logger *defaultLogger) Infof(format string, v ...interface{}) {
-	if int(logger.severity) <= int(LogLevelInfo) {
-		log.Printf("[INFO] "+format, v...)
+	if logger.enabled(LogLevelInfo) {
+		logger.printf("[INFO] "+format, v...)
 	}
 }
 
 func (logger *defaultLogger) Warn(v ...interface{}) {
-	if int(logger.severity) <= int(LogLevelWarn) {
+	if logger.enabled(LogLevelWarn) {
 		msg := fmt.Sprintf("[WARN] %s", fmt.Sprint(v...))
-		log.Println(msg)
+		logger.println(msg)
 	}
 }
 
 func (logger *defaultLogger) Warnf(format string, v ...interface{}) {
-	if int(logger.severity) <= int(LogLevelWarn) {
-		log.Printf("[WARN] "+format, v...)
+	if logger.enabled(LogLevelWarn) {
+		logger.printf("[WARN] "+format, v...)
 	}
 }
 
 func (logger *defaultLogger) Error(v ...interface{}) {
-	if int(logger.severity) <= int(LogLevelError) {
+	if logger.enabled(LogLevelError) {
 		msg := fmt.Sprintf("[ERROR] %s", fmt.Sprint(v...))
-		log.Println(msg)
+		logger.println(msg)
 	}
 }
 
 func (logger *defaultLogger) Errorf(format string, v ...interface{}) {
-	if int(logger.severity) <= int(LogLevelError) {
-		log.Printf("[ERROR]"+format, v...)
+	if logger.enabled(LogLevelError) {
+		logger.printf("[ERROR]"+format, v...)
 	}
 }
 
 func (logger *defaultLogger) Fatal(v ...interface{}) {
-	if int(logger.severity) <= int(LogLevelFatal) {
+	if logger.enabled(LogLevelFatal) {
 		msg := fmt.Sprintf("[FATAL] %s", fmt.Sprint(v...))
-		log.Println(msg)
+		logger.println(msg)
 	}
 }
 
 func (logger *defaultLogger) Fatalf(format string, v ...interface{}) {
-	if int(logger.severity) <= int(LogLevelFatal) {
-		log.Printf("[FATAL] "+format, v...)
+	if logger.enabled(LogLevelFatal) {
+		logger.printf("[FATAL] "+format, v...)
 		os.Exit(1)
 	}
 }
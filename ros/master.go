@@ -1,17 +1,45 @@
 package ros
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/ppg/rosgo/xmlrpc"
 )
 
 func callRosApi(calleeUri string, method string, args ...interface{}) (interface{}, error) {
-	result, err := xmlrpc.Call(calleeUri, method, args...)
+	return callRosApiContext(context.Background(), calleeUri, method, args...)
+}
+
+func callRosApiContext(ctx context.Context, calleeUri string, method string, args ...interface{}) (interface{}, error) {
+	result, err := xmlrpc.CallContext(ctx, calleeUri, method, args...)
 	if err != nil {
 		return nil, err
 	}
+	return parseRosApiResult(result)
+}
+
+// Call a Master API method.  Failing to reach the master is reported as
+// ErrMasterUnreachable.
+func callMasterApi(masterUri string, method string, args ...interface{}) (interface{}, error) {
+	return callMasterApiContext(context.Background(), masterUri, method, args...)
+}
+
+// Call a Master API method like callMasterApi, giving up when ctx is done,
+// in which case the error wraps ctx's rather than ErrMasterUnreachable.
+func callMasterApiContext(ctx context.Context, masterUri string, method string, args ...interface{}) (interface{}, error) {
+	result, err := xmlrpc.CallContext(ctx, masterUri, method, args...)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %s", ErrMasterUnreachable, err)
+	}
+	return parseRosApiResult(result)
+}
 
+// Check the status code of a ROS API result triplet and extract its value.
+func parseRosApiResult(result interface{}) (interface{}, error) {
 	var ok bool
 	var xs []interface{}
 	var code int32
@@ -33,8 +61,7 @@ func callRosApi(calleeUri string, method string, args ...interface{}) (interface
 	value = xs[2]
 
 	if code != ApiStatusSuccess {
-		err := fmt.Errorf("ROS Master API call failed with code %d: %s", code, message)
-		return nil, err
+		return nil, &apiStatusError{code, message}
 	}
 	return value, nil
 }
@@ -0,0 +1,21 @@
+package ros
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestCallMasterApiUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	masterUri := "http://" + listener.Addr().String()
+	listener.Close()
+
+	_, err = callMasterApi(masterUri, "getUri", "/test_node")
+	if !errors.Is(err, ErrMasterUnreachable) {
+		t.Errorf("expected ErrMasterUnreachable; got %v", err)
+	}
+}
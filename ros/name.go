@@ -2,6 +2,7 @@ package ros
 
 import (
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -150,3 +151,38 @@ func processArguments(args []string) (Remapping, Remapping, Remapping, []string)
 	}
 	return mapping, params, specials, rest
 }
+
+// qualifyNodeName builds the fully qualified node name from the name given to
+// NewNode, the namespace and the __name special argument.  A global name is
+// used as is unless __name overrides it.
+func qualifyNodeName(name string, namespace string, specials Remapping) string {
+	if overrideName, ok := specials["__name"]; ok {
+		name = overrideName
+	}
+	if isGlobalName(name) {
+		return canonicalizeName(name)
+	}
+	if len(namespace) == 0 {
+		namespace = GlobalNS
+	}
+	return canonicalizeName(GlobalNS + namespace + Sep + name)
+}
+
+// parseParamValue converts the value of a `_param:=value` argument into the
+// type roscpp would push to the parameter server: int, double, bool or
+// string, in that order of preference.
+func parseParamValue(value string) interface{} {
+	if i, err := strconv.ParseInt(value, 10, 32); err == nil {
+		return int32(i)
+	}
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return f
+	}
+	switch strings.ToLower(value) {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return value
+}
@@ -295,3 +295,49 @@ func TestProcessArguments(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestQualifyNodeName(t *testing.T) {
+	cases := []struct {
+		name      string
+		namespace string
+		specials  Remapping
+		expected  string
+	}{
+		{"talker", "", Remapping{}, "/talker"},
+		{"/talker", "", Remapping{}, "/talker"},
+		{"talker", "/robot", Remapping{}, "/robot/talker"},
+		{"talker", "robot/", Remapping{}, "/robot/talker"},
+		{"/talker", "/robot", Remapping{}, "/talker"},
+		{"/talker", "/robot", Remapping{"__name": "chatter"}, "/robot/chatter"},
+		{"talker", "", Remapping{"__name": "chatter"}, "/chatter"},
+	}
+	for _, c := range cases {
+		result := qualifyNodeName(c.name, c.namespace, c.specials)
+		if result != c.expected {
+			t.Errorf("qualifyNodeName(%q, %q, %v): expected '%s'; got '%s'",
+				c.name, c.namespace, c.specials, c.expected, result)
+		}
+	}
+}
+
+func TestParseParamValue(t *testing.T) {
+	cases := []struct {
+		value    string
+		expected interface{}
+	}{
+		{"42", int32(42)},
+		{"-7", int32(-7)},
+		{"3.5", 3.5},
+		{"10000000000", 1e10},
+		{"true", true},
+		{"False", false},
+		{"hello", "hello"},
+		{"", ""},
+	}
+	for _, c := range cases {
+		result := parseParamValue(c.value)
+		if result != c.expected {
+			t.Errorf("parseParamValue(%q): expected %#v; got %#v", c.value, c.expected, result)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package ros
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// determineHost returns the host name or address other nodes should use to
+// reach this node.  The precedence follows roscpp: the __hostname and __ip
+// special arguments, then ROS_HOSTNAME and ROS_IP, then the machine's host
+// name, and loopback as the last resort.
+func determineHost(specials Remapping) string {
+	if hostname, ok := specials["__hostname"]; ok && len(hostname) > 0 {
+		return hostname
+	}
+	if ip, ok := specials["__ip"]; ok && len(ip) > 0 {
+		return ip
+	}
+	if hostname := os.Getenv("ROS_HOSTNAME"); len(hostname) > 0 {
+		return hostname
+	}
+	if ip := os.Getenv("ROS_IP"); len(ip) > 0 {
+		return ip
+	}
+	if hostname, err := os.Hostname(); err == nil && len(hostname) > 0 && hostname != "localhost" {
+		return hostname
+	}
+	return "127.0.0.1"
+}
+
+// isLoopbackHost reports whether host only refers to the local machine.
+func isLoopbackHost(host string) bool {
+	if host == "localhost" || strings.HasPrefix(host, "localhost.") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// bindAddress returns the address sockets should listen on when the node
+// advertises host.  A loopback host keeps the node private to the machine;
+// anything else listens on all interfaces so the advertised name resolves
+// to a bound socket whichever interface it maps to.
+func bindAddress(host string) string {
+	if isLoopbackHost(host) {
+		return host
+	}
+	return ""
+}
+
+func listenRandomPort(address string, trialLimit int) (net.Listener, error) {
+	var listener net.Listener
+	var err error
+	numTrial := 0
+	for numTrial < trialLimit {
+		port := 1024 + rand.Intn(65535-1024)
+		addr := net.JoinHostPort(address, strconv.Itoa(port))
+		listener, err = net.Listen("tcp", addr)
+		if err == nil {
+			return listener, nil
+		} else {
+			numTrial += 1
+		}
+	}
+	return nil, fmt.Errorf("listenRandomPort exceeds trial limit.")
+}
+
+// listenPort listens on the given port of address, or on a random port if
+// port is 0.
+func listenPort(address string, port int) (net.Listener, error) {
+	if port == 0 {
+		return listenRandomPort(address, 10)
+	}
+	return net.Listen("tcp", net.JoinHostPort(address, strconv.Itoa(port)))
+}
+
+// listenerPort returns the port number a TCP listener is bound to.
+func listenerPort(listener net.Listener) int {
+	return listener.Addr().(*net.TCPAddr).Port
+}
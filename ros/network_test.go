@@ -0,0 +1,92 @@
+package ros
+
+import (
+	"os"
+	"testing"
+)
+
+func setenvForTest(key, value string) func() {
+	old, had := os.LookupEnv(key)
+	if len(value) > 0 {
+		os.Setenv(key, value)
+	} else {
+		os.Unsetenv(key)
+	}
+	return func() {
+		if had {
+			os.Setenv(key, old)
+		} else {
+			os.Unsetenv(key)
+		}
+	}
+}
+
+func TestDetermineHost(t *testing.T) {
+	cases := []struct {
+		rosHostname string
+		rosIP       string
+		specials    Remapping
+		expected    string
+	}{
+		{"", "10.0.0.2", Remapping{}, "10.0.0.2"},
+		{"robot.local", "10.0.0.2", Remapping{}, "robot.local"},
+		{"robot.local", "10.0.0.2", Remapping{"__ip": "10.0.0.3"}, "10.0.0.3"},
+		{"", "", Remapping{"__hostname": "base", "__ip": "10.0.0.3"}, "base"},
+	}
+	for _, c := range cases {
+		restoreHostname := setenvForTest("ROS_HOSTNAME", c.rosHostname)
+		restoreIP := setenvForTest("ROS_IP", c.rosIP)
+		result := determineHost(c.specials)
+		restoreIP()
+		restoreHostname()
+		if result != c.expected {
+			t.Errorf("ROS_HOSTNAME=%q ROS_IP=%q %v: expected '%s'; got '%s'",
+				c.rosHostname, c.rosIP, c.specials, c.expected, result)
+		}
+	}
+
+	restoreHostname := setenvForTest("ROS_HOSTNAME", "")
+	defer restoreHostname()
+	restoreIP := setenvForTest("ROS_IP", "")
+	defer restoreIP()
+	if result := determineHost(Remapping{}); len(result) == 0 {
+		t.Error("expected a fallback host; got empty string")
+	}
+}
+
+func TestBindAddress(t *testing.T) {
+	cases := map[string]string{
+		"127.0.0.1":   "127.0.0.1",
+		"127.0.1.1":   "127.0.1.1",
+		"::1":         "::1",
+		"localhost":   "localhost",
+		"10.0.0.2":    "",
+		"robot.local": "",
+	}
+	for host, expected := range cases {
+		if result := bindAddress(host); result != expected {
+			t.Errorf("bindAddress(%q): expected '%s'; got '%s'", host, expected, result)
+		}
+	}
+}
+
+func TestListenPort(t *testing.T) {
+	listener, err := listenPort("127.0.0.1", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listenerPort(listener)
+	listener.Close()
+	if port == 0 {
+		t.Fatal("expected a random port to be assigned")
+	}
+
+	listener, err = listenPort("127.0.0.1", port)
+	if err != nil {
+		t.Fatalf("could not listen on fixed port %d: %s", port, err)
+	}
+	defer listener.Close()
+	if result := listenerPort(listener); result != port {
+		t.Errorf("expected port %d; got %d", port, result)
+	}
+}
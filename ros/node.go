@@ -1,8 +1,10 @@
 package ros
 
 import (
+	"bytes"
+	"context"
 	"fmt"
-	"math/rand"
+	"io"
 	"net"
 	"net/http"
 	"os"
@@ -10,6 +12,7 @@ import (
 	"reflect"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ppg/rosgo/xmlrpc"
@@ -21,75 +24,113 @@ const (
 	ApiStatusSuccess = 1
 )
 
-// *defaultNode implements Node interface
-// a defaultNode instance must be accessed in user goroutine.
+// *defaultNode implements Node interface.  It is safe for concurrent use:
+// mutex guards the maps of subscribers, publishers, servers and clients, and
+// the rest of its state is set up before NewNode returns or guarded on its
+// own.
 type defaultNode struct {
 	qualifiedName  string
+	namespace      string
+	nameResolver   *NameResolver
+	hostname       string
 	masterUri      string
 	xmlrpcUri      string
 	xmlrpcListener net.Listener
 	xmlrpcHandler  *xmlrpc.Handler
+	tcprosListener net.Listener
+	mutex          sync.Mutex // Guards subscribers, publishers, servers and clients.
 	subscribers    map[string]*defaultSubscriber
 	publishers     map[string]*defaultPublisher
 	servers        map[string]*defaultServiceServer
-	jobChan        chan func()
+	clients        map[*defaultServiceClient]struct{} // Persistent ones.
+	params         *paramCache
+	watchMutex     sync.Mutex     // Guards watchJobs and watchQueued.
+	watchJobs      []func()       // Watch callbacks waiting to run, in order.
+	watchQueued    bool           // Whether a job draining watchJobs is queued or running.
+	queue          *CallbackQueue // Run by Spin.
 	interruptChan  chan os.Signal
-	logger         Logger
-	ok             bool
-	okMutex        sync.RWMutex
+	logger         *rosoutLogger
+	statistics     Publisher // On /statistics, or nil if not enabled.
+	statsWindow    statisticsWindow
+	clock          *clock
+	wallClock      *clock
+	errorCallback  func(error)
+	ctx            context.Context // Canceled when the node shuts down.
+	cancel         context.CancelFunc
 	waitGroup      sync.WaitGroup
+	shutdownOnce   sync.Once
 }
 
-func listenRandomPort(address string, trialLimit int) (net.Listener, error) {
-	var listener net.Listener
-	var err error
-	numTrial := 0
-	for numTrial < trialLimit {
-		port := 1024 + rand.Intn(65535-1024)
-		addr := fmt.Sprintf("%s:%d", address, port)
-		listener, err = net.Listen("tcp", addr)
-		if err == nil {
-			return listener, nil
-		} else {
-			numTrial += 1
-		}
+func newDefaultNode(name string, args []string, opts ...NodeOption) (*defaultNode, error) {
+	node := new(defaultNode)
+	options := nodeOptions{}
+	for _, opt := range opts {
+		opt(&options)
 	}
-	return nil, fmt.Errorf("listenRandomPort exceeds trial limit.")
-}
+	remapping, params, specials, _ := processArguments(args)
 
-func newDefaultNode(name string) *defaultNode {
-	node := new(defaultNode)
-	node.qualifiedName = name
+	node.namespace = os.Getenv("ROS_NAMESPACE")
+	if ns, ok := specials["__ns"]; ok {
+		node.namespace = ns
+	}
+	node.qualifiedName = qualifyNodeName(name, node.namespace, specials)
+	node.nameResolver = newNameResolver(node.qualifiedName, remapping)
 	node.subscribers = make(map[string]*defaultSubscriber)
 	node.publishers = make(map[string]*defaultPublisher)
 	node.servers = make(map[string]*defaultServiceServer)
+	node.clients = make(map[*defaultServiceClient]struct{})
+	node.params = newParamCache()
 	node.interruptChan = make(chan os.Signal)
-	node.ok = true
+	node.ctx, node.cancel = context.WithCancel(context.Background())
+	node.errorCallback = options.errorCallback
+	node.clock = newClock(node.ctx.Done())
+	node.wallClock = newClock(node.ctx.Done())
 
 	logger := NewDefaultLogger()
-	node.logger = logger
+	node.logger = newRosoutLogger(logger, node.qualifiedName, node.clock)
+	if logPath, ok := specials["__log"]; ok {
+		logFile, err := os.OpenFile(logPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			logger.Errorf("Failed to open log file %s: %s", logPath, err)
+		} else {
+			logger.setOutput(io.MultiWriter(os.Stderr, logFile))
+		}
+	}
 
 	// Install signal handler
 	signal.Notify(node.interruptChan, os.Interrupt)
 	go func() {
 		<-node.interruptChan
 		logger.Info("Interrupted")
-		node.okMutex.Lock()
-		node.ok = false
-		node.okMutex.Unlock()
+		node.cancel()
 	}()
 
-	node.jobChan = make(chan func(), 100)
+	node.queue = NewCallbackQueue()
 
 	node.masterUri = os.Getenv("ROS_MASTER_URI")
+	if uri, ok := specials["__master"]; ok {
+		node.masterUri = uri
+	}
 	logger.Debugf("Master URI = %s", node.masterUri)
 
-	listener, err := listenRandomPort("127.0.0.1", 10)
+	node.hostname = determineHost(specials)
+	bindAddr := bindAddress(node.hostname)
+	logger.Debugf("Hostname = %s", node.hostname)
+
+	listener, err := listenPort(bindAddr, options.xmlrpcPort)
 	if err != nil {
-		logger.Fatal(err)
+		node.cancel()
+		return nil, fmt.Errorf("failed to listen for XML-RPC: %w", err)
 	}
-	node.xmlrpcUri = fmt.Sprintf("http://%s", listener.Addr().String())
+	node.xmlrpcUri = fmt.Sprintf("http://%s", net.JoinHostPort(node.hostname, strconv.Itoa(listenerPort(listener))))
 	node.xmlrpcListener = listener
+
+	node.tcprosListener, err = listenPort(bindAddr, options.tcprosPort)
+	if err != nil {
+		node.cancel()
+		node.xmlrpcListener.Close()
+		return nil, fmt.Errorf("failed to listen for TCPROS: %w", err)
+	}
 	m := map[string]xmlrpc.Method{
 		"getBusStats":      func(callerId string) (interface{}, error) { return node.getBusStats(callerId) },
 		"getBusInfo":       func(callerId string) (interface{}, error) { return node.getBusInfo(callerId) },
@@ -110,67 +151,349 @@ func newDefaultNode(name string) *defaultNode {
 	}
 	node.xmlrpcHandler = xmlrpc.NewHandler(m)
 	go http.Serve(node.xmlrpcListener, node.xmlrpcHandler)
+	go node.serveTCPROS()
 	logger.Debugf("Started %s", node.qualifiedName)
-	return node
+
+	for k, v := range params {
+		key := node.nameResolver.resolve(PrivateNS + k[1:])
+		if _, err := callMasterApiContext(node.ctx, node.masterUri, "setParam", node.qualifiedName, key, parseParamValue(v)); err != nil {
+			node.Shutdown()
+			return nil, fmt.Errorf("failed to set private parameter %s: %w", key, err)
+		}
+	}
+
+	if err := node.followClock(); err != nil {
+		node.Shutdown()
+		return nil, err
+	}
+	if !options.noRosout {
+		if err := node.advertiseRosout(logger); err != nil {
+			logger.Warnf("Log messages will not be published: %s", err)
+		}
+	}
+	if err := node.advertiseStatistics(); err != nil {
+		logger.Warnf("Topic statistics will not be published: %s", err)
+	}
+	return node, nil
+}
+
+// Follow simulated time on /clock if /use_sim_time is set.  Clock messages
+// are handled in their own goroutine rather than by Spin, so that time
+// doesn't stop while a callback sleeps.
+func (node *defaultNode) followClock() error {
+	value, err := node.GetParam("/use_sim_time")
+	if err != nil {
+		// Not set, or there is no master to ask.
+		return nil
+	}
+	if useSimTime, ok := value.(bool); !ok || !useSimTime {
+		return nil
+	}
+	node.logger.Debug("Using simulated time")
+	node.clock.simulate()
+	queue := NewCallbackQueue()
+	callback := func(msg *clockMessage) {
+		node.clock.set(msg.Clock)
+	}
+	options := newSubscriberOptions([]SubscriberOption{SubscriberQueueSize(1), SubscriberCallbackQueue(queue)})
+	node.mutex.Lock()
+	_, err = node.subscribe(clockTopic, clockMessageType{}, callback, options)
+	node.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+	node.waitGroup.Add(1)
+	go func() {
+		defer node.waitGroup.Done()
+		queue.spin(node.ctx.Done())
+	}()
+	return nil
+}
+
+// Publish the node's log messages on /rosout.  The publisher logs with
+// logger, which doesn't publish, so that its own messages don't feed back
+// into the topic.
+func (node *defaultNode) advertiseRosout(logger Logger) error {
+	_, err := callMasterApiContext(node.ctx, node.masterUri, "registerPublisher",
+		node.qualifiedName,
+		rosoutTopic, rosoutLogType{}.Name(),
+		node.xmlrpcUri)
+	if err != nil {
+		return fmt.Errorf("failed to register publisher for %s: %w", rosoutTopic, err)
+	}
+	connectChan := make(chan SingleSubscriberPublisher)
+	connectCallback := func(ssp SingleSubscriberPublisher) {
+		select {
+		case connectChan <- ssp:
+		case <-node.ctx.Done():
+		}
+	}
+	options := newPublisherOptions([]PublisherOption{PublisherQueueSize(rosoutQueueSize)})
+	pub := newDefaultPublisher(node.ctx, logger, node.qualifiedName, node.xmlrpcUri, node.masterUri, rosoutTopic, rosoutLogType{}, connectCallback, nil, node.reportError, options)
+	node.mutex.Lock()
+	node.startPublisher(pub)
+	node.mutex.Unlock()
+	close(pub.registered)
+	node.waitGroup.Add(1)
+	go node.logger.run(node.ctx, &node.waitGroup, pub, connectChan)
+	return nil
+}
+
+// Publish the statistics of subscribers' connections on /statistics if
+// /enable_statistics is set.
+func (node *defaultNode) advertiseStatistics() error {
+	var enabled bool
+	if err := node.GetParamInto("/enable_statistics", &enabled); err != nil || !enabled {
+		// Not set, or there is no master to ask.
+		return nil
+	}
+	node.statsWindow = defaultStatisticsWindow
+	params := map[string]interface{}{
+		"/statistics_window_min_elements": &node.statsWindow.minElements,
+		"/statistics_window_max_elements": &node.statsWindow.maxElements,
+		"/statistics_window_min_size":     &node.statsWindow.minSize,
+		"/statistics_window_max_size":     &node.statsWindow.maxSize,
+	}
+	for key, v := range params {
+		// Those which aren't set keep their defaults.
+		_ = node.GetParamInto(key, v)
+	}
+	if !node.statsWindow.valid() {
+		node.logger.Warnf("Invalid /statistics_window_* parameters; using %v to %v", defaultStatisticsWindow.minSize, defaultStatisticsWindow.maxSize)
+		node.statsWindow = defaultStatisticsWindow
+	}
+	pub, err := node.NewPublisher(statisticsTopic, topicStatisticsType{})
+	if err != nil {
+		return err
+	}
+	node.statistics = pub
+	return nil
+}
+
+// Report an error from a connection maintained in the background, either to
+// the user's callback or to the log.
+func (node *defaultNode) reportError(err error) {
+	if node.errorCallback != nil {
+		node.errorCallback(err)
+	} else {
+		node.logger.Error(err)
+	}
 }
 
 func (node *defaultNode) OK() bool {
-	node.okMutex.RLock()
-	ok := node.ok
-	node.okMutex.RUnlock()
-	return ok
+	return node.ctx.Err() == nil
+}
+
+// Address other nodes use to open TCPROS connections to this node.
+func (node *defaultNode) tcprosAddress() string {
+	return net.JoinHostPort(node.hostname, strconv.Itoa(listenerPort(node.tcprosListener)))
+}
+
+// Accept TCPROS connections for all the node's publishers and service servers.
+func (node *defaultNode) serveTCPROS() {
+	logger := node.logger
+	for {
+		conn, err := node.tcprosListener.Accept()
+		if err != nil {
+			logger.Debugf("TCPROS listener closed: %s", err)
+			return
+		}
+		go node.dispatchTCPROS(conn)
+	}
+}
+
+// Read the connection header and hand the connection over to the publisher
+// or service server it asks for.
+func (node *defaultNode) dispatchTCPROS(conn net.Conn) {
+	logger := node.logger
+	headers, err := readConnectionHeader(conn)
+	if err != nil {
+		logger.Errorf("Failed to read connection header from %s: %s", conn.RemoteAddr().String(), err)
+		conn.Close()
+		return
+	}
+	logger.Debug("TCPROS Connection Header:")
+	headerMap := make(map[string]string)
+	for _, h := range headers {
+		headerMap[h.key] = h.value
+		logger.Debugf("  `%s` = `%s`", h.key, h.value)
+	}
+
+	if topic, ok := headerMap["topic"]; ok {
+		node.mutex.Lock()
+		pub, ok := node.publishers[topic]
+		node.mutex.Unlock()
+		if ok {
+			pub.addSession(conn, headerMap)
+			return
+		}
+		rejectTCPROS(conn, fmt.Sprintf("node %s is not publishing topic %s", node.qualifiedName, topic))
+	} else if service, ok := headerMap["service"]; ok {
+		node.mutex.Lock()
+		server, ok := node.servers[service]
+		node.mutex.Unlock()
+		if ok {
+			server.addSession(conn, headerMap)
+			return
+		}
+		rejectTCPROS(conn, fmt.Sprintf("node %s is not providing service %s", node.qualifiedName, service))
+	} else {
+		rejectTCPROS(conn, "connection header has neither topic nor service")
+	}
 }
 
+// Answer a TCPROS connection with an error header and close it.
+func rejectTCPROS(conn net.Conn, message string) {
+	writeConnectionHeader([]header{{"error", message}}, conn)
+	conn.Close()
+}
+
+// Report [publishStats, subscribeStats, serviceStats] as the Slave API
+// defines them.  Service statistics aren't collected.
 func (node *defaultNode) getBusStats(callerId string) (interface{}, error) {
-	return buildRosApiResult(-1, "Not implemented", 0), nil
+	node.mutex.Lock()
+	defer node.mutex.Unlock()
+	publishStats := []interface{}{}
+	for topic, pub := range node.publishers {
+		connections := []interface{}{}
+		for _, c := range pub.GetConnections() {
+			connections = append(connections, []interface{}{
+				c.ID, xmlrpcInt(c.Bytes), xmlrpcInt(c.Messages), c.Connected})
+		}
+		bytesSent := xmlrpcInt(atomic.LoadUint64(&pub.bytesSent))
+		publishStats = append(publishStats, []interface{}{topic, bytesSent, connections})
+	}
+	subscribeStats := []interface{}{}
+	for topic, sub := range node.subscribers {
+		connections := []interface{}{}
+		for _, c := range sub.GetConnections() {
+			connections = append(connections, []interface{}{
+				c.ID, xmlrpcInt(c.Bytes), xmlrpcInt(c.Dropped), c.Connected})
+		}
+		subscribeStats = append(subscribeStats, []interface{}{topic, connections})
+	}
+	stats := []interface{}{publishStats, subscribeStats, []interface{}{}}
+	return buildRosApiResult(ApiStatusSuccess, "Success", stats), nil
 }
 
+// Report a [connectionId, destinationId, direction, transport, topic,
+// connected] entry for each connection.
 func (node *defaultNode) getBusInfo(callerId string) (interface{}, error) {
-	return buildRosApiResult(-1, "Not implemeted", 0), nil
+	var connections []ConnectionStats
+	node.mutex.Lock()
+	for _, pub := range node.publishers {
+		connections = append(connections, pub.GetConnections()...)
+	}
+	for _, sub := range node.subscribers {
+		connections = append(connections, sub.GetConnections()...)
+	}
+	node.mutex.Unlock()
+	info := []interface{}{}
+	for _, c := range connections {
+		info = append(info, []interface{}{c.ID, c.Destination, c.Direction, c.Transport, c.Topic, c.Connected})
+	}
+	return buildRosApiResult(ApiStatusSuccess, "Success", info), nil
 }
 
 func (node *defaultNode) getMasterUri(callerId string) (interface{}, error) {
-	return buildRosApiResult(0, "Success", node.masterUri), nil
+	return buildRosApiResult(ApiStatusSuccess, "Success", node.masterUri), nil
 }
 
 func (node *defaultNode) shutdown(callerId string, msg string) (interface{}, error) {
-	node.okMutex.Lock()
-	node.ok = false
-	node.okMutex.Unlock()
-	return buildRosApiResult(0, "Success", 0), nil
+	node.cancel()
+	return buildRosApiResult(ApiStatusSuccess, "Success", 0), nil
 }
 
 func (node *defaultNode) getPid(callerId string) (interface{}, error) {
-	return buildRosApiResult(0, "Success", os.Getpid()), nil
+	return buildRosApiResult(ApiStatusSuccess, "Success", os.Getpid()), nil
 }
 
 func (node *defaultNode) getSubscriptions(callerId string) (interface{}, error) {
+	node.mutex.Lock()
+	defer node.mutex.Unlock()
 	result := []interface{}{}
 	for t, s := range node.subscribers {
 		pair := []interface{}{t, s.msgType.Name()}
 		result = append(result, pair)
 	}
-	return buildRosApiResult(0, "Success", result), nil
+	return buildRosApiResult(ApiStatusSuccess, "Success", result), nil
 }
 
 func (node *defaultNode) getPublications(callerId string) (interface{}, error) {
+	node.mutex.Lock()
+	defer node.mutex.Unlock()
 	result := []interface{}{}
 	for t, p := range node.publishers {
 		pair := []interface{}{t, p.msgType.Name()}
 		result = append(result, pair)
 	}
-	return buildRosApiResult(0, "Success", result), nil
+	return buildRosApiResult(ApiStatusSuccess, "Success", result), nil
 }
 
 func (node *defaultNode) paramUpdate(callerId string, key string, value interface{}) (interface{}, error) {
-	return buildRosApiResult(-1, "Not implemented", 0), nil
+	node.logger.Debug("Slave API paramUpdate() called.")
+	if ns, ok := value.(map[string]interface{}); ok && len(ns) == 0 {
+		// The master reports a deleted key as an empty namespace.
+		value = nil
+	}
+	node.applyParamChange(cacheKey(key), value)
+	return buildRosApiResult(ApiStatusSuccess, "Success", 0), nil
+}
+
+// Queue the callbacks watching key with its new value.  This doesn't wait
+// for room in the callback queue, since it is called by the master and by
+// callbacks changing parameters.
+func (node *defaultNode) notifyParamWatchers(key string, value interface{}, callbacks []func(interface{})) {
+	if len(callbacks) == 0 {
+		return
+	}
+	node.watchMutex.Lock()
+	defer node.watchMutex.Unlock()
+	for _, callback := range callbacks {
+		callback := callback
+		v := copyParam(value)
+		node.watchJobs = append(node.watchJobs, func() { callback(v) })
+	}
+	if node.watchQueued {
+		return
+	}
+	node.watchQueued = true
+	go func() {
+		select {
+		case node.queue.jobChan <- node.runParamWatchers:
+		case <-node.ctx.Done():
+		}
+	}()
+}
+
+// Run the queued watch callbacks, including those queued meanwhile.  Only
+// one job does at a time, which keeps them in order.
+func (node *defaultNode) runParamWatchers() {
+	for {
+		node.watchMutex.Lock()
+		jobs := node.watchJobs
+		node.watchJobs = nil
+		if len(jobs) == 0 {
+			node.watchQueued = false
+		}
+		node.watchMutex.Unlock()
+		if len(jobs) == 0 {
+			return
+		}
+		for _, job := range jobs {
+			job()
+		}
+	}
 }
 
 func (node *defaultNode) publisherUpdate(callerId string, topic string, publishers []interface{}) (interface{}, error) {
 	node.logger.Debug("Slave API publisherUpdate() called.")
 	var code int32
 	var message string
-	if sub, ok := node.subscribers[topic]; !ok {
+	node.mutex.Lock()
+	sub, ok := node.subscribers[topic]
+	node.mutex.Unlock()
+	if !ok {
 		node.logger.Debug("publisherUpdate() called without subscribing topic.")
 		code = 0
 		message = "No such topic"
@@ -179,7 +502,12 @@ func (node *defaultNode) publisherUpdate(callerId string, topic string, publishe
 		for i, uri := range publishers {
 			pubUris[i] = uri.(string)
 		}
-		sub.pubListChan <- pubUris
+		// The list registration returned goes first.
+		select {
+		case <-sub.registered:
+		case <-sub.done:
+		}
+		sub.updatePublishers(pubUris)
 		code = 1
 		message = "Success"
 	}
@@ -191,7 +519,10 @@ func (node *defaultNode) requestTopic(callerId string, topic string, protocols [
 	var code int32
 	var message string
 	var value interface{}
-	if pub, ok := node.publishers[topic]; !ok {
+	node.mutex.Lock()
+	pub, ok := node.publishers[topic]
+	node.mutex.Unlock()
+	if !ok {
 		node.logger.Debug("requestTopic() called with not publishing topic.")
 		code = 0
 		message = "No such topic"
@@ -204,16 +535,20 @@ func (node *defaultNode) requestTopic(callerId string, topic string, protocols [
 			if protocolName == "TCPROS" {
 				node.logger.Debug("TCPROS requested")
 				selectedProtocol = append(selectedProtocol, "TCPROS")
-				host, portStr := pub.hostAndPort()
-				p, err := strconv.ParseInt(portStr, 10, 32)
-				if err != nil {
-					return nil, err
-				}
-				port := int(p)
-				selectedProtocol = append(selectedProtocol, host)
-				selectedProtocol = append(selectedProtocol, port)
+				selectedProtocol = append(selectedProtocol, node.hostname)
+				selectedProtocol = append(selectedProtocol, listenerPort(node.tcprosListener))
 				break
 			}
+			if protocolName == "UDPROS" {
+				node.logger.Debug("UDPROS requested")
+				params, err := node.connectUDPROS(pub, protocolParams)
+				if err == nil {
+					selectedProtocol = params
+					break
+				}
+				// Try the subscriber's next choice.
+				node.logger.Warnf("UDPROS connection to %s for %s failed: %v", callerId, topic, err)
+			}
 		}
 		node.logger.Debug(selectedProtocol)
 		code = 1
@@ -223,134 +558,461 @@ func (node *defaultNode) requestTopic(callerId string, topic string, protocols [
 	return buildRosApiResult(code, message, value), nil
 }
 
-func (node *defaultNode) NewPublisher(topic string, msgType MessageType) Publisher {
-	return node.NewPublisherWithCallbacks(topic, msgType, nil, nil)
+// Connect to a subscriber which asked for UDPROS with its connection header,
+// host, port and largest datagram, and return the parameters of the
+// connection to answer requestTopic with.
+func (node *defaultNode) connectUDPROS(pub *defaultPublisher, protocolParams []interface{}) ([]interface{}, error) {
+	if len(protocolParams) < 5 {
+		return nil, fmt.Errorf("malformed UDPROS parameters %v", protocolParams)
+	}
+	headerFields, ok1 := protocolParams[1].([]byte)
+	host, ok2 := protocolParams[2].(string)
+	port, ok3 := protocolParams[3].(int32)
+	maxDatagramSize, ok4 := protocolParams[4].(int32)
+	if !ok1 || !ok2 || !ok3 || !ok4 {
+		return nil, fmt.Errorf("malformed UDPROS parameters %v", protocolParams)
+	}
+	if maxDatagramSize <= udprosHeaderSize {
+		return nil, fmt.Errorf("datagrams of %d bytes are too small", maxDatagramSize)
+	}
+	if maxDatagramSize > maxUDPPayload {
+		// The subscriber is told the size in the reply.
+		maxDatagramSize = maxUDPPayload
+	}
+	headers, err := decodeHeaderFields(headerFields)
+	if err != nil {
+		return nil, fmt.Errorf("malformed UDPROS connection header: %w", err)
+	}
+	headerMap := make(map[string]string)
+	for _, h := range headers {
+		headerMap[h.key] = h.value
+	}
+	conn, err := net.Dial("udp", net.JoinHostPort(host, strconv.Itoa(int(port))))
+	if err != nil {
+		return nil, err
+	}
+	session, err := pub.addUDPSession(conn, headerMap, int(maxDatagramSize))
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{
+		"UDPROS",
+		node.hostname,
+		int32(conn.LocalAddr().(*net.UDPAddr).Port),
+		session.link.id,
+		maxDatagramSize,
+		encodeHeaderFields(session.responseHeader()),
+	}, nil
+}
+
+func (node *defaultNode) NewPublisher(topic string, msgType MessageType, opts ...PublisherOption) (Publisher, error) {
+	return node.NewPublisherWithCallbacks(topic, msgType, nil, nil, opts...)
 }
 
 func (node *defaultNode) NewPublisherWithCallbacks(topic string, msgType MessageType,
-	connectCallback, disconnectCallback func(SingleSubscriberPublisher)) Publisher {
-	pub, ok := node.publishers[topic]
-	logger := node.logger
-	if !ok {
-		_, err := callRosApi(node.masterUri, "registerPublisher",
-			node.qualifiedName,
-			topic, msgType.Name(),
-			node.xmlrpcUri)
-		if err != nil {
-			logger.Fatalf("Failed to call registerPublisher(): %s", err)
+	connectCallback, disconnectCallback func(SingleSubscriberPublisher),
+	opts ...PublisherOption) (Publisher, error) {
+	topic = node.nameResolver.resolve(topic)
+	options := newPublisherOptions(opts)
+	node.mutex.Lock()
+	for {
+		pub, ok := node.publishers[topic]
+		if !ok {
+			break
+		}
+		select {
+		case <-pub.registered:
+			node.mutex.Unlock()
+			return pub, nil
+		default:
+			// Wait for whoever is registering it, then look again in case
+			// that failed.
+			node.mutex.Unlock()
+			<-pub.registered
+			node.mutex.Lock()
 		}
+	}
+	// The node's slave API handlers need the mutex, and the master may call
+	// them while the publisher is registered, so it isn't held meanwhile.
+	pub := newDefaultPublisher(node.ctx, node.logger, node.qualifiedName, node.xmlrpcUri, node.masterUri, topic, msgType, connectCallback, disconnectCallback, node.reportError, options)
+	node.startPublisher(pub)
+	node.mutex.Unlock()
+	_, err := callMasterApiContext(node.ctx, node.masterUri, "registerPublisher",
+		node.qualifiedName,
+		topic, msgType.Name(),
+		node.xmlrpcUri)
+	if err != nil {
+		node.mutex.Lock()
+		if node.publishers[topic] == pub {
+			delete(node.publishers, topic)
+		}
+		node.mutex.Unlock()
+		close(pub.registered)
+		pub.Shutdown()
+		return nil, fmt.Errorf("failed to register publisher for %s: %w", topic, err)
+	}
+	close(pub.registered)
+	return pub, nil
+}
 
-		pub = newDefaultPublisher(logger, node.qualifiedName, node.xmlrpcUri, node.masterUri, topic, msgType, connectCallback, disconnectCallback)
-		node.publishers[topic] = pub
-		go pub.start(&node.waitGroup)
+// Add pub to the node and start it.  The caller must hold node.mutex.
+func (node *defaultNode) startPublisher(pub *defaultPublisher) {
+	node.publishers[pub.topic] = pub
+	node.logger.addTopic(pub.topic)
+	// Also run when registering pub fails and NewPublisher shuts it down.
+	pub.onShutdown = func() {
+		node.logger.removeTopic(pub.topic)
+		node.mutex.Lock()
+		defer node.mutex.Unlock()
+		if node.publishers[pub.topic] == pub {
+			delete(node.publishers, pub.topic)
+		}
 	}
-	return pub
+	node.waitGroup.Add(1)
+	go pub.start(&node.waitGroup)
 }
 
-func (node *defaultNode) NewSubscriber(topic string, msgType MessageType, callback interface{}) Subscriber {
-	sub, ok := node.subscribers[topic]
-	logger := node.logger
-	if !ok {
-		node.logger.Debug("Call Master API registerSubscriber")
-		result, err := callRosApi(node.masterUri, "registerSubscriber",
-			node.qualifiedName,
-			topic,
-			msgType.Name(),
-			node.xmlrpcUri)
-		if err != nil {
-			logger.Fatalf("Failed to call registerSubscriber() for %s.", err)
+func (node *defaultNode) NewSubscriber(topic string, msgType MessageType, callback interface{}, opts ...SubscriberOption) (Subscriber, error) {
+	node.mutex.Lock()
+	defer node.mutex.Unlock()
+	sub, err := node.subscribe(node.nameResolver.resolve(topic), msgType, callback, newSubscriberOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (node *defaultNode) WaitForMessage(ctx context.Context, topic string, msgType MessageType) (Message, error) {
+	topic = node.nameResolver.resolve(topic)
+	// Subscribe without callbacks unless the node already has; the
+	// subscription ends with the last wait unless something else uses it.
+	node.mutex.Lock()
+	sub, err := node.subscribe(topic, msgType, nil, newSubscriberOptions(nil))
+	if err != nil {
+		node.mutex.Unlock()
+		return nil, err
+	}
+	if sub.msgType.MD5Sum() != msgType.MD5Sum() {
+		node.mutex.Unlock()
+		return nil, fmt.Errorf("%w: %s is subscribed as %s", ErrMD5Mismatch, topic, sub.msgType.Name())
+	}
+	sub.waiting++
+	node.mutex.Unlock()
+	defer func() {
+		node.mutex.Lock()
+		sub.waiting--
+		unused := sub.waiting == 0 && !sub.hasUser
+		node.mutex.Unlock()
+		if unused {
+			sub.Shutdown()
 		}
-		list, ok := result.([]interface{})
+	}()
+
+	waiter := make(chan messageEvent, 1)
+	select {
+	case sub.addWaiterChan <- waiter:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-sub.done:
+		return nil, ErrShutdown
+	}
+	var msgEvent messageEvent
+	select {
+	case msgEvent = <-waiter:
+	case <-ctx.Done():
+		node.removeWaiter(sub, waiter)
+		return nil, ctx.Err()
+	case <-sub.done:
+		return nil, ErrShutdown
+	}
+	msg := msgType.NewMessage()
+	if err := msg.Deserialize(bytes.NewReader(msgEvent.bytes)); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// Stop sub from passing a message to waiter, which may have got one
+// meanwhile.
+func (node *defaultNode) removeWaiter(sub *defaultSubscriber, waiter chan messageEvent) {
+	select {
+	case sub.removeWaiterChan <- waiter:
+	case <-sub.done:
+	}
+}
+
+// Subscribe to the resolved topic, or add callback to the existing
+// subscription.  A nil callback adds nothing.  options only apply to a new
+// subscription.  The caller must hold node.mutex, which is released while a
+// new subscription is registered with the master.
+func (node *defaultNode) subscribe(topic string, msgType MessageType, callback interface{},
+	options subscriberOptions) (*defaultSubscriber, error) {
+	logger := node.logger
+	for {
+		sub, ok := node.subscribers[topic]
 		if !ok {
-			logger.Fatalf("result is not []string but %s.", reflect.TypeOf(result).String())
+			break
 		}
-		var publishers []string
-		for _, item := range list {
-			s, ok := item.(string)
-			if !ok {
-				logger.Fatal("Publisher list contains no string object")
+		select {
+		case <-sub.registered:
+		default:
+			// Wait for whoever is registering it, then look again in case
+			// that failed.
+			node.mutex.Unlock()
+			<-sub.registered
+			node.mutex.Lock()
+			continue
+		}
+		select {
+		case <-sub.done:
+			// It is shutting down; replace it.
+			ok = false
+		default:
+			if callback != nil && !sub.addCallback(callback) {
+				ok = false
 			}
-			publishers = append(publishers, s)
 		}
+		if !ok {
+			break
+		}
+		if callback != nil {
+			sub.hasUser = true
+		}
+		return sub, nil
+	}
 
-		logger.Debugf("Publisher URI list: ", publishers)
+	sub := newDefaultSubscriber(topic, msgType, callback, options)
+	sub.hostname = node.hostname
+	if node.statistics != nil {
+		sub.statistics = newSubscriberStatistics(node.statistics, node.clock, topic, node.qualifiedName, msgType, node.statsWindow)
+	}
+	node.subscribers[topic] = sub
+	sub.onShutdown = func() {
+		node.mutex.Lock()
+		defer node.mutex.Unlock()
+		if node.subscribers[topic] == sub {
+			delete(node.subscribers, topic)
+		}
+	}
+	logger.Debugf("Start subscriber goroutine for topic '%s'", sub.topic)
+	node.waitGroup.Add(1)
+	go sub.start(node.ctx, &node.waitGroup, node.qualifiedName, node.xmlrpcUri, node.masterUri, node.callbackQueue(options.callbackQueue).jobChan, logger, node.reportError)
 
-		sub = newDefaultSubscriber(topic, msgType, callback)
-		node.subscribers[topic] = sub
+	// The node's slave API handlers need the mutex, and the master may call
+	// them while the subscriber is registered, so it isn't held meanwhile.
+	node.mutex.Unlock()
+	publishers, err := node.registerSubscriber(topic, msgType)
+	node.mutex.Lock()
+	if err != nil {
+		if node.subscribers[topic] == sub {
+			delete(node.subscribers, topic)
+		}
+		close(sub.registered)
+		node.mutex.Unlock()
+		sub.Shutdown()
+		node.mutex.Lock()
+		return nil, err
+	}
+	logger.Debugf("Publisher URI list: %v", publishers)
+	sub.updatePublishers(publishers)
+	close(sub.registered)
+	if callback != nil {
+		sub.hasUser = true
+	}
+	return sub, nil
+}
 
-		logger.Debugf("Start subscriber goroutine for topic '%s'", sub.topic)
-		go sub.start(&node.waitGroup, node.masterUri, node.qualifiedName, node.xmlrpcUri, node.jobChan, logger)
-		logger.Debugf("Done")
-		sub.pubListChan <- publishers
-		logger.Debugf("Update publisher list for topic '%s'", topic)
-	} else {
-		sub.callbacks = append(sub.callbacks, callback)
+// Register a subscriber with the master, returning the URIs of the topic's
+// publishers.
+func (node *defaultNode) registerSubscriber(topic string, msgType MessageType) ([]string, error) {
+	node.logger.Debug("Call Master API registerSubscriber")
+	result, err := callMasterApiContext(node.ctx, node.masterUri, "registerSubscriber",
+		node.qualifiedName,
+		topic,
+		msgType.Name(),
+		node.xmlrpcUri)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register subscriber for %s: %w", topic, err)
+	}
+	list, ok := result.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("registerSubscriber returned %T instead of a publisher list", result)
 	}
-	return sub
+	var publishers []string
+	for _, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("registerSubscriber returned a non-string publisher URI %v", item)
+		}
+		publishers = append(publishers, s)
+	}
+	return publishers, nil
 }
 
-func (node *defaultNode) NewServiceClient(service string, srvType ServiceType) ServiceClient {
-	client := newDefaultServiceClient(node.logger, node.qualifiedName, node.masterUri, service, srvType)
-	return client
+func (node *defaultNode) NewServiceClient(service string, srvType ServiceType, opts ...ServiceClientOption) (ServiceClient, error) {
+	service = node.nameResolver.resolve(service)
+	options := newServiceClientOptions(opts)
+	client := newDefaultServiceClient(node.logger, node.qualifiedName, node.masterUri, service, srvType, options)
+	if options.persistent {
+		// Persistent connections are closed when the node shuts down.
+		node.mutex.Lock()
+		node.clients[client] = struct{}{}
+		node.mutex.Unlock()
+		client.onShutdown = func() {
+			node.mutex.Lock()
+			defer node.mutex.Unlock()
+			delete(node.clients, client)
+		}
+	}
+	return client, nil
+}
+
+// Interval between checks of whether a service is available.
+const serviceProbeInterval = 100 * time.Millisecond
+
+func (node *defaultNode) WaitForService(service string, timeout time.Duration) error {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return node.WaitForServiceContext(ctx, service)
 }
 
-func (node *defaultNode) NewServiceServer(service string, srvType ServiceType, handler interface{}) ServiceServer {
-	server, ok := node.servers[service]
+func (node *defaultNode) WaitForServiceContext(ctx context.Context, service string) error {
+	service = node.nameResolver.resolve(service)
+	client := newDefaultServiceClient(node.logger, node.qualifiedName, node.masterUri, service, probeServiceType{}, serviceClientOptions{})
+	for {
+		err := client.probe(ctx)
+		if err == nil {
+			return nil
+		}
+		node.logger.Debugf("Service %s not available yet: %v", service, err)
+		select {
+		case <-time.After(serviceProbeInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-node.ctx.Done():
+			return ErrShutdown
+		}
+	}
+}
+
+func (node *defaultNode) NewServiceServer(service string, srvType ServiceType, handler interface{}, opts ...ServiceServerOption) (ServiceServer, error) {
+	service = node.nameResolver.resolve(service)
+	node.mutex.Lock()
+	old, ok := node.servers[service]
+	node.mutex.Unlock()
 	if ok {
-		server.Shutdown()
+		old.Shutdown()
 	}
-	server = newDefaultServiceServer(node, service, srvType, handler)
-	if server == nil {
-		return nil
+	server, err := newDefaultServiceServer(node, service, srvType, handler, newServiceServerOptions(opts))
+	if err != nil {
+		return nil, err
 	}
+	node.mutex.Lock()
+	// Another server for the service may have been created meanwhile.
+	old, ok = node.servers[service]
 	node.servers[service] = server
-	return server
+	node.mutex.Unlock()
+	if ok {
+		old.Shutdown()
+	}
+	return server, nil
 }
 
-func (node *defaultNode) SpinOnce() {
-	timeoutChan := time.After(10 * time.Millisecond)
-	select {
-	case job := <-node.jobChan:
-		job()
-	case <-timeoutChan:
-		break
+// The queue to use for callbacks, the node's own unless queue is set.
+func (node *defaultNode) callbackQueue(queue *CallbackQueue) *CallbackQueue {
+	if queue == nil {
+		return node.queue
 	}
+	return queue
 }
 
+// Run the callbacks which are already queued, without waiting for more.
+func (node *defaultNode) SpinOnce() {
+	node.queue.CallAvailable()
+}
+
+// Run callbacks as they arrive until the node shuts down.
 func (node *defaultNode) Spin() {
-	logger := node.logger
-	for node.OK() {
-		timeoutChan := time.After(10 * time.Millisecond)
-		select {
-		case job := <-node.jobChan:
-			logger.Debug("Execute job")
-			job()
-		case <-timeoutChan:
-			break
-		}
-	}
+	node.queue.spin(node.ctx.Done())
+}
+
+func (node *defaultNode) SpinContext(ctx context.Context) error {
+	done, release := mergeDone(ctx, node.ctx.Done())
+	defer release()
+	node.queue.spin(done)
+	return ctx.Err()
+}
+
+// Run callbacks on threads goroutines until the node shuts down.
+func (node *defaultNode) SpinMultiThreaded(threads int) {
+	spinThreads(node.queue, threads, node.ctx.Done())
+}
+
+func (node *defaultNode) CallbackQueue() *CallbackQueue {
+	return node.queue
 }
 
+// Shut the node down, returning once everything it started has finished.
+// Further calls do nothing.
 func (node *defaultNode) Shutdown() {
+	node.shutdownOnce.Do(node.teardown)
+}
+
+func (node *defaultNode) teardown() {
 	node.logger.Debug("Shutting node down")
-	node.okMutex.Lock()
-	node.ok = false
-	node.okMutex.Unlock()
-	node.logger.Debug("Shutdown subscribers")
+	node.cancel()
+	node.logger.Debug("Unsubscribe parameters")
+	for _, key := range node.params.clear() {
+		if _, err := callMasterApi(node.masterUri, "unsubscribeParam", node.qualifiedName, node.xmlrpcUri, key); err != nil {
+			node.logger.Warnf("Failed to unsubscribe parameter %s: %s", key, err)
+		}
+	}
+	node.logger.Debug("Unsubscribe parameters...done")
+	// Shutting down removes them from the maps, so work on copies.
+	node.mutex.Lock()
+	var subscribers []*defaultSubscriber
 	for _, s := range node.subscribers {
+		subscribers = append(subscribers, s)
+	}
+	var publishers []*defaultPublisher
+	for _, p := range node.publishers {
+		publishers = append(publishers, p)
+	}
+	var servers []*defaultServiceServer
+	for _, s := range node.servers {
+		servers = append(servers, s)
+	}
+	var clients []*defaultServiceClient
+	for c := range node.clients {
+		clients = append(clients, c)
+	}
+	node.mutex.Unlock()
+	node.logger.Debug("Shutdown subscribers")
+	for _, s := range subscribers {
 		s.Shutdown()
 	}
 	node.logger.Debug("Shutdown subscribers...done")
 	node.logger.Debug("Shutdown publishers")
-	for _, p := range node.publishers {
+	for _, p := range publishers {
 		p.Shutdown()
 	}
 	node.logger.Debug("Shutdown publishers...done")
 	node.logger.Debug("Shutdown servers")
-	for _, s := range node.servers {
+	for _, s := range servers {
 		s.Shutdown()
 	}
 	node.logger.Debug("Shutdown servers...done")
+	node.logger.Debug("Shutdown service clients")
+	for _, c := range clients {
+		c.Shutdown()
+	}
+	node.logger.Debug("Shutdown service clients...done")
+	node.logger.Debug("Close TCPROS listener")
+	node.tcprosListener.Close()
 	node.logger.Debug("Close XMLRPC lisetner")
 	node.xmlrpcListener.Close()
 	node.logger.Debug("Close XMLRPC done")
@@ -361,20 +1023,139 @@ func (node *defaultNode) Shutdown() {
 	node.waitGroup.Wait()
 	node.logger.Debug("Wait all goroutines...Done")
 	node.logger.Debug("Shutting node down completed")
-	return
 }
 
 func (node *defaultNode) GetParam(key string) (interface{}, error) {
-	return callRosApi(node.masterUri, "getParam", node.qualifiedName, key)
+	return node.GetParamContext(context.Background(), key)
+}
+
+func (node *defaultNode) GetParamContext(ctx context.Context, key string) (interface{}, error) {
+	return callMasterApiContext(ctx, node.masterUri, "getParam", node.qualifiedName, node.nameResolver.resolve(key))
 }
 
 func (node *defaultNode) SetParam(key string, value interface{}) error {
-	_, e := callRosApi(node.masterUri, "setParam", node.qualifiedName, key, value)
+	return node.SetParamContext(context.Background(), key, value)
+}
+
+func (node *defaultNode) SetParamContext(ctx context.Context, key string, value interface{}) error {
+	resolved := node.nameResolver.resolve(key)
+	value, e := encodeParam(resolved, reflect.ValueOf(value))
+	if e != nil {
+		return e
+	}
+	_, e = callMasterApiContext(ctx, node.masterUri, "setParam", node.qualifiedName, resolved, value)
+	if e == nil {
+		node.applyParamChange(resolved, value)
+	}
 	return e
 }
 
+// Update the cached parameters and tell their watchers about a change of
+// key.  The master doesn't tell a node about its own changes, so they are
+// applied here as well.  A nil value means the parameter was deleted.
+func (node *defaultNode) applyParamChange(key string, value interface{}) {
+	for k, v := range node.params.apply(key, value) {
+		node.notifyParamWatchers(k, v, node.params.callbacks(k))
+	}
+}
+
+// Subscribe to key with the master unless the node already has, caching its
+// current value.
+func (node *defaultNode) subscribeParam(key string) error {
+	if node.params.isSubscribed(key) {
+		return nil
+	}
+	value, err := callMasterApiContext(node.ctx, node.masterUri, "subscribeParam", node.qualifiedName, node.xmlrpcUri, key)
+	if err != nil {
+		return err
+	}
+	node.params.subscribe(key, value)
+	return nil
+}
+
+func (node *defaultNode) GetParamCached(key string) (interface{}, error) {
+	resolved := node.nameResolver.resolve(key)
+	value, cached, ok := node.params.lookup(resolved)
+	if !cached {
+		if err := node.subscribeParam(resolved); err != nil {
+			return nil, err
+		}
+		value, _, ok = node.params.lookup(resolved)
+	}
+	if !ok {
+		return nil, errParamNotSet(resolved)
+	}
+	return value, nil
+}
+
+func (node *defaultNode) WatchParam(key string, callback func(interface{})) error {
+	resolved := node.nameResolver.resolve(key)
+	if err := node.subscribeParam(resolved); err != nil {
+		return err
+	}
+	node.params.watch(resolved, callback)
+	return nil
+}
+
+func (node *defaultNode) GetParamInto(key string, v interface{}) error {
+	return node.GetParamIntoContext(context.Background(), key, v)
+}
+
+func (node *defaultNode) GetParamIntoContext(ctx context.Context, key string, v interface{}) error {
+	out := reflect.ValueOf(v)
+	if out.Kind() != reflect.Ptr || out.IsNil() {
+		return fmt.Errorf("GetParamInto needs a non-nil pointer, not %T", v)
+	}
+	resolved := node.nameResolver.resolve(key)
+	value, err := callMasterApiContext(ctx, node.masterUri, "getParam", node.qualifiedName, resolved)
+	if err != nil {
+		return err
+	}
+	return decodeParam(resolved, value, out.Elem())
+}
+
+func (node *defaultNode) GetParamInt(key string) (int, error) {
+	var i int
+	err := node.GetParamInto(key, &i)
+	return i, err
+}
+
+func (node *defaultNode) GetParamFloat64(key string) (float64, error) {
+	var f float64
+	err := node.GetParamInto(key, &f)
+	return f, err
+}
+
+func (node *defaultNode) GetParamBool(key string) (bool, error) {
+	var b bool
+	err := node.GetParamInto(key, &b)
+	return b, err
+}
+
+func (node *defaultNode) GetParamString(key string) (string, error) {
+	var s string
+	err := node.GetParamInto(key, &s)
+	return s, err
+}
+
+func (node *defaultNode) GetParamStringSlice(key string) ([]string, error) {
+	var s []string
+	err := node.GetParamInto(key, &s)
+	return s, err
+}
+
+func (node *defaultNode) GetParamDuration(key string) (Duration, error) {
+	var d Duration
+	err := node.GetParamInto(key, &d)
+	return d, err
+}
+
 func (node *defaultNode) HasParam(key string) (bool, error) {
-	result, err := callRosApi(node.masterUri, "hasParam", node.qualifiedName, key)
+	return node.HasParamContext(context.Background(), key)
+}
+
+func (node *defaultNode) HasParamContext(ctx context.Context, key string) (bool, error) {
+	result, err := callMasterApiContext(ctx, node.masterUri, "hasParam", node.qualifiedName, node.nameResolver.resolve(key))
 	if err != nil {
 		return false, err
 	}
@@ -383,16 +1164,64 @@ func (node *defaultNode) HasParam(key string) (bool, error) {
 }
 
 func (node *defaultNode) SearchParam(key string) (string, error) {
-	result, e := callRosApi(node.masterUri, "searchParam", node.qualifiedName, key)
-	foundKey := result.(string)
-	return foundKey, e
+	return node.SearchParamContext(context.Background(), key)
+}
+
+func (node *defaultNode) SearchParamContext(ctx context.Context, key string) (string, error) {
+	result, e := callMasterApiContext(ctx, node.masterUri, "searchParam", node.qualifiedName, key)
+	if e != nil {
+		return "", e
+	}
+	foundKey, _ := result.(string)
+	return foundKey, nil
 }
 
 func (node *defaultNode) DeleteParam(key string) error {
-	_, e := callRosApi(node.masterUri, "deleteParam", node.qualifiedName, key)
+	return node.DeleteParamContext(context.Background(), key)
+}
+
+func (node *defaultNode) DeleteParamContext(ctx context.Context, key string) error {
+	resolved := node.nameResolver.resolve(key)
+	_, e := callMasterApiContext(ctx, node.masterUri, "deleteParam", node.qualifiedName, resolved)
+	if e == nil {
+		node.applyParamChange(resolved, nil)
+	}
 	return e
 }
 
+func (node *defaultNode) Name() string {
+	return node.qualifiedName
+}
+
+func (node *defaultNode) Now() Time {
+	return node.clock.now()
+}
+
+func (node *defaultNode) Sleep(d Duration) error {
+	return node.clock.sleep(d)
+}
+
+func (node *defaultNode) NewRate(frequency float64) Rate {
+	r := NewRate(frequency)
+	r.clock = node.clock
+	r.Reset()
+	return r
+}
+
+func (node *defaultNode) NewTimer(period Duration, callback func(TimerEvent), oneshot bool, opts ...TimerOption) Timer {
+	options := newTimerOptions(opts)
+	timer := newDefaultTimer(node.clock, node.callbackQueue(options.callbackQueue).jobChan, period, callback, oneshot)
+	timer.Start()
+	return timer
+}
+
+func (node *defaultNode) NewWallTimer(period Duration, callback func(TimerEvent), oneshot bool, opts ...TimerOption) Timer {
+	options := newTimerOptions(opts)
+	timer := newDefaultTimer(node.wallClock, node.callbackQueue(options.callbackQueue).jobChan, period, callback, oneshot)
+	timer.Start()
+	return timer
+}
+
 func (node *defaultNode) Logger() Logger {
 	return node.logger
 }
@@ -0,0 +1,244 @@
+package ros
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ppg/rosgo/master"
+)
+
+// startTestMaster runs a master in-process and returns the arguments which
+// point a node at it.
+func startTestMaster(t *testing.T) (*master.Master, []string) {
+	m, err := master.Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m, []string{"__master:=" + m.Uri(), "__ip:=127.0.0.1"}
+}
+
+func newTestNode(t *testing.T, name string, args []string) *defaultNode {
+	node, err := newDefaultNode(name, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node.Logger().SetSeverity(LogLevelFatal)
+	return node
+}
+
+func TestPublishSubscribe(t *testing.T) {
+	m, args := startTestMaster(t)
+	defer m.Shutdown()
+	talker := newTestNode(t, "talker", args)
+	defer talker.Shutdown()
+	listener := newTestNode(t, "listener", args)
+	defer listener.Shutdown()
+
+	received := make(chan string, 10)
+	if _, err := listener.NewSubscriber("chatter", testMessageType{}, func(msg *testMessage) {
+		received <- msg.Data
+	}); err != nil {
+		t.Fatal(err)
+	}
+	go listener.Spin()
+	pub, err := talker.NewPublisher("chatter", testMessageType{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.After(5 * time.Second)
+	for {
+		pub.Publish(&testMessage{"hello"})
+		select {
+		case data := <-received:
+			if data != "hello" {
+				t.Errorf("expected hello; got %s", data)
+			}
+			if n := pub.GetNumSubscribers(); n != 1 {
+				t.Errorf("expected 1 subscriber; got %d", n)
+			}
+			if name := talker.Name(); name != "/talker" {
+				t.Errorf("expected node name /talker; got %s", name)
+			}
+			return
+		case <-time.After(50 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("no message received")
+		}
+	}
+}
+
+func TestParamsWithMaster(t *testing.T) {
+	m, args := startTestMaster(t)
+	defer m.Shutdown()
+	node := newTestNode(t, "/ns/node", append(args, "_rate:=10"))
+	defer node.Shutdown()
+
+	if v, err := node.GetParam("~rate"); err != nil || v != int32(10) {
+		t.Errorf("expected private parameter 10; got %v, %v", v, err)
+	}
+	if err := node.SetParam("gain", 1.5); err != nil {
+		t.Fatal(err)
+	}
+	if key, err := node.SearchParam("gain"); err != nil || key != "/ns/gain" {
+		t.Errorf("expected to find /ns/gain; got %v, %v", key, err)
+	}
+}
+
+func TestBusStatsAndInfo(t *testing.T) {
+	m, args := startTestMaster(t)
+	defer m.Shutdown()
+	talker := newTestNode(t, "talker", args)
+	defer talker.Shutdown()
+	listener := newTestNode(t, "listener", args)
+	defer listener.Shutdown()
+
+	received := make(chan struct{}, 10)
+	sub, err := listener.NewSubscriber("chatter", testMessageType{}, func(msg *testMessage) {
+		received <- struct{}{}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go listener.Spin()
+	pub, err := talker.NewPublisher("chatter", testMessageType{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.After(5 * time.Second)
+	for waiting := true; waiting; {
+		pub.Publish(&testMessage{"hello"})
+		select {
+		case <-received:
+			waiting = false
+		case <-time.After(50 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("no message received")
+		}
+	}
+
+	// The publisher counts a message once it has been written, which may
+	// be after it arrives.
+	pubStats := pub.GetConnections()
+	for len(pubStats) == 1 && pubStats[0].Messages == 0 {
+		select {
+		case <-time.After(10 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("sent message not counted")
+		}
+		pubStats = pub.GetConnections()
+	}
+	if len(pubStats) != 1 {
+		t.Fatalf("expected 1 publisher connection; got %+v", pubStats)
+	}
+	c := pubStats[0]
+	if c.Destination != "/listener" || c.Direction != "o" || c.Transport != "TCPROS" || c.Topic != "/chatter" || !c.Connected {
+		t.Errorf("unexpected publisher connection %+v", c)
+	}
+	// Each message is 13 bytes: the TCPROS length, the string length and
+	// "hello".
+	if c.Messages == 0 || c.Bytes%13 != 0 || c.Bytes < c.Messages*13 {
+		t.Errorf("expected 13 bytes for each message sent; got %+v", c)
+	}
+	subStats := sub.GetConnections()
+	if len(subStats) != 1 {
+		t.Fatalf("expected 1 subscriber connection; got %+v", subStats)
+	}
+	if c := subStats[0]; c.Destination != talker.xmlrpcUri || c.Direction != "i" || c.Topic != "/chatter" || !c.Connected || c.Messages == 0 {
+		t.Errorf("unexpected subscriber connection %+v", c)
+	}
+
+	result, err := callRosApi(talker.xmlrpcUri, "getBusInfo", "/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var chatter []interface{}
+	for _, item := range result.([]interface{}) {
+		if info := item.([]interface{}); info[4] == "/chatter" {
+			chatter = info
+		}
+	}
+	expected := []interface{}{pubStats[0].ID, "/listener", "o", "TCPROS", "/chatter", true}
+	if len(chatter) != len(expected) {
+		t.Fatalf("expected bus info %v; got %v", expected, chatter)
+	}
+	for i := range expected {
+		if chatter[i] != expected[i] {
+			t.Errorf("expected bus info %v; got %v", expected, chatter)
+			break
+		}
+	}
+
+	result, err = callRosApi(listener.xmlrpcUri, "getBusStats", "/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats := result.([]interface{})
+	if len(stats) != 3 {
+		t.Fatalf("expected publish, subscribe and service stats; got %v", stats)
+	}
+	subscribeStats := stats[1].([]interface{})
+	if len(subscribeStats) != 1 {
+		t.Fatalf("expected stats for one subscription; got %v", subscribeStats)
+	}
+	topicStats := subscribeStats[0].([]interface{})
+	connection := topicStats[1].([]interface{})[0].([]interface{})
+	if topicStats[0] != "/chatter" || connection[0] != subStats[0].ID || connection[1].(int32) <= 0 || connection[3] != true {
+		t.Errorf("unexpected subscription stats %v", topicStats)
+	}
+
+	if pid, err := callRosApi(talker.xmlrpcUri, "getPid", "/test"); err != nil || pid != int32(os.Getpid()) {
+		t.Errorf("expected pid %d; got %v, %v", os.Getpid(), pid, err)
+	}
+}
+
+func TestConnectionHeaderFields(t *testing.T) {
+	m, args := startTestMaster(t)
+	defer m.Shutdown()
+	talker := newTestNode(t, "talker", args)
+	defer talker.Shutdown()
+	listener := newTestNode(t, "listener", args)
+	defer listener.Shutdown()
+	go listener.Spin()
+
+	connected := make(chan map[string]string, 1)
+	pub, err := talker.NewPublisherWithCallbacks("chatter", testMessageType{},
+		func(ssp SingleSubscriberPublisher) { connected <- ssp.GetConnectionHeader() }, nil,
+		PublisherConnectionHeader(map[string]string{"frame": "base", "type": "bogus"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := make(chan map[string]string, 10)
+	if _, err := listener.NewSubscriber("chatter", testMessageType{}, func(msg *testMessage, event MessageEvent) {
+		received <- event.ConnectionHeader
+	}, SubscriberTransportHints(TransportHints{}.TCPNoDelay())); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case header := <-connected:
+		if header["tcp_nodelay"] != "1" || header["callerid"] != "/listener" {
+			t.Errorf("expected the subscriber's header with tcp_nodelay; got %v", header)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscriber didn't connect")
+	}
+	deadline := time.After(5 * time.Second)
+	for waiting := true; waiting; {
+		pub.Publish(&testMessage{"hello"})
+		select {
+		case header := <-received:
+			if header["frame"] != "base" {
+				t.Errorf("expected the extra header field; got %v", header)
+			}
+			if header["type"] != "std_msgs/String" {
+				t.Errorf("expected the publisher's type to win; got %s", header["type"])
+			}
+			waiting = false
+		case <-time.After(50 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("no message received")
+		}
+	}
+}
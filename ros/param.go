@@ -0,0 +1,334 @@
+package ros
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	durationType     = reflect.TypeOf(Duration{})
+	timeDurationType = reflect.TypeOf(time.Duration(0))
+)
+
+// paramField describes how a struct field maps to a parameter.  Fields are
+// named by their `ros:"name"` tag, or by the field name when untagged, and
+// `ros:"name,default=value"` gives the value to use when the parameter has no
+// such member.  `ros:"-"` skips the field.
+type paramField struct {
+	name         string
+	defaultValue string
+	hasDefault   bool
+}
+
+func parseParamField(f reflect.StructField) (paramField, bool) {
+	if f.PkgPath != "" {
+		// Unexported.
+		return paramField{}, false
+	}
+	tag := f.Tag.Get("ros")
+	if tag == "-" {
+		return paramField{}, false
+	}
+	var field paramField
+	// The default comes last and may contain commas itself.
+	if i := strings.Index(tag, ",default="); i >= 0 {
+		field.defaultValue = tag[i+len(",default="):]
+		field.hasDefault = true
+		tag = tag[:i]
+	}
+	field.name = tag
+	if field.name == "" {
+		field.name = f.Name
+	}
+	return field, true
+}
+
+func joinParamPath(path string, name string) string {
+	if strings.HasSuffix(path, "/") {
+		return path + name
+	}
+	return path + "/" + name
+}
+
+func errParamType(path string, value interface{}, t reflect.Type) error {
+	return &ParamError{path, fmt.Sprintf("cannot decode %T into %s", value, t)}
+}
+
+// Integer value of an XML-RPC number, accepting doubles without a fraction.
+func paramInt(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int32:
+		return int64(v), true
+	case float64:
+		if v == math.Trunc(v) && v >= math.MinInt64 && v <= math.MaxInt64 {
+			return int64(v), true
+		}
+	}
+	return 0, false
+}
+
+func paramFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// Decode value, as returned by the master, into out.  path names value in
+// errors.
+func decodeParam(path string, value interface{}, out reflect.Value) error {
+	switch out.Type() {
+	case durationType, timeDurationType:
+		// Durations are given in seconds.
+		sec, ok := paramFloat(value)
+		if !ok || sec < 0 {
+			return errParamType(path, value, out.Type())
+		}
+		if out.Type() == durationType {
+			var d Duration
+			d.FromSec(sec)
+			out.Set(reflect.ValueOf(d))
+		} else {
+			out.SetInt(int64(sec * float64(time.Second)))
+		}
+		return nil
+	}
+
+	switch out.Kind() {
+	case reflect.Interface:
+		v := reflect.ValueOf(value)
+		if !v.IsValid() || !v.Type().AssignableTo(out.Type()) {
+			return errParamType(path, value, out.Type())
+		}
+		out.Set(v)
+	case reflect.Ptr:
+		if out.IsNil() {
+			out.Set(reflect.New(out.Type().Elem()))
+		}
+		return decodeParam(path, value, out.Elem())
+	case reflect.Bool:
+		b, ok := value.(bool)
+		if !ok {
+			return errParamType(path, value, out.Type())
+		}
+		out.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := paramInt(value)
+		if !ok {
+			return errParamType(path, value, out.Type())
+		}
+		if out.OverflowInt(i) {
+			return &ParamError{path, fmt.Sprintf("%v overflows %s", value, out.Type())}
+		}
+		out.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		i, ok := paramInt(value)
+		if !ok {
+			return errParamType(path, value, out.Type())
+		}
+		if i < 0 || out.OverflowUint(uint64(i)) {
+			return &ParamError{path, fmt.Sprintf("%v overflows %s", value, out.Type())}
+		}
+		out.SetUint(uint64(i))
+	case reflect.Float32, reflect.Float64:
+		f, ok := paramFloat(value)
+		if !ok {
+			return errParamType(path, value, out.Type())
+		}
+		if out.OverflowFloat(f) {
+			return &ParamError{path, fmt.Sprintf("%v overflows %s", value, out.Type())}
+		}
+		out.SetFloat(f)
+	case reflect.String:
+		s, ok := value.(string)
+		if !ok {
+			return errParamType(path, value, out.Type())
+		}
+		out.SetString(s)
+	case reflect.Slice:
+		if b, ok := value.([]byte); ok && out.Type().Elem().Kind() == reflect.Uint8 {
+			out.SetBytes(append([]byte(nil), b...))
+			return nil
+		}
+		items, ok := value.([]interface{})
+		if !ok {
+			return errParamType(path, value, out.Type())
+		}
+		s := reflect.MakeSlice(out.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := decodeParam(fmt.Sprintf("%s[%d]", path, i), item, s.Index(i)); err != nil {
+				return err
+			}
+		}
+		out.Set(s)
+	case reflect.Array:
+		items, ok := value.([]interface{})
+		if !ok {
+			return errParamType(path, value, out.Type())
+		}
+		if len(items) != out.Len() {
+			return &ParamError{path, fmt.Sprintf("cannot decode %d items into %s", len(items), out.Type())}
+		}
+		for i, item := range items {
+			if err := decodeParam(fmt.Sprintf("%s[%d]", path, i), item, out.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		ns, ok := value.(map[string]interface{})
+		if !ok || out.Type().Key().Kind() != reflect.String {
+			return errParamType(path, value, out.Type())
+		}
+		m := reflect.MakeMapWithSize(out.Type(), len(ns))
+		for name, member := range ns {
+			elem := reflect.New(out.Type().Elem()).Elem()
+			if err := decodeParam(joinParamPath(path, name), member, elem); err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(name).Convert(out.Type().Key()), elem)
+		}
+		out.Set(m)
+	case reflect.Struct:
+		return decodeParamStruct(path, value, out)
+	default:
+		return errParamType(path, value, out.Type())
+	}
+	return nil
+}
+
+// Decode a namespace into the tagged fields of a struct.  Fields without a
+// member or a default are left alone.
+func decodeParamStruct(path string, value interface{}, out reflect.Value) error {
+	ns, ok := value.(map[string]interface{})
+	if !ok {
+		return errParamType(path, value, out.Type())
+	}
+	t := out.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, ok := parseParamField(t.Field(i))
+		if !ok {
+			continue
+		}
+		fieldPath := joinParamPath(path, field.name)
+		member, ok := ns[field.name]
+		if !ok {
+			if !field.hasDefault {
+				continue
+			}
+			member = parseParamDefault(field.defaultValue, t.Field(i).Type)
+		}
+		if err := decodeParam(fieldPath, member, out.Field(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Interpret a default from a struct tag the way command line parameters
+// are, except that string fields take it verbatim.
+func parseParamDefault(value string, t reflect.Type) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.String {
+		return value
+	}
+	return parseParamValue(value)
+}
+
+// Encode value into the types the master stores, turning structs into
+// namespaces and durations into seconds.  path names value in errors.
+func encodeParam(path string, value reflect.Value) (interface{}, error) {
+	if !value.IsValid() {
+		return nil, &ParamError{path, "cannot encode nil"}
+	}
+	switch value.Type() {
+	case durationType:
+		d := value.Interface().(Duration)
+		return d.ToSec(), nil
+	case timeDurationType:
+		return time.Duration(value.Int()).Seconds(), nil
+	}
+
+	switch value.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if value.IsNil() {
+			return nil, &ParamError{path, "cannot encode nil"}
+		}
+		return encodeParam(path, value.Elem())
+	case reflect.Bool:
+		return value.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		// XML-RPC integers have 32 bits.
+		i := value.Int()
+		if i < math.MinInt32 || i > math.MaxInt32 {
+			return nil, &ParamError{path, fmt.Sprintf("%d overflows int32", i)}
+		}
+		return int32(i), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := value.Uint()
+		if u > math.MaxInt32 {
+			return nil, &ParamError{path, fmt.Sprintf("%d overflows int32", u)}
+		}
+		return int32(u), nil
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), nil
+	case reflect.String:
+		return value.String(), nil
+	case reflect.Slice, reflect.Array:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, value.Len())
+			reflect.Copy(reflect.ValueOf(b), value)
+			return b, nil
+		}
+		items := make([]interface{}, value.Len())
+		for i := range items {
+			item, err := encodeParam(fmt.Sprintf("%s[%d]", path, i), value.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return nil, &ParamError{path, fmt.Sprintf("cannot encode %s", value.Type())}
+		}
+		ns := make(map[string]interface{}, value.Len())
+		for _, key := range value.MapKeys() {
+			name := key.String()
+			member, err := encodeParam(joinParamPath(path, name), value.MapIndex(key))
+			if err != nil {
+				return nil, err
+			}
+			ns[name] = member
+		}
+		return ns, nil
+	case reflect.Struct:
+		ns := make(map[string]interface{})
+		t := value.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field, ok := parseParamField(t.Field(i))
+			if !ok {
+				continue
+			}
+			if f := value.Field(i); (f.Kind() == reflect.Ptr || f.Kind() == reflect.Interface) && f.IsNil() {
+				// Leave optional members out.
+				continue
+			}
+			member, err := encodeParam(joinParamPath(path, field.name), value.Field(i))
+			if err != nil {
+				return nil, err
+			}
+			ns[field.name] = member
+		}
+		return ns, nil
+	}
+	return nil, &ParamError{path, fmt.Sprintf("cannot encode %s", value.Type())}
+}
@@ -0,0 +1,189 @@
+package ros
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// paramCache holds the values of the parameters a node subscribed to with
+// the master, which keeps them up to date through paramUpdate.
+type paramCache struct {
+	mutex    sync.Mutex
+	values   map[string]interface{}         // By subscribed key.
+	watchers map[string][]func(interface{}) // By subscribed key.
+}
+
+func newParamCache() *paramCache {
+	return &paramCache{
+		values:   make(map[string]interface{}),
+		watchers: make(map[string][]func(interface{})),
+	}
+}
+
+// Strip the trailing slash the master adds to subscribed keys.
+func cacheKey(key string) string {
+	if key != "/" {
+		key = strings.TrimSuffix(key, "/")
+	}
+	return key
+}
+
+// Split key into its names below the namespace ns, which must contain it.
+func relativeNames(ns string, key string) []string {
+	rest := strings.TrimPrefix(key, ns)
+	return strings.FieldsFunc(rest, func(r rune) bool { return r == '/' })
+}
+
+// Whether key is ns or lies below it.
+func inNamespace(ns string, key string) bool {
+	return key == ns || ns == "/" || strings.HasPrefix(key, ns+"/")
+}
+
+// Look up key in the cache.  The second result is false unless key or a
+// namespace above it is cached; the third is false if the parameter isn't
+// set.
+func (c *paramCache) lookup(key string) (interface{}, bool, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for ns, value := range c.values {
+		if !inNamespace(ns, key) {
+			continue
+		}
+		for _, name := range relativeNames(ns, key) {
+			m, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, true, false
+			}
+			if value, ok = m[name]; !ok {
+				return nil, true, false
+			}
+		}
+		if m, ok := value.(map[string]interface{}); ok && len(m) == 0 {
+			// The master reports an unset key as an empty namespace.
+			return nil, true, false
+		}
+		return copyParam(value), true, true
+	}
+	return nil, false, false
+}
+
+func (c *paramCache) isSubscribed(key string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	_, ok := c.values[key]
+	return ok
+}
+
+// Start caching key with its value from the master.  A key which is already
+// cached keeps the value it has, which may be newer.
+func (c *paramCache) subscribe(key string, value interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if _, ok := c.values[key]; !ok {
+		c.values[key] = value
+	}
+}
+
+func (c *paramCache) watch(key string, callback func(interface{})) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.watchers[key] = append(c.watchers[key], callback)
+}
+
+// Apply a change of key to every subscribed key it affects, so namespaces
+// and the keys inside them stay consistent whichever update arrives first.  A
+// nil value deletes key.  Returns the new values of the keys which changed.
+func (c *paramCache) apply(key string, value interface{}) map[string]interface{} {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	changed := make(map[string]interface{})
+	for ns, old := range c.values {
+		switch {
+		case inNamespace(ns, key):
+			// The change is inside the subscribed namespace.
+			c.values[ns] = setParamValue(old, relativeNames(ns, key), value)
+		case inNamespace(key, ns):
+			// The subscribed key is inside the changed namespace.
+			var updated interface{} = value
+			for _, name := range relativeNames(key, ns) {
+				m, ok := updated.(map[string]interface{})
+				if !ok {
+					updated = nil
+					break
+				}
+				updated = m[name]
+			}
+			if updated == nil {
+				updated = map[string]interface{}{}
+			}
+			c.values[ns] = copyParam(updated)
+		default:
+			continue
+		}
+		if !reflect.DeepEqual(old, c.values[ns]) {
+			changed[ns] = c.values[ns]
+		}
+	}
+	return changed
+}
+
+func (c *paramCache) callbacks(key string) []func(interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.watchers[key]
+}
+
+// Forget every subscription and return the subscribed keys.
+func (c *paramCache) clear() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	var keys []string
+	for key := range c.values {
+		keys = append(keys, key)
+	}
+	c.values = make(map[string]interface{})
+	c.watchers = make(map[string][]func(interface{}))
+	return keys
+}
+
+// Return tree with the value at names replaced, or deleted if value is nil.
+// tree isn't modified.
+func setParamValue(tree interface{}, names []string, value interface{}) interface{} {
+	if len(names) == 0 {
+		if value == nil {
+			return map[string]interface{}{}
+		}
+		return copyParam(value)
+	}
+	old, _ := tree.(map[string]interface{})
+	m := make(map[string]interface{}, len(old)+1)
+	for k, v := range old {
+		m[k] = v
+	}
+	if len(names) == 1 && value == nil {
+		delete(m, names[0])
+	} else {
+		m[names[0]] = setParamValue(m[names[0]], names[1:], value)
+	}
+	return m
+}
+
+// Copy namespaces so cached values can't be changed through the values
+// handed out.
+func copyParam(value interface{}) interface{} {
+	ns, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+	result := make(map[string]interface{}, len(ns))
+	for k, v := range ns {
+		result[k] = copyParam(v)
+	}
+	return result
+}
+
+func errParamNotSet(key string) error {
+	return &apiStatusError{ApiStatusError, fmt.Sprintf("Parameter [%s] is not set", key)}
+}
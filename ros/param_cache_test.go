@@ -0,0 +1,127 @@
+package ros
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParamCacheAndWatch(t *testing.T) {
+	m, args := startTestMaster(t)
+	defer m.Shutdown()
+	setter := newTestNode(t, "setter", args)
+	defer setter.Shutdown()
+	watcher := newTestNode(t, "watcher", args)
+
+	if err := setter.SetParam("/robot/speed", int32(1)); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := watcher.GetParamCached("/robot/speed"); err != nil || v != int32(1) {
+		t.Fatalf("expected 1; got %v, %v", v, err)
+	}
+	if _, err := watcher.GetParamCached("/robot/missing"); err == nil {
+		t.Error("expected an error for an unset parameter")
+	}
+
+	updates := make(chan interface{}, 10)
+	if err := watcher.WatchParam("/robot", func(value interface{}) {
+		updates <- value
+	}); err != nil {
+		t.Fatal(err)
+	}
+	go watcher.Spin()
+	next := func() interface{} {
+		select {
+		case v := <-updates:
+			return v
+		case <-time.After(5 * time.Second):
+			t.Fatal("no parameter update")
+			return nil
+		}
+	}
+
+	// A change below the watched namespace updates the whole namespace.
+	if err := setter.SetParam("/robot/speed", int32(2)); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{"speed": int32(2)}
+	if v := next(); !reflect.DeepEqual(v, expected) {
+		t.Errorf("expected %v; got %v", expected, v)
+	}
+	if v, err := watcher.GetParamCached("/robot/speed"); err != nil || v != int32(2) {
+		t.Errorf("expected cached 2; got %v, %v", v, err)
+	}
+
+	// The node's own changes are applied without the master.
+	if err := watcher.SetParam("/robot/name", "r2"); err != nil {
+		t.Fatal(err)
+	}
+	expected = map[string]interface{}{"speed": int32(2), "name": "r2"}
+	if v := next(); !reflect.DeepEqual(v, expected) {
+		t.Errorf("expected %v; got %v", expected, v)
+	}
+
+	if err := setter.DeleteParam("/robot"); err != nil {
+		t.Fatal(err)
+	}
+	// The master reports each subscribed key separately, so the namespace
+	// may be seen losing its keys one by one.
+	for v := next(); !reflect.DeepEqual(v, map[string]interface{}{}); v = next() {
+		if _, ok := v.(map[string]interface{}); !ok {
+			t.Fatalf("expected a namespace on the way to being deleted; got %v", v)
+		}
+	}
+	if _, err := watcher.GetParamCached("/robot/speed"); err == nil {
+		t.Error("expected an error for a deleted parameter")
+	}
+
+	watcher.Shutdown()
+	// Once the watcher has unsubscribed, the master forgets it.
+	if _, err := callMasterApi(m.Uri(), "lookupNode", "/setter", "/watcher"); err == nil {
+		t.Error("expected the watcher to be unregistered")
+	}
+}
+
+func TestWatchParamFullCallbackQueue(t *testing.T) {
+	m, args := startTestMaster(t)
+	defer m.Shutdown()
+	node := newTestNode(t, "watcher", args)
+	defer node.Shutdown()
+
+	updates := make(chan interface{}, 10)
+	if err := node.WatchParam("/count", func(value interface{}) {
+		updates <- value
+		// Changing a parameter from a watch callback mustn't wait for
+		// the callback queue.
+		if value == int32(1) {
+			node.SetParam("/count", int32(2))
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for len(node.queue.jobChan) < cap(node.queue.jobChan) {
+		node.queue.jobChan <- func() {}
+	}
+	done := make(chan error, 1)
+	go func() { done <- node.SetParam("/count", int32(1)) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("SetParam blocked on the full callback queue")
+	}
+
+	go node.Spin()
+	for _, expected := range []int32{1, 2} {
+		select {
+		case v := <-updates:
+			if v != expected {
+				t.Errorf("expected %d; got %v", expected, v)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no update to %d", expected)
+		}
+	}
+}
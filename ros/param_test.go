@@ -0,0 +1,155 @@
+package ros
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testWheel struct {
+	Radius float32 `ros:"radius"`
+	Driven bool    `ros:"driven,default=true"`
+}
+
+type testRobot struct {
+	Name     string            `ros:"name,default=robbie, the robot"`
+	MaxSpeed int               `ros:"max_speed,default=3"`
+	Timeout  Duration          `ros:"timeout"`
+	Period   time.Duration     `ros:"period"`
+	Wheels   []testWheel       `ros:"wheels"`
+	Limits   map[string]uint16 `ros:"limits"`
+	Pose     *[3]float64       `ros:"pose"`
+	Comment  string            `ros:"-"`
+	ignored  int
+}
+
+func TestDecodeParam(t *testing.T) {
+	value := map[string]interface{}{
+		"timeout": 1.5,
+		"period":  int32(2),
+		"wheels": []interface{}{
+			map[string]interface{}{"radius": 0.25},
+			map[string]interface{}{"radius": int32(1), "driven": false},
+		},
+		"limits":  map[string]interface{}{"a": int32(7), "b": 8.0},
+		"pose":    []interface{}{1.0, int32(2), 3.5},
+		"Comment": "skipped",
+		"ignored": int32(9),
+	}
+	var robot testRobot
+	if err := decodeParam("/robot", value, reflect.ValueOf(&robot).Elem()); err != nil {
+		t.Fatal(err)
+	}
+	expected := testRobot{
+		Name:     "robbie, the robot",
+		MaxSpeed: 3,
+		Timeout:  NewDuration(1, 500000000),
+		Period:   2 * time.Second,
+		Wheels:   []testWheel{{0.25, true}, {1, false}},
+		Limits:   map[string]uint16{"a": 7, "b": 8},
+		Pose:     &[3]float64{1, 2, 3.5},
+	}
+	if !reflect.DeepEqual(robot, expected) {
+		t.Errorf("expected %+v; got %+v", expected, robot)
+	}
+}
+
+func TestDecodeParamErrors(t *testing.T) {
+	for _, test := range []struct {
+		value interface{}
+		path  string
+	}{
+		{map[string]interface{}{"max_speed": 1.5}, "/robot/max_speed"},
+		{map[string]interface{}{"wheels": []interface{}{map[string]interface{}{}, map[string]interface{}{"radius": "big"}}}, "/robot/wheels[1]/radius"},
+		{map[string]interface{}{"limits": map[string]interface{}{"a": int32(70000)}}, "/robot/limits/a"},
+		{map[string]interface{}{"pose": []interface{}{1.0}}, "/robot/pose"},
+		{map[string]interface{}{"timeout": -1.0}, "/robot/timeout"},
+		{"robbie", "/robot"},
+	} {
+		var robot testRobot
+		err := decodeParam("/robot", test.value, reflect.ValueOf(&robot).Elem())
+		paramErr, ok := err.(*ParamError)
+		if !ok {
+			t.Errorf("%v: expected a *ParamError; got %v", test.value, err)
+			continue
+		}
+		if paramErr.Path != test.path {
+			t.Errorf("expected error at %s; got %s", test.path, paramErr.Path)
+		}
+	}
+}
+
+func TestEncodeParam(t *testing.T) {
+	robot := testRobot{
+		Name:    "r2",
+		Timeout: NewDuration(2, 0),
+		Period:  500 * time.Millisecond,
+		Wheels:  []testWheel{{0.5, true}},
+		Comment: "skipped",
+	}
+	value, err := encodeParam("/robot", reflect.ValueOf(robot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"name":      "r2",
+		"max_speed": int32(0),
+		"timeout":   2.0,
+		"period":    0.5,
+		"wheels": []interface{}{
+			map[string]interface{}{"radius": 0.5, "driven": true},
+		},
+		"limits": map[string]interface{}{},
+	}
+	if !reflect.DeepEqual(value, expected) {
+		t.Errorf("expected %v; got %v", expected, value)
+	}
+
+	robot.Wheels = nil
+	robot.MaxSpeed = 1 << 40
+	_, err = encodeParam("/robot", reflect.ValueOf(robot))
+	if paramErr, ok := err.(*ParamError); !ok || paramErr.Path != "/robot/max_speed" {
+		t.Errorf("expected an error at /robot/max_speed; got %v", err)
+	}
+}
+
+func TestParamStructsWithMaster(t *testing.T) {
+	m, args := startTestMaster(t)
+	defer m.Shutdown()
+	node := newTestNode(t, "node", args)
+	defer node.Shutdown()
+
+	robot := testRobot{Name: "r2", MaxSpeed: 5, Wheels: []testWheel{{0.5, false}}}
+	if err := node.SetParam("robot", robot); err != nil {
+		t.Fatal(err)
+	}
+	var decoded testRobot
+	if err := node.GetParamInto("robot", &decoded); err != nil {
+		t.Fatal(err)
+	}
+	robot.Limits = map[string]uint16{}
+	if !reflect.DeepEqual(decoded, robot) {
+		t.Errorf("expected %+v; got %+v", robot, decoded)
+	}
+	if i, err := node.GetParamInt("robot/max_speed"); err != nil || i != 5 {
+		t.Errorf("expected 5; got %v, %v", i, err)
+	}
+	if f, err := node.GetParamFloat64("robot/max_speed"); err != nil || f != 5 {
+		t.Errorf("expected 5.0; got %v, %v", f, err)
+	}
+	if _, err := node.GetParamString("robot/max_speed"); err == nil {
+		t.Error("expected an error decoding a number into a string")
+	}
+	if err := node.SetParam("names", []string{"a", "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := node.GetParamStringSlice("names"); err != nil || !reflect.DeepEqual(s, []string{"a", "b"}) {
+		t.Errorf("expected [a b]; got %v, %v", s, err)
+	}
+	if err := node.SetParam("timeout", 0.25); err != nil {
+		t.Fatal(err)
+	}
+	if d, err := node.GetParamDuration("timeout"); err != nil || d != NewDuration(0, 250000000) {
+		t.Errorf("expected 0.25s; got %v, %v", d, err)
+	}
+}
@@ -3,13 +3,13 @@ package ros
 import (
 	"bytes"
 	"container/list"
-	"encoding/binary"
-	"encoding/hex"
+	"context"
 	"errors"
 	"fmt"
 	"net"
+	"sort"
 	"sync"
-	"time"
+	"sync/atomic"
 )
 
 type remoteSubscriberSessionError struct {
@@ -22,6 +22,11 @@ func (e *remoteSubscriberSessionError) Error() string {
 }
 
 type defaultPublisher struct {
+	dropped            uint64 // Accessed atomically; keep 64-bit aligned.
+	bytesSent          uint64 // Accessed atomically.
+	numSubscribers     int32  // Accessed atomically.
+	ctx                context.Context
+	cancel             context.CancelFunc
 	logger             Logger
 	nodeId             string
 	nodeApiUri         string
@@ -29,19 +34,31 @@ type defaultPublisher struct {
 	topic              string
 	msgType            MessageType
 	msgChan            chan []byte
+	flushChan          chan chan []flushMarker
 	shutdownChan       chan struct{}
 	sessions           *list.List
+	sessionChan        chan *remoteSubscriberSession
 	sessionErrorChan   chan error
-	listenerErrorChan  chan error
-	listener           net.Listener
 	connectCallback    func(SingleSubscriberPublisher)
 	disconnectCallback func(SingleSubscriberPublisher)
+	reportError        func(error)
+	latch              bool
+	header             map[string]string // Extra connection header fields.
+	lastMsg            []byte
+	queueSize          int
+	stats              connectionSet
+	shutdownOnce       sync.Once
+	registered         chan struct{} // Closed once registration with the master has finished.
+	done               chan struct{} // Closed when the publisher goroutine exits.
+	onShutdown         func()        // Called once the publisher has shut down.
 }
 
-func newDefaultPublisher(logger Logger, nodeId string, nodeApiUri string,
+func newDefaultPublisher(ctx context.Context, logger Logger, nodeId string, nodeApiUri string,
 	masterUri string, topic string, msgType MessageType,
-	connectCallback, disconnectCallback func(SingleSubscriberPublisher)) *defaultPublisher {
+	connectCallback, disconnectCallback func(SingleSubscriberPublisher),
+	reportError func(error), options publisherOptions) *defaultPublisher {
 	pub := new(defaultPublisher)
+	pub.ctx, pub.cancel = context.WithCancel(ctx)
 	pub.logger = logger
 	pub.nodeId = nodeId
 	pub.nodeApiUri = nodeApiUri
@@ -50,91 +67,124 @@ func newDefaultPublisher(logger Logger, nodeId string, nodeApiUri string,
 	pub.msgType = msgType
 	pub.shutdownChan = make(chan struct{}, 10)
 	pub.msgChan = make(chan []byte, 10)
-	pub.listenerErrorChan = make(chan error, 10)
+	pub.flushChan = make(chan chan []flushMarker)
+	pub.sessionChan = make(chan *remoteSubscriberSession, 10)
 	pub.sessionErrorChan = make(chan error, 10)
+	pub.registered = make(chan struct{})
+	pub.done = make(chan struct{})
 	pub.sessions = list.New()
 	pub.connectCallback = connectCallback
 	pub.disconnectCallback = disconnectCallback
-	if listener, err := listenRandomPort("127.0.0.1", 10); err != nil {
-		panic(err)
-	} else {
-		pub.listener = listener
-	}
+	pub.reportError = reportError
+	pub.latch = options.latch
+	pub.header = options.header
+	pub.queueSize = options.queueSize
 	return pub
 }
 
+// Run the publisher until it is shut down.  The caller must have added it
+// to wg.
 func (pub *defaultPublisher) start(wg *sync.WaitGroup) {
 	logger := pub.logger
 	logger.Debugf("Publisher goroutine for %s started.", pub.topic)
-	wg.Add(1)
 	defer func() {
 		logger.Debug("defaultPublisher.start exit")
+		close(pub.done)
 		wg.Done()
 	}()
 
-	go pub.listenRemoteSubscriber()
-
 	for {
 		logger.Debug("defaultPublisher.start loop")
 		select {
 		case msg := <-pub.msgChan:
 			logger.Debug("Receive msgChan")
+			pub.send(msg)
+		case reply := <-pub.flushChan:
+			// Messages published before the flush go ahead of the markers.
+			for drained := false; !drained; {
+				select {
+				case msg := <-pub.msgChan:
+					pub.send(msg)
+				default:
+					drained = true
+				}
+			}
+			var markers []flushMarker
 			for e := pub.sessions.Front(); e != nil; e = e.Next() {
-				session := e.Value.(*remoteSubscriberSession)
-				session.msgChan <- msg
+				marker := make(flushMarker)
+				e.Value.(*remoteSubscriberSession).enqueue(marker)
+				markers = append(markers, marker)
 			}
-		case err := <-pub.listenerErrorChan:
-			logger.Debug("Listener closed unexpectedly: %s", err)
-			pub.listener.Close()
-			return
+			reply <- markers
+		case session := <-pub.sessionChan:
+			logger.Debugf("Connected %s", session.conn.RemoteAddr().String())
+			pub.sessions.PushBack(session)
+			pub.stats.add(session.link)
+			atomic.StoreInt32(&pub.numSubscribers, int32(pub.sessions.Len()))
+			if pub.latch && pub.lastMsg != nil {
+				// Queued behind the response header for the new subscriber.
+				session.enqueue(pub.lastMsg)
+			}
+			go session.start()
 		case err := <-pub.sessionErrorChan:
-			logger.Error(err)
+			logger.Debug(err)
 			if sessionError, ok := err.(*remoteSubscriberSessionError); ok {
 				for e := pub.sessions.Front(); e != nil; e = e.Next() {
 					if e.Value == sessionError.session {
 						pub.sessions.Remove(e)
+						pub.stats.remove(sessionError.session.link)
+						atomic.StoreInt32(&pub.numSubscribers, int32(pub.sessions.Len()))
 						break
 					}
 				}
 			}
 		case <-pub.shutdownChan:
 			logger.Debug("defaultPublisher.start Receive shutdownChan")
-			pub.listener.Close()
-			logger.Debug("defaultPublisher.start closed listener")
-			_, err := callRosApi(pub.masterUri, "unregisterPublisher", pub.nodeId, pub.topic, pub.nodeApiUri)
+			_, err := callMasterApi(pub.masterUri, "unregisterPublisher", pub.nodeId, pub.topic, pub.nodeApiUri)
 			if err != nil {
 				logger.Warn(err)
 			}
+			pub.cancel() // Close all sessions
 			for e := pub.sessions.Front(); e != nil; e = e.Next() {
-				session := e.Value.(*remoteSubscriberSession)
-				session.quitChan <- struct{}{}
+				pub.stats.remove(e.Value.(*remoteSubscriberSession).link)
 			}
-			pub.sessions.Init() // Clear all sessions
+			pub.sessions.Init()
+			atomic.StoreInt32(&pub.numSubscribers, 0)
 			return
 		}
 	}
 }
 
-func (pub *defaultPublisher) listenRemoteSubscriber() {
-	logger := pub.logger
-	logger.Debugf("Start listen %s.", pub.listener.Addr().String())
-	defer func() {
-		logger.Debug("defaultPublisher.listenRemoteSubscriber exit")
-	}()
+// Queue a serialized message for every subscriber.
+func (pub *defaultPublisher) send(msg []byte) {
+	if pub.latch {
+		pub.lastMsg = msg
+	}
+	for e := pub.sessions.Front(); e != nil; e = e.Next() {
+		e.Value.(*remoteSubscriberSession).enqueue(msg)
+	}
+}
 
-	for {
-		logger.Debug("defaultPublisher.listenRemoteSubscriber loop")
-		if conn, err := pub.listener.Accept(); err != nil {
-			logger.Debugf("pub.listner.Accept() failed")
-			pub.listenerErrorChan <- err
-			close(pub.listenerErrorChan)
-			logger.Debugf("defaultPublisher.listenRemoteSubscriber loop exit")
+// flushMarker is queued for a subscriber behind the messages a flush waits
+// for, and closed once they have been written.
+type flushMarker chan struct{}
+
+// Wait until the messages published so far have been written to the
+// subscribers connected now, or ctx is done.
+func (pub *defaultPublisher) flush(ctx context.Context) {
+	reply := make(chan []flushMarker, 1)
+	select {
+	case pub.flushChan <- reply:
+	case <-pub.done:
+		return
+	case <-ctx.Done():
+		return
+	}
+	for _, marker := range <-reply {
+		select {
+		case <-marker:
+		case <-ctx.Done():
 			return
-		} else {
-			logger.Debugf("Connected %s", conn.RemoteAddr().String())
-			session := newRemoteSubscriberSession(pub, conn)
-			pub.sessions.PushBack(session)
-			go session.start()
 		}
 	}
 }
@@ -142,64 +192,180 @@ func (pub *defaultPublisher) listenRemoteSubscriber() {
 func (pub *defaultPublisher) Publish(msg Message) {
 	var buf bytes.Buffer
 	_ = msg.Serialize(&buf)
-	pub.msgChan <- buf.Bytes()
+	select {
+	case pub.msgChan <- buf.Bytes():
+	case <-pub.ctx.Done(): // Shut down; nobody is left to send to.
+	}
+}
+
+func (pub *defaultPublisher) GetNumSubscribers() int {
+	return int(atomic.LoadInt32(&pub.numSubscribers))
+}
+
+func (pub *defaultPublisher) GetNumDropped() uint64 {
+	return atomic.LoadUint64(&pub.dropped)
+}
+
+func (pub *defaultPublisher) GetConnections() []ConnectionStats {
+	return pub.stats.stats()
 }
 
+// Unregister the publisher and close its connections, returning once it has
+// finished.  Further calls do nothing.
 func (pub *defaultPublisher) Shutdown() {
-	pub.shutdownChan <- struct{}{}
+	pub.shutdownOnce.Do(func() {
+		pub.shutdownChan <- struct{}{}
+		<-pub.done
+		if pub.onShutdown != nil {
+			pub.onShutdown()
+		}
+	})
+}
+
+// Hand over a TCPROS connection, whose header has already been read by the
+// node, to the publisher goroutine.
+func (pub *defaultPublisher) addSession(conn net.Conn, headerMap map[string]string) {
+	select {
+	case pub.sessionChan <- newRemoteSubscriberSession(pub, conn, headerMap):
+	case <-pub.done:
+		rejectTCPROS(conn, fmt.Sprintf("topic %s is no longer published", pub.topic))
+	}
 }
 
-func (pub *defaultPublisher) hostAndPort() (string, string) {
-	addr, port, err := net.SplitHostPort(pub.listener.Addr().String())
-	if err != nil {
-		// Not reached
-		panic(err)
+// Hand over a UDPROS connection to a subscriber, whose header came with its
+// requestTopic, to the publisher goroutine.  The session is returned for its
+// response header, which goes back with the answer to requestTopic.
+func (pub *defaultPublisher) addUDPSession(conn net.Conn, headerMap map[string]string, maxDatagramSize int) (*remoteSubscriberSession, error) {
+	session := newRemoteSubscriberSession(pub, conn, headerMap)
+	session.udp = newUDPROSWriter(conn, uint32(session.link.id), maxDatagramSize)
+	session.link.setTransport("UDPROS")
+	if err := session.checkHeader(); err != nil {
+		conn.Close()
+		pub.reportError(err)
+		return nil, err
+	}
+	select {
+	case pub.sessionChan <- session:
+		return session, nil
+	case <-pub.done:
+		conn.Close()
+		return nil, fmt.Errorf("topic %s is no longer published", pub.topic)
 	}
-	return addr, port
 }
 
 type remoteSubscriberSession struct {
 	conn               net.Conn
+	headerMap          map[string]string
 	nodeId             string
 	topic              string
 	typeText           string
 	md5sum             string
 	typeName           string
-	quitChan           chan struct{}
-	msgChan            chan []byte
+	latching           bool
+	extraHeader        map[string]string
+	ctx                context.Context
+	cancel             context.CancelFunc
+	queue              *boundedQueue
+	dropped            *uint64
+	bytesSent          *uint64
+	link               *connection
+	udp                *udprosWriter // Set for UDPROS connections.
 	errorChan          chan error
 	logger             Logger
 	connectCallback    func(SingleSubscriberPublisher)
 	disconnectCallback func(SingleSubscriberPublisher)
+	reportError        func(error)
 }
 
-func newRemoteSubscriberSession(pub *defaultPublisher, conn net.Conn) *remoteSubscriberSession {
+func newRemoteSubscriberSession(pub *defaultPublisher, conn net.Conn, headerMap map[string]string) *remoteSubscriberSession {
 	session := new(remoteSubscriberSession)
 	session.conn = conn
+	session.headerMap = headerMap
 	session.nodeId = pub.nodeId
 	session.topic = pub.topic
 	session.typeText = pub.msgType.Text()
 	session.md5sum = pub.msgType.MD5Sum()
 	session.typeName = pub.msgType.Name()
-	session.quitChan = make(chan struct{})
-	session.msgChan = make(chan []byte, 10)
+	session.latching = pub.latch
+	session.extraHeader = pub.header
+	session.ctx, session.cancel = context.WithCancel(pub.ctx)
+	session.queue = newBoundedQueue(pub.queueSize)
+	session.dropped = &pub.dropped
+	session.bytesSent = &pub.bytesSent
+	session.link = newConnection(headerMap["callerid"], directionOutbound, pub.topic)
 	session.errorChan = pub.sessionErrorChan
 	session.logger = pub.logger
 	session.connectCallback = pub.connectCallback
 	session.disconnectCallback = pub.disconnectCallback
+	session.reportError = pub.reportError
 	return session
 }
 
+// Queue a serialized message, or a flushMarker, for the subscriber, dropping
+// the oldest queued message if the queue is full.
+func (session *remoteSubscriberSession) enqueue(msg interface{}) {
+	if session.queue.push(msg) {
+		atomic.AddUint64(session.dropped, 1)
+		session.link.drop()
+	}
+}
+
+// Check that the subscriber wants the type which is published.
+func (session *remoteSubscriberSession) checkHeader() error {
+	headerMap := session.headerMap
+	if headerMap["type"] != session.typeName || headerMap["md5sum"] != session.md5sum {
+		return fmt.Errorf("%w: subscriber %s wants %s [%s] on %s, which publishes %s [%s]",
+			ErrMD5Mismatch, headerMap["callerid"], headerMap["type"], headerMap["md5sum"],
+			session.topic, session.typeName, session.md5sum)
+	}
+	return nil
+}
+
+func (session *remoteSubscriberSession) responseHeader() []header {
+	var resHeaders []header
+	resHeaders = append(resHeaders, header{"message_definition", session.typeText})
+	resHeaders = append(resHeaders, header{"callerid", session.nodeId})
+	if session.latching {
+		resHeaders = append(resHeaders, header{"latching", "1"})
+	} else {
+		resHeaders = append(resHeaders, header{"latching", "0"})
+	}
+	resHeaders = append(resHeaders, header{"md5sum", session.md5sum})
+	resHeaders = append(resHeaders, header{"topic", session.topic})
+	resHeaders = append(resHeaders, header{"type", session.typeName})
+	var keys []string
+	for key := range session.extraHeader {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		switch key {
+		case "message_definition", "callerid", "latching", "md5sum", "topic", "type":
+			continue
+		}
+		resHeaders = append(resHeaders, header{key, session.extraHeader[key]})
+	}
+	return resHeaders
+}
+
+func (session *remoteSubscriberSession) writeMessage(msg []byte) error {
+	if session.udp != nil {
+		return session.udp.writeMessage(msg)
+	}
+	return writeMessage(session.conn, msg)
+}
+
 type singleSubPub struct {
 	subName string
 	topic   string
-	msgChan chan []byte
+	header  map[string]string
+	session *remoteSubscriberSession
 }
 
 func (ssp *singleSubPub) Publish(msg Message) {
 	var buf bytes.Buffer
 	_ = msg.Serialize(&buf)
-	ssp.msgChan <- buf.Bytes()
+	ssp.session.enqueue(buf.Bytes())
 }
 
 func (ssp *singleSubPub) GetSubscriberName() string {
@@ -210,117 +376,113 @@ func (ssp *singleSubPub) GetTopic() string {
 	return ssp.topic
 }
 
+func (ssp *singleSubPub) GetConnectionHeader() map[string]string {
+	header := make(map[string]string, len(ssp.header))
+	for key, value := range ssp.header {
+		header[key] = value
+	}
+	return header
+}
+
 func (session *remoteSubscriberSession) start() {
 	logger := session.logger
 	logger.Debug("remoteSubscriberSession.start enter")
 
 	ssp := &singleSubPub{
 		topic:   session.topic,
-		msgChan: session.msgChan,
+		session: session,
 		// callerId is filled in after header gets read later in this function.
 	}
 
+	// Closing the connection unblocks the writer when the publisher shuts
+	// down.
+	go func() {
+		<-session.ctx.Done()
+		session.conn.Close()
+	}()
 	defer func() {
 		logger.Debug("remoteSubscriberSession.start exit")
+		session.cancel()
 
 		if session.disconnectCallback != nil {
 			session.disconnectCallback(ssp)
 		}
 	}()
 	defer func() {
+		var e error
 		if err := recover(); err != nil {
-			if e, ok := err.(error); ok {
-				session.errorChan <- &remoteSubscriberSessionError{session, e}
-			} else {
+			var ok bool
+			if e, ok = err.(error); !ok {
 				e = fmt.Errorf("Unkonwn error value")
-				session.errorChan <- &remoteSubscriberSessionError{session, e}
 			}
 		} else {
-			e := fmt.Errorf("Normal exit")
-			session.errorChan <- &remoteSubscriberSessionError{session, e}
+			e = fmt.Errorf("Normal exit")
+		}
+		select {
+		case session.errorChan <- &remoteSubscriberSessionError{session, e}:
+		case <-session.ctx.Done():
 		}
 	}()
-	// 1. Read connection header
-	headers, err := readConnectionHeader(session.conn)
-	if err != nil {
-		panic(errors.New("Failed to read connection header."))
-	}
-	logger.Debug("TCPROS Connection Header:")
-	headerMap := make(map[string]string)
-	for _, h := range headers {
-		headerMap[h.key] = h.value
-		logger.Debugf("  `%s` = `%s`", h.key, h.value)
-	}
-	if headerMap["type"] != session.typeName || headerMap["md5sum"] != session.md5sum {
-		panic(errors.New("Incomatible message type!"))
+	// 1. Check connection header, which addUDPSession already did for
+	// UDPROS
+	headerMap := session.headerMap
+	if err := session.checkHeader(); err != nil {
+		writeConnectionHeader([]header{{"error", err.Error()}}, session.conn)
+		session.reportError(err)
+		return
 	}
 	ssp.subName = headerMap["callerid"]
+	ssp.header = headerMap
+	if tcpConn, ok := session.conn.(*net.TCPConn); ok && headerMap["tcp_nodelay"] == "1" {
+		// Go disables Nagle's algorithm by default already; this makes
+		// sure of it for the subscriber which asked.
+		tcpConn.SetNoDelay(true)
+	}
 	if session.connectCallback != nil {
 		go session.connectCallback(ssp)
 	}
 
-	// 2. Return reponse header
-	var resHeaders []header
-	resHeaders = append(resHeaders, header{"message_definition", session.typeText})
-	resHeaders = append(resHeaders, header{"callerid", session.nodeId})
-	resHeaders = append(resHeaders, header{"latching", "0"})
-	resHeaders = append(resHeaders, header{"md5sum", session.md5sum})
-	resHeaders = append(resHeaders, header{"topic", session.topic})
-	resHeaders = append(resHeaders, header{"type", session.typeName})
-	logger.Debug("TCPROS Response Header")
-	for _, h := range resHeaders {
-		logger.Debugf("  `%s` = `%s`", h.key, h.value)
-	}
-	err = writeConnectionHeader(resHeaders, session.conn)
-	if err != nil {
-		panic(errors.New("Failed to write response header."))
+	// 2. Return reponse header, which UDPROS sends through requestTopic
+	if session.udp == nil {
+		resHeaders := session.responseHeader()
+		logger.Debug("TCPROS Response Header")
+		for _, h := range resHeaders {
+			logger.Debugf("  `%s` = `%s`", h.key, h.value)
+		}
+		err := writeConnectionHeader(resHeaders, session.conn)
+		if err != nil {
+			panic(errors.New("Failed to write response header."))
+		}
 	}
+	session.link.setConnected(true)
+	defer session.link.setConnected(false)
 
 	// 3. Start sending message
 	logger.Debug("Start sending messages...")
-	queue := list.New()
-	queueMaxSize := 100
 	for {
-		//logger.Debug("session.remoteSubscriberSession")
 		select {
-		case msg := <-session.msgChan:
-			logger.Debug("Receive msgChan")
-			if queue.Len() == queueMaxSize {
-				queue.Remove(queue.Front())
-			}
-			queue.PushBack(msg)
-		case <-session.quitChan:
-			logger.Debug("Receive quitChan")
+		case <-session.ctx.Done():
+			logger.Debug("Session canceled")
 			return
-		case <-time.After(10 * time.Millisecond):
-			if queue.Len() > 0 {
-				logger.Debug("writing")
-				msg := queue.Front().Value.([]byte)
-				queue.Remove(queue.Front())
-				logger.Debug(hex.EncodeToString(msg))
-				session.conn.SetDeadline(time.Now().Add(10 * time.Millisecond))
-				size := uint32(len(msg))
-				if err := binary.Write(session.conn, binary.LittleEndian, size); err != nil {
-					if neterr, ok := err.(net.Error); ok && neterr.Timeout() {
-						logger.Debug("timeout")
-						continue
-					} else {
-						logger.Error(err)
-						panic(err)
-					}
+		case <-session.queue.notify():
+			for {
+				item, ok := session.queue.pop()
+				if !ok {
+					break
+				}
+				if marker, ok := item.(flushMarker); ok {
+					close(marker)
+					continue
 				}
-				logger.Debug(len(msg))
-				session.conn.SetDeadline(time.Now().Add(10 * time.Millisecond))
-				if _, err := session.conn.Write(msg); err != nil {
-					if neterr, ok := err.(net.Error); ok && neterr.Timeout() {
-						logger.Debug("timeout")
-						continue
-					} else {
-						logger.Error(err)
-						panic(err)
+				msg := item.([]byte)
+				if err := session.writeMessage(msg); err != nil {
+					if session.ctx.Err() != nil {
+						return
 					}
+					panic(err)
 				}
-				logger.Debug(hex.EncodeToString(msg))
+				session.link.count(len(msg))
+				atomic.AddUint64(session.bytesSent, uint64(len(msg))+4)
 			}
 		}
 	}
@@ -0,0 +1,224 @@
+package ros
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testMessageType is a std_msgs/String compatible message type for tests
+// that can't import generated messages.
+type testMessageType struct{}
+
+func (testMessageType) Text() string        { return "string data\n" }
+func (testMessageType) MD5Sum() string      { return "992ce8a1687cec8c8bd883ec73ca41d1" }
+func (testMessageType) Name() string        { return "std_msgs/String" }
+func (testMessageType) NewMessage() Message { return new(testMessage) }
+
+type testMessage struct {
+	Data string
+}
+
+func (m *testMessage) Serialize(w io.Writer) error {
+	return SerializeMessageField(w, "string", &m.Data)
+}
+
+func (m *testMessage) Deserialize(r io.Reader) error {
+	return DeserializeMessageField(r, "string", &m.Data)
+}
+
+// connectTestSubscriber hands one end of a pipe to pub as if a subscriber had
+// connected, and returns the other end with the response header read.
+func connectTestSubscriber(t *testing.T, pub *defaultPublisher) (net.Conn, map[string]string) {
+	local, remote := net.Pipe()
+	pub.addSession(remote, map[string]string{
+		"topic":    pub.topic,
+		"type":     pub.msgType.Name(),
+		"md5sum":   pub.msgType.MD5Sum(),
+		"callerid": "/test_subscriber",
+	})
+	local.SetDeadline(time.Now().Add(5 * time.Second))
+	headers, err := readConnectionHeader(local)
+	if err != nil {
+		t.Fatalf("could not read response header: %s", err)
+	}
+	headerMap := make(map[string]string)
+	for _, h := range headers {
+		headerMap[h.key] = h.value
+	}
+	return local, headerMap
+}
+
+func readTestMessage(t *testing.T, conn net.Conn) string {
+	var size uint32
+	if err := binary.Read(conn, binary.LittleEndian, &size); err != nil {
+		t.Fatalf("could not read message size: %s", err)
+	}
+	var msg testMessage
+	if err := msg.Deserialize(io.LimitReader(conn, int64(size))); err != nil {
+		t.Fatalf("could not read message: %s", err)
+	}
+	return msg.Data
+}
+
+func TestLatchedPublisher(t *testing.T) {
+	var wg sync.WaitGroup
+	pub := newDefaultPublisher(context.Background(), NewDefaultLogger(), "/test_node", "", "", "/latched",
+		testMessageType{}, nil, nil, nil, publisherOptions{latch: true})
+	wg.Add(1)
+	go pub.start(&wg)
+	defer pub.Shutdown()
+
+	// The first subscriber only makes sure the publisher has handled the
+	// message before the second one connects.
+	first, headerMap := connectTestSubscriber(t, pub)
+	defer first.Close()
+	if headerMap["latching"] != "1" {
+		t.Errorf("expected latching=1; got '%s'", headerMap["latching"])
+	}
+	pub.Publish(&testMessage{"latched"})
+	if data := readTestMessage(t, first); data != "latched" {
+		t.Errorf("expected 'latched'; got '%s'", data)
+	}
+
+	second, _ := connectTestSubscriber(t, pub)
+	defer second.Close()
+	if data := readTestMessage(t, second); data != "latched" {
+		t.Errorf("expected latched message for late subscriber; got '%s'", data)
+	}
+
+	pub.Publish(&testMessage{"live"})
+	if data := readTestMessage(t, second); data != "live" {
+		t.Errorf("expected 'live'; got '%s'", data)
+	}
+}
+
+func TestUnlatchedPublisher(t *testing.T) {
+	var wg sync.WaitGroup
+	pub := newDefaultPublisher(context.Background(), NewDefaultLogger(), "/test_node", "", "", "/unlatched",
+		testMessageType{}, nil, nil, nil, publisherOptions{})
+	wg.Add(1)
+	go pub.start(&wg)
+	defer pub.Shutdown()
+
+	first, headerMap := connectTestSubscriber(t, pub)
+	defer first.Close()
+	if headerMap["latching"] != "0" {
+		t.Errorf("expected latching=0; got '%s'", headerMap["latching"])
+	}
+	pub.Publish(&testMessage{"missed"})
+	if data := readTestMessage(t, first); data != "missed" {
+		t.Errorf("expected 'missed'; got '%s'", data)
+	}
+
+	second, _ := connectTestSubscriber(t, pub)
+	defer second.Close()
+	pub.Publish(&testMessage{"received"})
+	if data := readTestMessage(t, second); data != "received" {
+		t.Errorf("expected 'received' as first message; got '%s'", data)
+	}
+}
+
+func TestPublisherQueueDropsOldest(t *testing.T) {
+	pub := newDefaultPublisher(context.Background(), NewDefaultLogger(), "/test_node", "", "", "/chatter",
+		testMessageType{}, nil, nil, nil, publisherOptions{queueSize: 2})
+	local, remote := net.Pipe()
+	defer local.Close()
+	defer remote.Close()
+	session := newRemoteSubscriberSession(pub, remote, nil)
+
+	for _, data := range []string{"a", "b", "c", "d", "e"} {
+		session.enqueue([]byte(data))
+	}
+	if n := pub.GetNumDropped(); n != 3 {
+		t.Errorf("expected 3 dropped messages; got %d", n)
+	}
+	for _, expected := range []string{"d", "e"} {
+		item, ok := session.queue.pop()
+		if !ok || string(item.([]byte)) != expected {
+			t.Errorf("expected queued message '%s'; got %v, %v", expected, item, ok)
+		}
+	}
+}
+
+// flush returns once the messages published before it have been written,
+// or when its context is done.
+func TestPublisherFlush(t *testing.T) {
+	var wg sync.WaitGroup
+	pub := newDefaultPublisher(context.Background(), NewDefaultLogger(), "/test_node", "", "", "/chatter",
+		testMessageType{}, nil, nil, nil, publisherOptions{queueSize: 10})
+	wg.Add(1)
+	go pub.start(&wg)
+	defer pub.Shutdown()
+	conn, _ := connectTestSubscriber(t, pub)
+	defer conn.Close()
+
+	pub.Publish(&testMessage{"a"})
+	pub.Publish(&testMessage{"b"})
+	flushed := make(chan struct{})
+	go func() {
+		pub.flush(context.Background())
+		close(flushed)
+	}()
+	if data := readTestMessage(t, conn); data != "a" {
+		t.Errorf("expected 'a'; got '%s'", data)
+	}
+	select {
+	case <-flushed:
+		t.Error("flush returned before every message was written")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if data := readTestMessage(t, conn); data != "b" {
+		t.Errorf("expected 'b'; got '%s'", data)
+	}
+	select {
+	case <-flushed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("flush didn't return once the messages were written")
+	}
+
+	pub.Publish(&testMessage{"unread"})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	pub.flush(ctx)
+}
+
+func TestPublisherRejectsMismatchedSubscriber(t *testing.T) {
+	var wg sync.WaitGroup
+	reported := make(chan error, 1)
+	pub := newDefaultPublisher(context.Background(), NewDefaultLogger(), "/test_node", "", "", "/chatter",
+		testMessageType{}, nil, nil, func(err error) { reported <- err }, publisherOptions{})
+	wg.Add(1)
+	go pub.start(&wg)
+	defer pub.Shutdown()
+
+	local, remote := net.Pipe()
+	defer local.Close()
+	pub.addSession(remote, map[string]string{
+		"topic":    "/chatter",
+		"type":     "std_msgs/Int32",
+		"md5sum":   "da5909fbe378aeaf85e547e830cc1bb7",
+		"callerid": "/test_subscriber",
+	})
+	local.SetDeadline(time.Now().Add(5 * time.Second))
+	headers, err := readConnectionHeader(local)
+	if err != nil {
+		t.Fatalf("could not read response header: %s", err)
+	}
+	if len(headers) != 1 || headers[0].key != "error" {
+		t.Errorf("expected an error header; got %v", headers)
+	}
+	select {
+	case err := <-reported:
+		if !errors.Is(err, ErrMD5Mismatch) {
+			t.Errorf("expected ErrMD5Mismatch; got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("mismatch was not reported")
+	}
+}
@@ -0,0 +1,75 @@
+package ros
+
+import (
+	"container/list"
+	"sync"
+)
+
+// boundedQueue is a FIFO queue shared between goroutines.  Instead of
+// blocking when it is full it drops its oldest item, the same way roscpp
+// treats queue_size.  A size of 0 makes the queue unbounded.
+type boundedQueue struct {
+	mutex      sync.Mutex
+	items      *list.List
+	size       int
+	notifyChan chan struct{}
+}
+
+func newBoundedQueue(size int) *boundedQueue {
+	q := new(boundedQueue)
+	q.items = list.New()
+	q.size = size
+	q.notifyChan = make(chan struct{}, 1)
+	return q
+}
+
+// Append item to the queue.  Returns true if the oldest item was dropped to
+// make room for it.
+func (q *boundedQueue) push(item interface{}) bool {
+	_, dropped := q.pushDropping(item)
+	return dropped
+}
+
+// Append item to the queue like push, also returning the item which was
+// dropped, if any.
+func (q *boundedQueue) pushDropping(item interface{}) (interface{}, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	var dropped interface{}
+	ok := false
+	if q.size > 0 && q.items.Len() >= q.size {
+		dropped = q.items.Remove(q.items.Front())
+		ok = true
+	}
+	q.items.PushBack(item)
+	select {
+	case q.notifyChan <- struct{}{}:
+	default:
+		// A notification is already pending.
+	}
+	return dropped, ok
+}
+
+// Remove and return the oldest item.  The second result is false if the
+// queue is empty.
+func (q *boundedQueue) pop() (interface{}, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	front := q.items.Front()
+	if front == nil {
+		return nil, false
+	}
+	return q.items.Remove(front), true
+}
+
+func (q *boundedQueue) len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.items.Len()
+}
+
+// Channel which becomes readable after items have been pushed.  A consumer
+// should pop until the queue is empty every time it receives from it.
+func (q *boundedQueue) notify() <-chan struct{} {
+	return q.notifyChan
+}
@@ -0,0 +1,41 @@
+package ros
+
+import (
+	"testing"
+)
+
+func TestBoundedQueue(t *testing.T) {
+	q := newBoundedQueue(3)
+	for i := 0; i < 3; i++ {
+		if q.push(i) {
+			t.Errorf("push(%d) dropped an item before the queue was full", i)
+		}
+	}
+	if !q.push(3) {
+		t.Error("expected push to a full queue to drop an item")
+	}
+	if q.len() != 3 {
+		t.Errorf("expected length 3; got %d", q.len())
+	}
+	for _, expected := range []int{1, 2, 3} {
+		item, ok := q.pop()
+		if !ok || item.(int) != expected {
+			t.Errorf("expected %d; got %v, %v", expected, item, ok)
+		}
+	}
+	if _, ok := q.pop(); ok {
+		t.Error("expected pop from an empty queue to fail")
+	}
+}
+
+func TestUnboundedQueue(t *testing.T) {
+	q := newBoundedQueue(0)
+	for i := 0; i < 1000; i++ {
+		if q.push(i) {
+			t.Fatalf("push(%d) dropped an item from an unbounded queue", i)
+		}
+	}
+	if q.len() != 1000 {
+		t.Errorf("expected length 1000; got %d", q.len())
+	}
+}
@@ -5,19 +5,20 @@ type Rate struct {
 	actualCycleTime   Duration
 	expectedCycleTime Duration
 	start             Time
+	clock             *clock // The wall clock when nil.
 }
 
 func NewRate(frequency float64) Rate {
 	var actualCycleTime, expectedCycleTime Duration
 	expectedCycleTime.FromSec(1.0 / frequency)
 	start := Now()
-	return Rate{actualCycleTime, expectedCycleTime, start}
+	return Rate{actualCycleTime, expectedCycleTime, start, nil}
 }
 
 func CycleTime(d Duration) Rate {
 	var actualCycleTime Duration
 	start := Now()
-	return Rate{actualCycleTime, d, start}
+	return Rate{actualCycleTime, d, start, nil}
 }
 
 func (r *Rate) CycleTime() Duration {
@@ -30,19 +31,27 @@ func (r *Rate) ExpectedCycleTime() Duration {
 
 func (r *Rate) Reset() {
 	r.actualCycleTime = NewDuration(0, 0)
-	r.start = Now()
+	r.start = r.clock.now()
 }
 
+// Sleep until the current cycle is over.  With a node's rate, the error is
+// the one from the node's Sleep, after which the rate starts a new cycle.
 func (r *Rate) Sleep() error {
-	end := Now()
+	end := r.clock.now()
+	if end.Cmp(r.start) < 0 {
+		// Time jumped backward since the last cycle.
+		r.start = end
+	}
 	diff := end.Diff(r.start)
 	var remaining Duration
 	if r.expectedCycleTime.Cmp(diff) >= 0 {
 		remaining = r.expectedCycleTime.Sub(diff)
 	}
-	remaining.Sleep()
-	now := Now()
-	r.actualCycleTime = now.Diff(r.start)
+	err := r.clock.sleep(remaining)
+	now := r.clock.now()
+	if err == nil {
+		r.actualCycleTime = now.Diff(r.start)
+	}
 	r.start = now
-	return nil
+	return err
 }
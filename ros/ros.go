@@ -1,18 +1,22 @@
 package ros
 
 import (
+	"context"
+	"os"
+	"runtime"
 	"time"
 )
 
 type Node interface {
-	NewPublisher(topic string, msgType MessageType) Publisher
+	NewPublisher(topic string, msgType MessageType, opts ...PublisherOption) (Publisher, error)
 	// Create a publisher which gives you callbacks when subscribers
 	// connect and disconnect.  The callbacks are called in their own
 	// goroutines, so they don't need to return immediately to let the
 	// connection proceed.
 	NewPublisherWithCallbacks(topic string,
 		msgType MessageType,
-		connectCallback, disconnectCallback func(SingleSubscriberPublisher)) Publisher
+		connectCallback, disconnectCallback func(SingleSubscriberPublisher),
+		opts ...PublisherOption) (Publisher, error)
 	// callback should be a function which takes 0, 1, or 2 arguments.
 	// If it takes 0 arguments, it will simply be called without the
 	// message.  1-argument functions are the normal case, and the
@@ -20,33 +24,247 @@ type Node interface {
 	// function takes 2 arguments, the first argument should be of the
 	// generated message type and the second argument should be of
 	// type MessageEvent.
-	NewSubscriber(topic string, msgType MessageType, callback interface{}) Subscriber
-	NewServiceClient(service string, srvType ServiceType) ServiceClient
-	NewServiceServer(service string, srvType ServiceType, callback interface{}) ServiceServer
+	NewSubscriber(topic string, msgType MessageType, callback interface{}, opts ...SubscriberOption) (Subscriber, error)
+	// Wait for the next message on topic and return it, without needing
+	// Spin.  The node subscribes to the topic for the wait unless it
+	// already has.  It returns ctx's error if ctx is done first and
+	// ErrShutdown if the node shuts down.
+	WaitForMessage(ctx context.Context, topic string, msgType MessageType) (Message, error)
+	NewServiceClient(service string, srvType ServiceType, opts ...ServiceClientOption) (ServiceClient, error)
+	// Wait until service is registered and accepts connections.  It
+	// returns context.DeadlineExceeded if timeout passes first, waiting
+	// indefinitely if timeout isn't positive, and ErrShutdown if the node
+	// shuts down.
+	WaitForService(service string, timeout time.Duration) error
+	// Wait for service like WaitForService, until ctx is done.
+	WaitForServiceContext(ctx context.Context, service string) error
+	NewServiceServer(service string, srvType ServiceType, callback interface{}, opts ...ServiceServerOption) (ServiceServer, error)
 
 	OK() bool
+	// Run the callbacks waiting in the node's callback queue.
 	SpinOnce()
+	// Run callbacks from the node's callback queue until the node shuts
+	// down.
 	Spin()
+	// Spin until ctx is done or the node shuts down.  It returns ctx's
+	// error in the first case and nil in the second.
+	SpinContext(ctx context.Context) error
+	// Spin on threads goroutines, so that one slow callback doesn't hold
+	// up the others.  Zero or fewer threads means one per CPU.
+	SpinMultiThreaded(threads int)
+	// The queue run by Spin, which callbacks go to unless another queue is
+	// attached.
+	CallbackQueue() *CallbackQueue
 	Shutdown()
 
+	// Fully qualified name of the node, such as /ns/talker.
+	Name() string
+
+	// Current time on the node's clock.  When the /use_sim_time parameter
+	// is true the clock follows the simulated time published on /clock,
+	// and is zero until the first message arrives; otherwise it is wall
+	// time.
+	Now() Time
+	// Sleep for d on the node's clock, waiting while simulated time is
+	// paused.  It returns ErrShutdown if the node shuts down first and
+	// ErrTimeJumpedBackward if simulated time moves back meanwhile.
+	Sleep(d Duration) error
+	// Create a Rate which runs on the node's clock.
+	NewRate(frequency float64) Rate
+	// Create a running timer which calls callback from Spin every period on
+	// the node's clock, or only once if oneshot is set.  A zero period
+	// calls callback each time Spin gets to it.
+	NewTimer(period Duration, callback func(TimerEvent), oneshot bool, opts ...TimerOption) Timer
+	// Create a timer like NewTimer which runs on wall time even when the
+	// node uses simulated time.
+	NewWallTimer(period Duration, callback func(TimerEvent), oneshot bool, opts ...TimerOption) Timer
+
 	GetParam(name string) (interface{}, error)
+	// The parameter methods taking a context give up when ctx is done,
+	// returning an error which wraps ctx's.
+	GetParamContext(ctx context.Context, name string) (interface{}, error)
+	// Get a parameter into the value v points to.  Namespaces decode into
+	// structs, whose fields are named by `ros:"name"` tags and may take a
+	// default with `ros:"name,default=value"`, or into maps.  Numbers convert
+	// to any numeric type which holds them exactly, and seconds into
+	// Duration or time.Duration.  Values which don't fit return a
+	// *ParamError.
+	GetParamInto(name string, v interface{}) error
+	GetParamIntoContext(ctx context.Context, name string, v interface{}) error
+	GetParamInt(name string) (int, error)
+	GetParamFloat64(name string) (float64, error)
+	GetParamBool(name string) (bool, error)
+	GetParamString(name string) (string, error)
+	GetParamStringSlice(name string) ([]string, error)
+	GetParamDuration(name string) (Duration, error)
+	// Set a parameter.  Structs are stored as namespaces, using the same
+	// tags as GetParamInto, and durations as seconds.
 	SetParam(name string, value interface{}) error
+	SetParamContext(ctx context.Context, name string, value interface{}) error
 	HasParam(name string) (bool, error)
+	HasParamContext(ctx context.Context, name string) (bool, error)
 	SearchParam(name string) (string, error)
+	SearchParamContext(ctx context.Context, name string) (string, error)
 	DeleteParam(name string) error
+	DeleteParamContext(ctx context.Context, name string) error
+	// Get a parameter like GetParam, but from a local copy which the master
+	// keeps up to date once the node has asked for it.
+	GetParamCached(name string) (interface{}, error)
+	// Call callback from Spin with the new value each time the parameter
+	// changes.  A deleted parameter is passed as an empty namespace.
+	WatchParam(name string, callback func(interface{})) error
 
 	Logger() Logger
 }
 
-func NewNode(name string) Node {
-	return newDefaultNode(name)
+// Create a node named name.  Command line arguments are processed the same
+// way roscpp does: `from:=to` remaps names, `_param:=value` sets private
+// parameters, and `__name`, `__ns`, `__master`, `__ip`, `__hostname` and
+// `__log` override the node's name, namespace, master URI, advertised host
+// and log file.
+//
+// The advertised host is taken from __hostname, __ip, ROS_HOSTNAME or ROS_IP
+// in that order.  Sockets listen on loopback only when that host is a
+// loopback address, and on all interfaces otherwise.
+//
+// An error is returned if the node's sockets can't be opened or its private
+// parameters can't be set on the master.
+func NewNode(name string, opts ...NodeOption) (Node, error) {
+	node, err := newDefaultNode(name, os.Args[1:], opts...)
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// NodeOption configures a node created by NewNode.
+type NodeOption func(*nodeOptions)
+
+type nodeOptions struct {
+	xmlrpcPort    int
+	tcprosPort    int
+	errorCallback func(error)
+	noRosout      bool
+}
+
+// XMLRPCPort makes the node serve its slave API on port instead of a random
+// port.
+func XMLRPCPort(port int) NodeOption {
+	return func(opts *nodeOptions) {
+		opts.xmlrpcPort = port
+	}
+}
+
+// TCPROSPort makes the node accept topic and service connections on port
+// instead of a random port.
+func TCPROSPort(port int) NodeOption {
+	return func(opts *nodeOptions) {
+		opts.tcprosPort = port
+	}
+}
+
+// ConnectionErrorCallback makes the node pass errors from connections it
+// maintains in the background, such as a subscriber link which can't reach
+// its publisher, to callback instead of logging them.  callback is called
+// from internal goroutines.
+func ConnectionErrorCallback(callback func(error)) NodeOption {
+	return func(opts *nodeOptions) {
+		opts.errorCallback = callback
+	}
+}
+
+// NoRosout stops the node from publishing its log messages on /rosout.  It
+// is meant for nodes which subscribe to /rosout themselves, such as the
+// aggregator.
+func NoRosout() NodeOption {
+	return func(opts *nodeOptions) {
+		opts.noRosout = true
+	}
 }
 
 type Publisher interface {
 	Publish(msg Message)
+	// Number of subscribers currently connected.
+	GetNumSubscribers() int
+	// Number of messages dropped because a subscriber's queue was full.
+	GetNumDropped() uint64
+	// Connections to subscribers, current and failed ones included until
+	// they are closed.
+	GetConnections() []ConnectionStats
 	Shutdown()
 }
 
+// ConnectionStats describes a connection between a publisher and a
+// subscriber, as reported by the getBusInfo and getBusStats Slave API calls.
+type ConnectionStats struct {
+	// Identifies the connection within the node.
+	ID int32
+	// The subscribing node's name for a publisher's connection, or the
+	// publishing node's Slave API URI for a subscriber's.
+	Destination string
+	// "o" for a publisher's connection, "i" for a subscriber's.
+	Direction string
+	Transport string
+	Topic     string
+	// Bytes, including framing, and messages sent by a publisher or
+	// received by a subscriber.
+	Bytes    uint64
+	Messages uint64
+	// Messages dropped because a queue was full.
+	Dropped   uint64
+	Connected bool
+}
+
+// Queue size used by publishers and subscribers unless overridden.
+const DefaultQueueSize = 100
+
+// PublisherOption configures a publisher created by Node.NewPublisher.
+type PublisherOption func(*publisherOptions)
+
+type publisherOptions struct {
+	latch     bool
+	queueSize int
+	header    map[string]string
+}
+
+func newPublisherOptions(opts []PublisherOption) publisherOptions {
+	options := publisherOptions{queueSize: DefaultQueueSize}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// PublisherQueueSize sets how many outgoing messages are buffered for each
+// subscriber.  When a subscriber falls behind the oldest message is dropped.
+// A size of 0 means unbounded.
+func PublisherQueueSize(size int) PublisherOption {
+	return func(opts *publisherOptions) {
+		opts.queueSize = size
+	}
+}
+
+// Latch makes the publisher keep the last published message and send it to
+// every subscriber as soon as it connects.
+func Latch() PublisherOption {
+	return func(opts *publisherOptions) {
+		opts.latch = true
+	}
+}
+
+// PublisherConnectionHeader adds fields to the connection header sent to
+// every subscriber, which finds them in MessageEvent.ConnectionHeader.  The
+// fields the publisher sets itself, such as type and md5sum, can't be
+// replaced.
+func PublisherConnectionHeader(fields map[string]string) PublisherOption {
+	return func(opts *publisherOptions) {
+		opts.header = make(map[string]string, len(fields))
+		for key, value := range fields {
+			opts.header[key] = value
+		}
+	}
+}
+
 // A publisher which only sends to one specific subscriber.  This is
 // sent as an argument to the connect and disconnect callback
 // functions passed to Node.NewPublisherWithCallbacks().
@@ -54,13 +272,139 @@ type SingleSubscriberPublisher interface {
 	Publish(msg Message)
 	GetSubscriberName() string
 	GetTopic() string
+	// The connection header the subscriber sent, including the
+	// tcp_nodelay its TransportHints asked for.
+	GetConnectionHeader() map[string]string
 }
 
 type Subscriber interface {
 	GetNumPublishers() int
+	// Number of received messages dropped because the callback queue was
+	// full.
+	GetNumDropped() uint64
+	// Connections to publishers, including those which are being retried.
+	GetConnections() []ConnectionStats
+	// Wait until at least n publishers are connected.  It returns
+	// context.DeadlineExceeded if timeout passes first, waiting
+	// indefinitely if timeout isn't positive, and ErrShutdown if the
+	// subscriber shuts down.
+	WaitForPublishers(n int, timeout time.Duration) error
+	// Wait for publishers like WaitForPublishers, until ctx is done.
+	WaitForPublishersContext(ctx context.Context, n int) error
 	Shutdown()
 }
 
+// SubscriberOption configures a subscriber created by Node.NewSubscriber.
+type SubscriberOption func(*subscriberOptions)
+
+type subscriberOptions struct {
+	queueSize     int
+	callbackQueue *CallbackQueue
+	concurrent    bool
+	hints         TransportHints
+}
+
+func newSubscriberOptions(opts []SubscriberOption) subscriberOptions {
+	options := subscriberOptions{queueSize: DefaultQueueSize}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// SubscriberQueueSize sets how many received messages wait for their
+// callbacks.  When callbacks fall behind the oldest message is dropped.  A
+// size of 0 means unbounded.
+func SubscriberQueueSize(size int) SubscriberOption {
+	return func(opts *subscriberOptions) {
+		opts.queueSize = size
+	}
+}
+
+// SubscriberCallbackQueue makes the subscriber run its callbacks from queue
+// instead of the node's callback queue.
+func SubscriberCallbackQueue(queue *CallbackQueue) SubscriberOption {
+	return func(opts *subscriberOptions) {
+		opts.callbackQueue = queue
+	}
+}
+
+// AllowConcurrentCallbacks lets a multi-threaded spinner run callbacks for
+// several of the subscriber's messages at once.  Without it they run one at a
+// time, in the order the messages arrived.
+func AllowConcurrentCallbacks() SubscriberOption {
+	return func(opts *subscriberOptions) {
+		opts.concurrent = true
+	}
+}
+
+// SubscriberTransportHints sets the transports the subscriber asks
+// publishers for.
+func SubscriberTransportHints(hints TransportHints) SubscriberOption {
+	return func(opts *subscriberOptions) {
+		opts.hints = hints
+	}
+}
+
+// TransportHints lists the transports a subscriber asks publishers for, in
+// order of preference, as in roscpp.  The zero value asks for TCPROS.  For
+// example
+//
+//	ros.TransportHints{}.Unreliable().Reliable()
+//
+// asks for UDPROS, falling back to TCPROS for publishers without it.
+type TransportHints struct {
+	transports      []string
+	maxDatagramSize int
+	tcpNoDelay      bool
+}
+
+// Reliable adds TCPROS to the transports.
+func (h TransportHints) Reliable() TransportHints {
+	h.transports = append(append([]string(nil), h.transports...), "TCPROS")
+	return h
+}
+
+// Unreliable adds UDPROS to the transports.
+func (h TransportHints) Unreliable() TransportHints {
+	h.transports = append(append([]string(nil), h.transports...), "UDPROS")
+	return h
+}
+
+// MaxDatagramSize sets the largest datagram, header included, which UDPROS
+// publishers may send.  It defaults to DefaultMaxDatagramSize, and can't
+// exceed the 65507 bytes a UDP datagram holds.
+func (h TransportHints) MaxDatagramSize(size int) TransportHints {
+	h.maxDatagramSize = size
+	return h
+}
+
+// TCPNoDelay asks for TCPROS connections without Nagle's algorithm, so small
+// messages are sent at once rather than batched.  Go turns the algorithm
+// off by default, so this matters mostly to publishers in other client
+// libraries.
+func (h TransportHints) TCPNoDelay() TransportHints {
+	h.tcpNoDelay = true
+	return h
+}
+
+func (h TransportHints) protocols() []string {
+	if len(h.transports) == 0 {
+		return []string{"TCPROS"}
+	}
+	return h.transports
+}
+
+func (h TransportHints) datagramSize() int {
+	if h.maxDatagramSize <= 0 {
+		return DefaultMaxDatagramSize
+	}
+	if h.maxDatagramSize > maxUDPPayload {
+		return maxUDPPayload
+	}
+	return h.maxDatagramSize
+}
+
 // Optional second argument to a Subscriber callback.
 type MessageEvent struct {
 	PublisherName    string
@@ -79,7 +423,125 @@ type ServiceServer interface {
 	Shutdown()
 }
 
+// ServiceServerOption configures a service server created by
+// Node.NewServiceServer.
+type ServiceServerOption func(*serviceServerOptions)
+
+type serviceServerOptions struct {
+	callbackQueue *CallbackQueue
+	timeout       time.Duration
+	threads       int
+}
+
+func newServiceServerOptions(opts []ServiceServerOption) serviceServerOptions {
+	var options serviceServerOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// ServiceCallbackQueue makes the service server run its handler from queue
+// instead of the node's callback queue.
+func ServiceCallbackQueue(queue *CallbackQueue) ServiceServerOption {
+	return func(opts *serviceServerOptions) {
+		opts.callbackQueue = queue
+	}
+}
+
+// ServiceTimeout sets how long the service server waits for its handler to
+// answer a request, after which the caller is sent an error, and for each
+// read and write of a request or response.  A timeout of 0, the default,
+// means no limit.
+func ServiceTimeout(timeout time.Duration) ServiceServerOption {
+	return func(opts *serviceServerOptions) {
+		opts.timeout = timeout
+	}
+}
+
+// ServiceThreads makes the service server run its handler on a pool of
+// threads goroutines of its own, instead of from a callback queue, so
+// requests are answered without spinning and several at once.  Zero or
+// fewer threads means one per CPU.
+func ServiceThreads(threads int) ServiceServerOption {
+	return func(opts *serviceServerOptions) {
+		if threads <= 0 {
+			threads = runtime.NumCPU()
+		}
+		opts.threads = threads
+	}
+}
+
+// TimerOption configures a timer created by Node.NewTimer or
+// Node.NewWallTimer.
+type TimerOption func(*timerOptions)
+
+type timerOptions struct {
+	callbackQueue *CallbackQueue
+}
+
+func newTimerOptions(opts []TimerOption) timerOptions {
+	var options timerOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// TimerCallbackQueue makes the timer run its callback from queue instead of
+// the node's callback queue.
+func TimerCallbackQueue(queue *CallbackQueue) TimerOption {
+	return func(opts *timerOptions) {
+		opts.callbackQueue = queue
+	}
+}
+
 type ServiceClient interface {
 	Call(srv Service) error
+	// Call like Call, giving up when ctx is done and returning ctx's
+	// error.
+	CallContext(ctx context.Context, srv Service) error
+	// Whether the service is registered and accepts connections of the
+	// client's type, checked without calling it.
+	Exists() bool
+	// Close the client's persistent connection.  Calls made after it fail
+	// with ErrShutdown.
 	Shutdown()
 }
+
+// ServiceClientOption configures a service client created by
+// Node.NewServiceClient.
+type ServiceClientOption func(*serviceClientOptions)
+
+type serviceClientOptions struct {
+	persistent bool
+	timeout    time.Duration
+}
+
+func newServiceClientOptions(opts []ServiceClientOption) serviceClientOptions {
+	var options serviceClientOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// Persistent makes the service client keep its connection open and reuse it
+// for every call, as a roscpp persistent client does, instead of looking the
+// service up and connecting for each one.  The connection is re-established
+// on the call after one which failed.
+func Persistent() ServiceClientOption {
+	return func(opts *serviceClientOptions) {
+		opts.persistent = true
+	}
+}
+
+// CallTimeout limits how long each call of the service client may take,
+// from looking the service up to receiving the response, after which it
+// fails with context.DeadlineExceeded.  By default calls wait as long as the
+// service takes to answer.
+func CallTimeout(timeout time.Duration) ServiceClientOption {
+	return func(opts *serviceClientOptions) {
+		opts.timeout = timeout
+	}
+}
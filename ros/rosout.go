@@ -0,0 +1,368 @@
+package ros
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+	"time"
+)
+
+const (
+	// Topic every node publishes its log messages on.
+	rosoutTopic = "/rosout"
+	// Number of log messages kept for /rosout before anyone subscribes to
+	// it, and buffered while they are being sent.
+	rosoutQueueSize = 100
+	// Time Fatalf allows its message to reach /rosout before exiting.
+	rosoutFlushTimeout = time.Second
+)
+
+// Severity levels of rosgraph_msgs/Log.
+var rosoutLevels = map[LogLevel]int8{
+	LogLevelDebug: 1,
+	LogLevelInfo:  2,
+	LogLevelWarn:  4,
+	LogLevelError: 8,
+	LogLevelFatal: 16,
+}
+
+// rosoutLogType is rosgraph_msgs/Log.  The generated message can't be used
+// here because the msgs packages import this one.
+type rosoutLogType struct{}
+
+func (rosoutLogType) Text() string {
+	return rosoutLogText
+}
+
+func (rosoutLogType) MD5Sum() string {
+	return "acffd30cd6b6de30f120938c17c593fb"
+}
+
+func (rosoutLogType) Name() string {
+	return "rosgraph_msgs/Log"
+}
+
+func (rosoutLogType) NewMessage() Message {
+	return new(rosoutLog)
+}
+
+type rosoutLog struct {
+	Seq      uint32
+	Stamp    Time
+	FrameID  string
+	Level    int8
+	Name     string
+	Msg      string
+	File     string
+	Function string
+	Line     uint32
+	Topics   []string
+}
+
+func (m *rosoutLog) Serialize(w io.Writer) (err error) {
+	if err = SerializeMessageField(w, "uint32", &m.Seq); err != nil {
+		return err
+	}
+	if err = SerializeMessageField(w, "time", &m.Stamp); err != nil {
+		return err
+	}
+	if err = SerializeMessageField(w, "string", &m.FrameID); err != nil {
+		return err
+	}
+	if err = SerializeMessageField(w, "byte", &m.Level); err != nil {
+		return err
+	}
+	for _, s := range []*string{&m.Name, &m.Msg, &m.File, &m.Function} {
+		if err = SerializeMessageField(w, "string", s); err != nil {
+			return err
+		}
+	}
+	if err = SerializeMessageField(w, "uint32", &m.Line); err != nil {
+		return err
+	}
+	if err = binary.Write(w, binary.LittleEndian, uint32(len(m.Topics))); err != nil {
+		return fmt.Errorf("could not write array length: %s", err)
+	}
+	for i := range m.Topics {
+		if err = SerializeMessageField(w, "string", &m.Topics[i]); err != nil {
+			return err
+		}
+	}
+	return
+}
+
+func (m *rosoutLog) Deserialize(r io.Reader) (err error) {
+	if err = DeserializeMessageField(r, "uint32", &m.Seq); err != nil {
+		return err
+	}
+	if err = DeserializeMessageField(r, "time", &m.Stamp); err != nil {
+		return err
+	}
+	if err = DeserializeMessageField(r, "string", &m.FrameID); err != nil {
+		return err
+	}
+	if err = DeserializeMessageField(r, "byte", &m.Level); err != nil {
+		return err
+	}
+	for _, s := range []*string{&m.Name, &m.Msg, &m.File, &m.Function} {
+		if err = DeserializeMessageField(r, "string", s); err != nil {
+			return err
+		}
+	}
+	if err = DeserializeMessageField(r, "uint32", &m.Line); err != nil {
+		return err
+	}
+	var size uint32
+	if err = binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return fmt.Errorf("cannot read array size for Topics: %s", err)
+	}
+	m.Topics = make([]string, int(size))
+	for i := range m.Topics {
+		if err = DeserializeMessageField(r, "string", &m.Topics[i]); err != nil {
+			return err
+		}
+	}
+	return
+}
+
+// rosoutLogger is a node's logger.  It writes messages like defaultLogger
+// and also publishes them on /rosout.
+type rosoutLogger struct {
+	*defaultLogger
+	nodeName  string
+	clock     *clock
+	msgChan   chan *rosoutLog
+	flushChan chan rosoutFlush
+	mutex     sync.Mutex
+	topics    []string
+}
+
+func newRosoutLogger(logger *defaultLogger, nodeName string, clock *clock) *rosoutLogger {
+	return &rosoutLogger{
+		defaultLogger: logger,
+		nodeName:      nodeName,
+		clock:         clock,
+		msgChan:       make(chan *rosoutLog, rosoutQueueSize),
+		flushChan:     make(chan rosoutFlush),
+	}
+}
+
+// Record a topic the node publishes, to be listed in its log messages.
+func (logger *rosoutLogger) addTopic(topic string) {
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+	logger.topics = append(logger.topics, topic)
+}
+
+// Forget a topic recorded by addTopic once the node stops publishing it.
+func (logger *rosoutLogger) removeTopic(topic string) {
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+	for i, t := range logger.topics {
+		if t == topic {
+			logger.topics = append(logger.topics[:i], logger.topics[i+1:]...)
+			return
+		}
+	}
+}
+
+// Queue msg for /rosout.  It must be called directly from the exported
+// logging method so the caller's location is reported.  Messages are dropped
+// rather than block the caller when the queue is full.
+func (logger *rosoutLogger) publish(level LogLevel, msg string) {
+	if !logger.enabled(level) {
+		return
+	}
+	m := &rosoutLog{
+		Stamp: logger.clock.now(),
+		Level: rosoutLevels[level],
+		Name:  logger.nodeName,
+		Msg:   msg,
+	}
+	if pc, file, line, ok := runtime.Caller(2); ok {
+		m.File = file
+		m.Line = uint32(line)
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			m.Function = fn.Name()
+		}
+	}
+	logger.mutex.Lock()
+	m.Topics = append([]string(nil), logger.topics...)
+	logger.mutex.Unlock()
+	select {
+	case logger.msgChan <- m:
+	default:
+	}
+}
+
+// rosoutFlush asks run to send the messages queued so far; done is closed
+// once they have been written, or ctx is done.
+type rosoutFlush struct {
+	ctx  context.Context
+	done chan struct{}
+}
+
+// Wait up to timeout for the messages queued so far to be written to the
+// subscribers of /rosout.
+func (logger *rosoutLogger) flush(timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	req := rosoutFlush{ctx, make(chan struct{})}
+	select {
+	case logger.flushChan <- req:
+	case <-ctx.Done():
+		return
+	}
+	select {
+	case <-req.done:
+	case <-ctx.Done():
+	}
+}
+
+// Publish queued messages with pub until ctx is canceled.  Messages logged
+// before anyone subscribes are kept, up to rosoutQueueSize of them, and sent
+// to the first subscriber to connect.
+func (logger *rosoutLogger) run(ctx context.Context, wg *sync.WaitGroup, pub *defaultPublisher, connectChan <-chan SingleSubscriberPublisher) {
+	defer wg.Done()
+	var pending []*rosoutLog
+	connected := false
+	var seq uint32
+	send := func(m *rosoutLog) {
+		seq++
+		m.Seq = seq
+		if connected {
+			pub.Publish(m)
+		} else {
+			pending = append(pending, m)
+			if len(pending) > rosoutQueueSize {
+				pending = pending[1:]
+			}
+		}
+	}
+	for {
+		select {
+		case m := <-logger.msgChan:
+			send(m)
+		case req := <-logger.flushChan:
+			for drained := false; !drained; {
+				select {
+				case m := <-logger.msgChan:
+					send(m)
+				default:
+					drained = true
+				}
+			}
+			if connected {
+				pub.flush(req.ctx)
+			}
+			close(req.done)
+		case ssp := <-connectChan:
+			if !connected {
+				for _, m := range pending {
+					ssp.Publish(m)
+				}
+				pending = nil
+				connected = true
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (logger *rosoutLogger) Debug(v ...interface{}) {
+	logger.defaultLogger.Debug(v...)
+	logger.publish(LogLevelDebug, fmt.Sprint(v...))
+}
+
+func (logger *rosoutLogger) Debugf(format string, v ...interface{}) {
+	logger.defaultLogger.Debugf(format, v...)
+	logger.publish(LogLevelDebug, fmt.Sprintf(format, v...))
+}
+
+func (logger *rosoutLogger) Info(v ...interface{}) {
+	logger.defaultLogger.Info(v...)
+	logger.publish(LogLevelInfo, fmt.Sprint(v...))
+}
+
+func (logger *rosoutLogger) Infof(format string, v ...interface{}) {
+	logger.defaultLogger.Infof(format, v...)
+	logger.publish(LogLevelInfo, fmt.Sprintf(format, v...))
+}
+
+func (logger *rosoutLogger) Warn(v ...interface{}) {
+	logger.defaultLogger.Warn(v...)
+	logger.publish(LogLevelWarn, fmt.Sprint(v...))
+}
+
+func (logger *rosoutLogger) Warnf(format string, v ...interface{}) {
+	logger.defaultLogger.Warnf(format, v...)
+	logger.publish(LogLevelWarn, fmt.Sprintf(format, v...))
+}
+
+func (logger *rosoutLogger) Error(v ...interface{}) {
+	logger.defaultLogger.Error(v...)
+	logger.publish(LogLevelError, fmt.Sprint(v...))
+}
+
+func (logger *rosoutLogger) Errorf(format string, v ...interface{}) {
+	logger.defaultLogger.Errorf(format, v...)
+	logger.publish(LogLevelError, fmt.Sprintf(format, v...))
+}
+
+func (logger *rosoutLogger) Fatal(v ...interface{}) {
+	logger.defaultLogger.Fatal(v...)
+	logger.publish(LogLevelFatal, fmt.Sprint(v...))
+}
+
+// Fatalf exits the process once the message has been written to the
+// subscribers of /rosout, or after rosoutFlushTimeout.
+func (logger *rosoutLogger) Fatalf(format string, v ...interface{}) {
+	logger.publish(LogLevelFatal, fmt.Sprintf(format, v...))
+	if logger.enabled(LogLevelFatal) {
+		logger.flush(rosoutFlushTimeout)
+	}
+	logger.defaultLogger.Fatalf(format, v...)
+}
+
+const rosoutLogText = `##
+## Severity level constants
+##
+byte DEBUG=1 #debug level
+byte INFO=2  #general level
+byte WARN=4  #warning level
+byte ERROR=8 #error level
+byte FATAL=16 #fatal/critical level
+##
+## Fields
+##
+Header header
+byte level
+string name # name of the node
+string msg # message 
+string file # file the message came from
+string function # function the message came from
+uint32 line # line the message came from
+string[] topics # topic names that the node publishes
+
+================================================================================
+MSG: std_msgs/Header
+# Standard metadata for higher-level stamped data types.
+# This is generally used to communicate timestamped data 
+# in a particular coordinate frame.
+# 
+# sequence ID: consecutively increasing ID 
+uint32 seq
+#Two-integer timestamp that is expressed as:
+# * stamp.sec: seconds (stamp_secs) since epoch (in Python the variable is called 'secs')
+# * stamp.nsec: nanoseconds since stamp_secs (in Python the variable is called 'nsecs')
+# time-handling sugar is provided by the client library
+time stamp
+#Frame this data is associated with
+# 0: no frame
+# 1: global frame
+string frame_id
+`
@@ -0,0 +1,104 @@
+package ros_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ppg/rosgo/master"
+	"github.com/ppg/rosgo/msgs/rosgraph_msgs"
+	"github.com/ppg/rosgo/msgs/std_msgs"
+	"github.com/ppg/rosgo/ros"
+)
+
+// The node's hand-written rosgraph_msgs/Log has to interoperate with the
+// generated one, so subscribe to /rosout with that.
+func TestRosout(t *testing.T) {
+	m, err := master.Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Shutdown()
+	t.Setenv("ROS_MASTER_URI", m.Uri())
+	t.Setenv("ROS_IP", "127.0.0.1")
+
+	talker, err := ros.NewNode("talker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer talker.Shutdown()
+	chatter, err := talker.NewPublisher("chatter", std_msgs.MsgString)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Logged before anyone subscribes, so it must be queued.
+	talker.Logger().Warn("hello rosout")
+
+	listener, err := ros.NewNode("listener")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Shutdown()
+	listener.Logger().SetSeverity(ros.LogLevelFatal)
+	received := make(chan *rosgraph_msgs.Log, 10)
+	definitions := make(chan string, 10)
+	if _, err := listener.NewSubscriber("/rosout", rosgraph_msgs.MsgLog, func(msg *rosgraph_msgs.Log, event ros.MessageEvent) {
+		received <- msg
+		definitions <- event.ConnectionHeader["message_definition"]
+	}); err != nil {
+		t.Fatal(err)
+	}
+	go listener.Spin()
+
+	var msg *rosgraph_msgs.Log
+	for msg == nil {
+		select {
+		case m := <-received:
+			if m.Name == "/talker" && m.Msg == "hello rosout" {
+				msg = m
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("log message not received")
+		}
+	}
+	if msg.Level != 4 {
+		t.Errorf("expected level WARN (4); got %d", msg.Level)
+	}
+	if !strings.HasSuffix(msg.File, "rosout_test.go") {
+		t.Errorf("expected file rosout_test.go; got %s", msg.File)
+	}
+	if !strings.HasSuffix(msg.Function, ".TestRosout") {
+		t.Errorf("expected function TestRosout; got %s", msg.Function)
+	}
+	if msg.Line == 0 {
+		t.Error("expected a line number")
+	}
+	if topics := strings.Join(msg.Topics, ","); topics != "/rosout,/chatter" {
+		t.Errorf("expected topics /rosout,/chatter; got %s", topics)
+	}
+	if definition := <-definitions; definition != rosgraph_msgs.MsgLog.Text() {
+		t.Errorf("unexpected message definition %q", definition)
+	}
+
+	// Topics the node no longer publishes aren't listed, however often
+	// they were advertised.
+	chatter.Shutdown()
+	if chatter, err = talker.NewPublisher("chatter", std_msgs.MsgString); err != nil {
+		t.Fatal(err)
+	}
+	chatter.Shutdown()
+	talker.Logger().Warn("goodbye rosout")
+	for msg = nil; msg == nil; {
+		select {
+		case m := <-received:
+			if m.Name == "/talker" && m.Msg == "goodbye rosout" {
+				msg = m
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("log message not received")
+		}
+	}
+	if topics := strings.Join(msg.Topics, ","); topics != "/rosout" {
+		t.Errorf("expected topics /rosout; got %s", topics)
+	}
+}
@@ -2,56 +2,193 @@ package ros
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/url"
+	"sync"
 	"time"
 )
 
 type defaultServiceClient struct {
-	logger    Logger
-	service   string
-	srvType   ServiceType
-	masterUri string
-	nodeId    string
+	logger     Logger
+	service    string
+	srvType    ServiceType
+	masterUri  string
+	nodeId     string
+	persistent bool
+	timeout    time.Duration // Per call, or 0 for none.
+	mutex      sync.Mutex    // Serializes calls over conn.
+	conn       net.Conn      // The persistent connection, nil until established.
+	closed     chan struct{}
+	closeOnce  sync.Once
+	onShutdown func()
 }
 
-func newDefaultServiceClient(logger Logger, nodeId string, masterUri string, service string, srvType ServiceType) *defaultServiceClient {
+// The failure a service handler reported, which leaves the connection
+// usable.
+type serviceHandlerError string
+
+func (e serviceHandlerError) Error() string {
+	return string(e)
+}
+
+func newDefaultServiceClient(logger Logger, nodeId string, masterUri string, service string, srvType ServiceType, options serviceClientOptions) *defaultServiceClient {
 	client := new(defaultServiceClient)
 	client.logger = logger
 	client.service = service
 	client.srvType = srvType
 	client.masterUri = masterUri
 	client.nodeId = nodeId
+	client.persistent = options.persistent
+	client.timeout = options.timeout
+	client.closed = make(chan struct{})
 	return client
 }
 
 func (c *defaultServiceClient) Call(srv Service) error {
-	logger := c.logger
+	return c.CallContext(context.Background(), srv)
+}
+
+func (c *defaultServiceClient) CallContext(ctx context.Context, srv Service) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	select {
+	case <-c.closed:
+		return fmt.Errorf("%w: client of service %s", ErrShutdown, c.service)
+	default:
+	}
+	if !c.persistent {
+		conn, err := c.connect(ctx)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		return c.call(ctx, conn, srv)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.conn == nil {
+		conn, err := c.connect(ctx)
+		if err != nil {
+			return err
+		}
+		c.conn = conn
+	}
+	err := c.call(ctx, c.conn, srv)
+	var handlerErr serviceHandlerError
+	if err != nil && !errors.As(err, &handlerErr) {
+		// Connect again on the next call.
+		c.conn.Close()
+		c.conn = nil
+	}
+	return err
+}
+
+// Look the service up, connect to it and exchange connection headers.
+func (c *defaultServiceClient) connect(ctx context.Context) (net.Conn, error) {
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.interruptible(ctx, conn, func() error { return c.handshake(conn, false) }); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
 
-	result, err := callRosApi(c.masterUri, "lookupService", c.nodeId, c.service)
+// Check that the service is registered and accepts connections, without
+// calling it.
+func (c *defaultServiceClient) probe(ctx context.Context) error {
+	conn, err := c.dial(ctx)
 	if err != nil {
 		return err
 	}
+	defer conn.Close()
+	return c.interruptible(ctx, conn, func() error { return c.handshake(conn, true) })
+}
+
+func (c *defaultServiceClient) Exists() bool {
+	ctx := context.Background()
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	return c.probe(ctx) == nil
+}
+
+// Look the service up and connect to it.
+func (c *defaultServiceClient) dial(ctx context.Context) (net.Conn, error) {
+	result, err := callMasterApiContext(ctx, c.masterUri, "lookupService", c.nodeId, c.service)
+	if err != nil {
+		var statusErr *apiStatusError
+		if errors.As(err, &statusErr) {
+			return nil, fmt.Errorf("%w: %s", ErrServiceNotFound, c.service)
+		}
+		return nil, err
+	}
 
 	serviceRawUrl, converted := result.(string)
 	if !converted {
-		return fmt.Errorf("Result of 'lookupService' is not a string")
+		return nil, fmt.Errorf("Result of 'lookupService' is not a string")
 	}
 	var serviceUrl *url.URL
 	serviceUrl, err = url.Parse(serviceRawUrl)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	var conn net.Conn
-	conn, err = net.Dial("tcp", serviceUrl.Host)
-	if err != nil {
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, "tcp", serviceUrl.Host)
+}
+
+// Send the request of srv over conn and read the response into it.
+func (c *defaultServiceClient) call(ctx context.Context, conn net.Conn, srv Service) error {
+	return c.interruptible(ctx, conn, func() error { return c.exchange(conn, srv) })
+}
+
+// Run step, which uses conn, closing conn to interrupt it if ctx is done or
+// the client shuts down.
+func (c *defaultServiceClient) interruptible(ctx context.Context, conn net.Conn, step func() error) error {
+	finished := make(chan struct{})
+	defer close(finished)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-c.closed:
+			conn.Close()
+		case <-finished:
+		}
+	}()
+	if err := step(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		select {
+		case <-c.closed:
+			return fmt.Errorf("%w: client of service %s", ErrShutdown, c.service)
+		default:
+		}
 		return err
 	}
+	return nil
+}
+
+// Exchange connection headers over conn.  A probe only checks that the
+// service accepts the connection, after which the server closes it.
+func (c *defaultServiceClient) handshake(conn net.Conn, probe bool) error {
+	logger := c.logger
+	var err error
 
 	// 1. Write connection header
 	var headers []header
@@ -61,17 +198,20 @@ func (c *defaultServiceClient) Call(srv Service) error {
 	headers = append(headers, header{"md5sum", md5sum})
 	headers = append(headers, header{"type", msgType})
 	headers = append(headers, header{"callerid", c.nodeId})
+	if probe {
+		headers = append(headers, header{"probe", "1"})
+	} else if c.persistent {
+		headers = append(headers, header{"persistent", "1"})
+	}
 	logger.Debug("TCPROS Connection Header")
 	for _, h := range headers {
 		logger.Debugf("  `%s` = `%s`", h.key, h.value)
 	}
-	conn.SetDeadline(time.Now().Add(10 * time.Millisecond))
 	if err = writeConnectionHeader(headers, conn); err != nil {
 		return err
 	}
 
 	// 2. Read reponse header
-	conn.SetDeadline(time.Now().Add(10 * time.Millisecond))
 	if resHeaders, readErr := readConnectionHeader(conn); readErr != nil {
 		return readErr
 	} else {
@@ -81,52 +221,57 @@ func (c *defaultServiceClient) Call(srv Service) error {
 			resHeaderMap[h.key] = h.value
 			logger.Debugf("  `%s` = `%s`", h.key, h.value)
 		}
-		if resHeaderMap["type"] != msgType || resHeaderMap["md5sum"] != md5sum {
-			logger.Fatalf("Incompatible message type!")
+		if message, ok := resHeaderMap["error"]; ok {
+			return fmt.Errorf("service %s rejected connection: %s", c.service, message)
+		}
+		if md5sum != "*" && (resHeaderMap["type"] != msgType || resHeaderMap["md5sum"] != md5sum) {
+			return fmt.Errorf("%w: expected %s [%s], service %s has %s [%s]",
+				ErrMD5Mismatch, msgType, md5sum, c.service, resHeaderMap["type"], resHeaderMap["md5sum"])
 		}
 		logger.Debug("Start receiving messages...")
 	}
+	return nil
+}
+
+// Send the request of srv over conn and read the response into it.
+func (c *defaultServiceClient) exchange(conn net.Conn, srv Service) error {
+	logger := c.logger
+	var err error
 
 	// 3. Send request
 	var buf bytes.Buffer
 	_ = srv.ReqMessage().Serialize(&buf)
 	reqMsg := buf.Bytes()
 	size := uint32(len(reqMsg))
-	conn.SetDeadline(time.Now().Add(10 * time.Millisecond))
 	if err = binary.Write(conn, binary.LittleEndian, size); err != nil {
 		return err
 	}
 	logger.Debug(len(reqMsg))
-	conn.SetDeadline(time.Now().Add(10 * time.Millisecond))
 	if _, err = conn.Write(reqMsg); err != nil {
 		return err
 	}
 
 	// 4. Read OK byte
 	var ok byte
-	conn.SetDeadline(time.Now().Add(10 * time.Millisecond))
 	if err = binary.Read(conn, binary.LittleEndian, &ok); err != nil {
 		return err
 	} else {
 		if ok == 0 {
 			var size uint32
-			conn.SetDeadline(time.Now().Add(10 * time.Millisecond))
 			if err = binary.Read(conn, binary.LittleEndian, &size); err != nil {
 				return err
 			} else {
 				errMsg := make([]byte, int(size))
-				conn.SetDeadline(time.Now().Add(10 * time.Millisecond))
 				if _, err = io.ReadFull(conn, errMsg); err != nil {
 					return err
 				} else {
-					return errors.New(string(errMsg))
+					return serviceHandlerError(errMsg)
 				}
 			}
 		}
 	}
 
 	// 5. Receive response
-	conn.SetDeadline(time.Now().Add(10 * time.Millisecond))
 	//logger.Debug("Reading message size...")
 	var msgSize uint32
 	if err = binary.Read(conn, binary.LittleEndian, &msgSize); err != nil {
@@ -145,4 +290,28 @@ func (c *defaultServiceClient) Call(srv Service) error {
 	return nil
 }
 
-func (*defaultServiceClient) Shutdown() {}
+// Close the persistent connection, interrupting any call in progress.
+// Calls after this fail with ErrShutdown.
+func (c *defaultServiceClient) Shutdown() {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		if c.conn != nil {
+			c.conn.Close()
+			c.conn = nil
+		}
+		if c.onShutdown != nil {
+			c.onShutdown()
+		}
+	})
+}
+
+// Stands in for the type of a service which is only probed, matching any.
+type probeServiceType struct{}
+
+func (probeServiceType) MD5Sum() string            { return "*" }
+func (probeServiceType) Name() string              { return "*" }
+func (probeServiceType) RequestType() MessageType  { return nil }
+func (probeServiceType) ResponseType() MessageType { return nil }
+func (probeServiceType) NewService() Service       { return nil }
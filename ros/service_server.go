@@ -8,6 +8,7 @@ import (
 	"io"
 	"net"
 	"reflect"
+	"sync"
 	"time"
 )
 
@@ -30,79 +31,103 @@ type defaultServiceServer struct {
 	service          string
 	srvType          ServiceType
 	handler          interface{}
-	listener         *net.TCPListener
+	jobChan          chan func()
 	sessions         *list.List
+	sessionChan      chan *remoteClientSession
 	shutdownChan     chan struct{}
 	sessionErrorChan chan error
+	timeout          time.Duration // Per request, or 0 for none.
+	shutdownOnce     sync.Once
+	done             chan struct{} // Closed when the server goroutine exits.
+	threadsDone      chan struct{} // Closed when the server's own threads exit; nil without them.
 }
 
-func newDefaultServiceServer(node *defaultNode, service string, srvType ServiceType, handler interface{}) *defaultServiceServer {
+func newDefaultServiceServer(node *defaultNode, service string, srvType ServiceType, handler interface{}, options serviceServerOptions) (*defaultServiceServer, error) {
 	logger := node.logger
 	server := new(defaultServiceServer)
-	if listener, err := listenRandomPort("127.0.0.1", 10); err != nil {
-		panic(err)
-	} else {
-		if tcpListener, ok := listener.(*net.TCPListener); ok {
-			server.listener = tcpListener
-		} else {
-			panic(fmt.Errorf("Server listener is not TCPListener"))
-		}
-	}
 	server.node = node
 	server.service = service
 	server.srvType = srvType
 	server.handler = handler
+	server.timeout = options.timeout
 	server.sessions = list.New()
+	server.sessionChan = make(chan *remoteClientSession, 10)
 	server.shutdownChan = make(chan struct{}, 10)
 	server.sessionErrorChan = make(chan error, 10)
-	address := fmt.Sprintf("rosrpc://%s", server.listener.Addr().String())
+	server.done = make(chan struct{})
+	address := fmt.Sprintf("rosrpc://%s", node.tcprosAddress())
 	logger.Debugf("ServiceServer listen %s", address)
-	_, err := callRosApi(node.masterUri, "registerService",
+	_, err := callMasterApiContext(node.ctx, node.masterUri, "registerService",
 		node.qualifiedName,
 		service,
 		address,
 		node.xmlrpcUri)
 	if err != nil {
-		logger.Errorf("Failed to register service %s", service)
-		server.listener.Close()
-		return nil
+		return nil, fmt.Errorf("failed to register service %s: %w", service, err)
 	}
+	if options.threads > 0 {
+		// The server's own pool runs the handler until the server exits.
+		queue := NewCallbackQueue()
+		server.jobChan = queue.jobChan
+		server.threadsDone = make(chan struct{})
+		node.waitGroup.Add(1)
+		go func() {
+			defer node.waitGroup.Done()
+			defer close(server.threadsDone)
+			spinThreads(queue, options.threads, server.done)
+		}()
+	} else {
+		server.jobChan = node.callbackQueue(options.callbackQueue).jobChan
+	}
+	node.waitGroup.Add(1)
 	go server.start()
-	return server
+	return server, nil
 }
 
+// Unregister the service and close its connections, returning once the
+// server, and any handler running on its own threads, has finished.  Further
+// calls do nothing.
 func (s *defaultServiceServer) Shutdown() {
-	s.shutdownChan <- struct{}{}
+	s.shutdownOnce.Do(func() {
+		s.shutdownChan <- struct{}{}
+		<-s.done
+		if s.threadsDone != nil {
+			<-s.threadsDone
+		}
+		s.node.mutex.Lock()
+		defer s.node.mutex.Unlock()
+		if s.node.servers[s.service] == s {
+			delete(s.node.servers, s.service)
+		}
+	})
+}
+
+// Hand over a TCPROS connection, whose header has already been read by the
+// node, to the service server goroutine.
+func (s *defaultServiceServer) addSession(conn net.Conn, headerMap map[string]string) {
+	select {
+	case s.sessionChan <- newRemoteClientSession(s, conn, headerMap):
+	case <-s.done:
+		rejectTCPROS(conn, fmt.Sprintf("service %s is no longer provided", s.service))
+	}
 }
 
 // event loop
 func (s *defaultServiceServer) start() {
 	logger := s.node.logger
-	logger.Debugf("service server '%s' start listen %s.", s.service, s.listener.Addr().String())
-	s.node.waitGroup.Add(1)
+	logger.Debugf("service server '%s' started.", s.service)
 	defer func() {
 		logger.Debug("defaultServiceServer.start exit")
+		close(s.done)
 		s.node.waitGroup.Done()
 	}()
 
 	for {
-		//logger.Debug("defaultServiceServer.start loop");
-		s.listener.SetDeadline(time.Now().Add(1 * time.Millisecond))
-		if conn, err := s.listener.Accept(); err != nil {
-			opError, ok := err.(*net.OpError)
-			if !ok || !opError.Timeout() {
-				logger.Debugf("s.listner.Accept() failed")
-				return
-			}
-		} else {
-			logger.Debugf("Connected from %s", conn.RemoteAddr().String())
-			session := newRemoteClientSession(s, conn)
+		select {
+		case session := <-s.sessionChan:
+			logger.Debugf("Connected from %s", session.conn.RemoteAddr().String())
 			s.sessions.PushBack(session)
 			go session.start()
-		}
-
-		timeoutChan := time.After(1 * time.Millisecond)
-		select {
 		case err := <-s.sessionErrorChan:
 			logger.Errorf("session error: %v", err)
 			if sessionError, ok := err.(*remoteClientSessionError); ok {
@@ -116,44 +141,46 @@ func (s *defaultServiceServer) start() {
 			}
 		case <-s.shutdownChan:
 			logger.Debug("defaultServiceServer.start Receive shutdownChan")
-			s.listener.Close()
-			logger.Debug("defaultServiceServer.start closed listener")
-			_, err := callRosApi(s.node.masterUri, "unregisterService",
+			_, err := callMasterApi(s.node.masterUri, "unregisterService",
 				s.node.qualifiedName, s.service, s.node.xmlrpcUri)
 			if err != nil {
-				logger.Warn("Failed unregisterService(%s): %v", s.service, err)
+				logger.Warnf("Failed unregisterService(%s): %v", s.service, err)
 			}
-			logger.Debug("Called unregisterService(%s)", s.service)
+			logger.Debugf("Called unregisterService(%s)", s.service)
 			for e := s.sessions.Front(); e != nil; e = e.Next() {
-				session := e.Value.(*remoteClientSession)
-				session.quitChan <- struct{}{}
+				// Closing the connection ends the session.
+				e.Value.(*remoteClientSession).conn.Close()
 			}
 			s.sessions.Init() // Clear all sessions
 			logger.Debug("defaultServiceServer.start session cleared")
 			return
-		case <-timeoutChan:
-			break
 		}
 	}
 }
 
 type remoteClientSession struct {
-	server       *defaultServiceServer
-	conn         net.Conn
-	quitChan     chan struct{}
-	responseChan chan []byte
-	errorChan    chan error
+	server    *defaultServiceServer
+	conn      net.Conn
+	headerMap map[string]string
 }
 
-func newRemoteClientSession(s *defaultServiceServer, conn net.Conn) *remoteClientSession {
+func newRemoteClientSession(s *defaultServiceServer, conn net.Conn, headerMap map[string]string) *remoteClientSession {
 	session := new(remoteClientSession)
 	session.server = s
 	session.conn = conn
-	session.responseChan = make(chan []byte)
-	session.errorChan = make(chan error)
+	session.headerMap = headerMap
 	return session
 }
 
+// Allow the server's timeout for the next reads and writes of conn.
+func (s *remoteClientSession) setDeadline() {
+	if s.server.timeout > 0 {
+		s.conn.SetDeadline(time.Now().Add(s.server.timeout))
+	} else {
+		s.conn.SetDeadline(time.Time{})
+	}
+}
+
 func (s *remoteClientSession) start() {
 	logger := s.server.node.logger
 	conn := s.conn
@@ -165,40 +192,33 @@ func (s *remoteClientSession) start() {
 	logger.Debugf("remoteClientSession.start '%s'", s.server.service)
 	defer func() {
 		logger.Debug("remoteClientSession.start exit")
+		conn.Close()
 	}()
 	defer func() {
+		var e error
 		if err := recover(); err != nil {
-			if e, ok := err.(error); ok {
-				s.server.sessionErrorChan <- &remoteClientSessionError{s, e}
-			} else {
+			var ok bool
+			if e, ok = err.(error); !ok {
 				e = fmt.Errorf("Unkonwn error value")
-				s.server.sessionErrorChan <- &remoteClientSessionError{s, e}
 			}
 		} else {
-			e := fmt.Errorf("Normal exit")
-			s.server.sessionErrorChan <- &remoteClientSessionError{s, e}
+			e = fmt.Errorf("Normal exit")
+		}
+		select {
+		case s.server.sessionErrorChan <- &remoteClientSessionError{s, e}:
+		case <-s.server.done:
 		}
 	}()
 
-	// 1. Read request header
-	conn.SetDeadline(time.Now().Add(10 * time.Millisecond))
-	if resHeaders, readErr := readConnectionHeader(conn); readErr != nil {
-		panic(readErr)
-	} else {
-		logger.Debug("TCPROS Connection Header:")
-		resHeaderMap := make(map[string]string)
-		for _, h := range resHeaders {
-			resHeaderMap[h.key] = h.value
-			logger.Debugf("  `%s` = `%s`", h.key, h.value)
-		}
-		if probe, ok := resHeaderMap["probe"]; ok && probe == "1" {
-			logger.Debug("TCPROS header 'probe' detected. Session closed")
-			return
-		}
-		if resHeaderMap["service"] != service ||
-			resHeaderMap["md5sum"] != md5sum {
-			logger.Fatalf("Incompatible message type!")
-		}
+	// 1. Check request header
+	reqHeaderMap := s.headerMap
+	if reqHeaderMap["service"] != service ||
+		(reqHeaderMap["md5sum"] != md5sum && reqHeaderMap["md5sum"] != "*") {
+		err := fmt.Errorf("%w: client %s wants %s [%s], which serves %s [%s]",
+			ErrMD5Mismatch, reqHeaderMap["callerid"], service, reqHeaderMap["md5sum"], srvType, md5sum)
+		writeConnectionHeader([]header{{"error", err.Error()}}, conn)
+		s.server.node.reportError(err)
+		return
 	}
 
 	// 2. Write response header
@@ -211,32 +231,72 @@ func (s *remoteClientSession) start() {
 	for _, h := range headers {
 		logger.Debugf("  `%s` = `%s`", h.key, h.value)
 	}
-	conn.SetDeadline(time.Now().Add(10 * time.Millisecond))
+	s.setDeadline()
 	if err = writeConnectionHeader(headers, conn); err != nil {
 		panic(err)
 	}
-
-	// 3. Read request
-	logger.Debug("Reading message size...")
-	var msgSize uint32
-	conn.SetDeadline(time.Now().Add(10 * time.Millisecond))
-	if err = binary.Read(conn, binary.LittleEndian, &msgSize); err != nil {
-		panic(err)
+	if reqHeaderMap["probe"] == "1" {
+		logger.Debug("TCPROS header 'probe' detected. Session closed")
+		return
 	}
-	logger.Debugf("  %d", msgSize)
-	resBuffer := make([]byte, int(msgSize))
-	logger.Debug("Reading message body...")
-	conn.SetDeadline(time.Now().Add(10 * time.Millisecond))
-	if _, err = io.ReadFull(conn, resBuffer); err != nil {
-		panic(err)
+
+	// A persistent client sends any number of requests over the connection.
+	persistent := reqHeaderMap["persistent"] == "1"
+	for {
+		// 3. Read request
+		logger.Debug("Reading message size...")
+		var msgSize uint32
+		if persistent {
+			// The client may take as long as it likes to make its next call.
+			conn.SetDeadline(time.Time{})
+		} else {
+			s.setDeadline()
+		}
+		if err = binary.Read(conn, binary.LittleEndian, &msgSize); err != nil {
+			if persistent && err == io.EOF {
+				logger.Debug("Persistent client closed the connection")
+				return
+			}
+			panic(err)
+		}
+		logger.Debugf("  %d", msgSize)
+		resBuffer := make([]byte, int(msgSize))
+		logger.Debug("Reading message body...")
+		s.setDeadline()
+		if _, err = io.ReadFull(conn, resBuffer); err != nil {
+			panic(err)
+		}
+
+		if !s.serve(resBuffer) || !persistent {
+			return
+		}
 	}
+}
 
-	s.server.node.jobChan <- func() {
+// Run the handler on a request and write its response, returning false if
+// the server shut down first.
+func (s *remoteClientSession) serve(resBuffer []byte) bool {
+	logger := s.server.node.logger
+	conn := s.conn
+	var err error
+
+	// The handler's result mustn't block the callback queue if the session
+	// has given up waiting for it.
+	responseChan := make(chan []byte, 1)
+	errorChan := make(chan error, 1)
+	abandoned := make(chan struct{})
+	job := func() {
+		select {
+		case <-abandoned:
+			// The caller has been told the request timed out.
+			return
+		default:
+		}
 		srv := s.server.srvType.NewService()
 		reader := bytes.NewReader(resBuffer)
-		err = srv.ReqMessage().Deserialize(reader)
-		if err != nil {
-			s.errorChan <- err
+		if err := srv.ReqMessage().Deserialize(reader); err != nil {
+			errorChan <- err
+			return
 		}
 		args := []reflect.Value{reflect.ValueOf(srv)}
 		fun := reflect.ValueOf(s.server.handler)
@@ -244,7 +304,7 @@ func (s *remoteClientSession) start() {
 
 		if len(results) != 1 {
 			logger.Debug("Service callback return type must be 'error'")
-			s.errorChan <- err
+			errorChan <- fmt.Errorf("Service handler has invalid signature")
 			return
 		}
 		result := results[0]
@@ -252,57 +312,61 @@ func (s *remoteClientSession) start() {
 			logger.Debug("Service callback success")
 			var buf bytes.Buffer
 			_ = srv.ResMessage().Serialize(&buf)
-			s.responseChan <- buf.Bytes()
+			responseChan <- buf.Bytes()
 		} else {
 			logger.Debug("Service callback failure")
-			var ok bool
-			if err, ok = result.Interface().(error); ok {
-				s.errorChan <- err
+			if err, ok := result.Interface().(error); ok {
+				errorChan <- err
 			} else {
-				s.errorChan <- fmt.Errorf("Service handler has invalid signature")
+				errorChan <- fmt.Errorf("Service handler has invalid signature")
 			}
 		}
 	}
 
-	timeoutChan := time.After(1000 * time.Millisecond)
+	var timeoutChan <-chan time.Time
+	if s.server.timeout > 0 {
+		timer := time.NewTimer(s.server.timeout)
+		defer timer.Stop()
+		timeoutChan = timer.C
+	}
+	var resMsg []byte
+	var handlerErr error
 	select {
-	case resMsg := <-s.responseChan:
-		// 4. Write OK byte
-		var ok byte = 1
-		conn.SetDeadline(time.Now().Add(10 * time.Millisecond))
-		if err = binary.Write(conn, binary.LittleEndian, &ok); err != nil {
-			panic(err)
-		}
-		// 5. Write response
-		logger.Debug(len(resMsg))
-		size := uint32(len(resMsg))
-		conn.SetDeadline(time.Now().Add(10 * time.Millisecond))
-		if err = binary.Write(conn, binary.LittleEndian, size); err != nil {
-			panic(err)
-		}
-		conn.SetDeadline(time.Now().Add(10 * time.Millisecond))
-		if _, err = conn.Write(resMsg); err != nil {
-			panic(err)
-		}
-	case err = <-s.errorChan:
-		logger.Error(err)
-		// 4. Write OK byte
-		var ok byte = 0
-		conn.SetDeadline(time.Now().Add(10 * time.Millisecond))
-		if err = binary.Write(conn, binary.LittleEndian, &ok); err != nil {
-			panic(err)
-		}
-		errMsg := err.Error()
-		size := uint32(len(errMsg))
-		conn.SetDeadline(time.Now().Add(10 * time.Millisecond))
-		if err = binary.Write(conn, binary.LittleEndian, size); err != nil {
-			panic(err)
-		}
-		conn.SetDeadline(time.Now().Add(10 * time.Millisecond))
-		if _, err = conn.Write([]byte(errMsg)); err != nil {
-			panic(err)
+	case s.server.jobChan <- job:
+		select {
+		case resMsg = <-responseChan:
+		case handlerErr = <-errorChan:
+		case <-timeoutChan:
+			handlerErr = fmt.Errorf("service %s timed out after %v", s.server.service, s.server.timeout)
+		case <-s.server.done:
+			return false
 		}
 	case <-timeoutChan:
-		panic(fmt.Errorf("service callback timeout"))
+		handlerErr = fmt.Errorf("service %s timed out after %v waiting for its handler", s.server.service, s.server.timeout)
+	case <-s.server.done:
+		return false
+	}
+	close(abandoned)
+
+	// 4. Write OK byte, which is 0 if an error message follows
+	var ok byte = 1
+	if handlerErr != nil {
+		logger.Error(handlerErr)
+		ok = 0
+		resMsg = []byte(handlerErr.Error())
+	}
+	s.setDeadline()
+	if err = binary.Write(conn, binary.LittleEndian, &ok); err != nil {
+		panic(err)
+	}
+	// 5. Write response
+	logger.Debug(len(resMsg))
+	size := uint32(len(resMsg))
+	if err = binary.Write(conn, binary.LittleEndian, size); err != nil {
+		panic(err)
+	}
+	if _, err = conn.Write(resMsg); err != nil {
+		panic(err)
 	}
+	return true
 }
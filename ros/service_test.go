@@ -0,0 +1,287 @@
+package ros
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testServiceType is an echo service built from testMessageType for tests
+// that can't import generated services.
+type testServiceType struct{}
+
+func (testServiceType) MD5Sum() string            { return "8f2aa6a8fa8a1fed5ba5a4ba1f5c6cf8" }
+func (testServiceType) Name() string              { return "rosgo_test/Echo" }
+func (testServiceType) RequestType() MessageType  { return testMessageType{} }
+func (testServiceType) ResponseType() MessageType { return testMessageType{} }
+func (testServiceType) NewService() Service       { return new(testService) }
+
+type testService struct {
+	Request  testMessage
+	Response testMessage
+}
+
+func (s *testService) ReqMessage() Message { return &s.Request }
+func (s *testService) ResMessage() Message { return &s.Response }
+
+func echoService(srv *testService) error {
+	switch srv.Request.Data {
+	case "fail":
+		return errors.New("asked to fail")
+	case "slow":
+		time.Sleep(300 * time.Millisecond)
+	case "slower":
+		time.Sleep(1200 * time.Millisecond)
+	}
+	srv.Response.Data = srv.Request.Data
+	return nil
+}
+
+func callEcho(client ServiceClient, data string) (string, error) {
+	srv := &testService{Request: testMessage{data}}
+	err := client.Call(srv)
+	return srv.Response.Data, err
+}
+
+func TestPersistentServiceClient(t *testing.T) {
+	m, args := startTestMaster(t)
+	defer m.Shutdown()
+	server := newTestNode(t, "server", args)
+	defer server.Shutdown()
+	go server.Spin()
+	if _, err := server.NewServiceServer("/echo", testServiceType{}, echoService); err != nil {
+		t.Fatal(err)
+	}
+	node := newTestNode(t, "client", args)
+	defer node.Shutdown()
+	c, err := node.NewServiceClient("/echo", testServiceType{}, Persistent())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := c.(*defaultServiceClient)
+
+	// Calls, including failed ones, share the connection.
+	if data, err := callEcho(client, "one"); err != nil || data != "one" {
+		t.Fatalf("expected one; got %q, %v", data, err)
+	}
+	conn := client.conn
+	if _, err := callEcho(client, "fail"); err == nil || err.Error() != "asked to fail" {
+		t.Errorf("expected the handler's error; got %v", err)
+	}
+	if data, err := callEcho(client, "two"); err != nil || data != "two" {
+		t.Errorf("expected two; got %q, %v", data, err)
+	}
+	if client.conn != conn {
+		t.Error("expected the connection to be reused")
+	}
+
+	// Replacing the server drops the connection, which a later call
+	// establishes again.
+	if _, err := server.NewServiceServer("/echo", testServiceType{}, echoService); err != nil {
+		t.Fatal(err)
+	}
+	data, err := callEcho(client, "three")
+	if err != nil {
+		data, err = callEcho(client, "three")
+	}
+	if err != nil || data != "three" {
+		t.Errorf("expected three after reconnecting; got %q, %v", data, err)
+	}
+
+	client.Shutdown()
+	if client.conn != nil {
+		t.Error("expected the connection to be closed")
+	}
+	if _, err := callEcho(client, "four"); !errors.Is(err, ErrShutdown) {
+		t.Errorf("expected ErrShutdown; got %v", err)
+	}
+	if _, ok := node.clients[client]; ok {
+		t.Error("expected the client to be forgotten by the node")
+	}
+}
+
+func TestServiceClient(t *testing.T) {
+	m, args := startTestMaster(t)
+	defer m.Shutdown()
+	server := newTestNode(t, "server", args)
+	defer server.Shutdown()
+	go server.Spin()
+	if _, err := server.NewServiceServer("/echo", testServiceType{}, echoService); err != nil {
+		t.Fatal(err)
+	}
+	node := newTestNode(t, "client", args)
+	defer node.Shutdown()
+	client, err := node.NewServiceClient("/echo", testServiceType{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"one", "two"} {
+		if data, err := callEcho(client, expected); err != nil || data != expected {
+			t.Errorf("expected %s; got %q, %v", expected, data, err)
+		}
+	}
+	if _, err := callEcho(client, "fail"); err == nil || err.Error() != "asked to fail" {
+		t.Errorf("expected the handler's error; got %v", err)
+	}
+}
+
+// A server with its own handler threads answers without spinning, and tells
+// the caller when its handler takes too long.
+func TestServiceTimeout(t *testing.T) {
+	m, args := startTestMaster(t)
+	defer m.Shutdown()
+	server := newTestNode(t, "server", args)
+	defer server.Shutdown()
+	if _, err := server.NewServiceServer("/echo", testServiceType{}, echoService,
+		ServiceThreads(2), ServiceTimeout(50*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	node := newTestNode(t, "client", args)
+	defer node.Shutdown()
+	c, err := node.NewServiceClient("/echo", testServiceType{}, Persistent())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := c.(*defaultServiceClient)
+
+	if data, err := callEcho(client, "one"); err != nil || data != "one" {
+		t.Fatalf("expected one; got %q, %v", data, err)
+	}
+	conn := client.conn
+	if _, err := callEcho(client, "slow"); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected a timeout response; got %v", err)
+	}
+	if data, err := callEcho(client, "two"); err != nil || data != "two" {
+		t.Errorf("expected two; got %q, %v", data, err)
+	}
+	if client.conn != conn {
+		t.Error("expected the connection to survive the timeout")
+	}
+}
+
+// Servers have no timeout by default, so handlers may take as long as they
+// need.
+func TestServiceNoDefaultTimeout(t *testing.T) {
+	m, args := startTestMaster(t)
+	defer m.Shutdown()
+	server := newTestNode(t, "server", args)
+	defer server.Shutdown()
+	if _, err := server.NewServiceServer("/echo", testServiceType{}, echoService, ServiceThreads(1)); err != nil {
+		t.Fatal(err)
+	}
+	node := newTestNode(t, "client", args)
+	defer node.Shutdown()
+	client, err := node.NewServiceClient("/echo", testServiceType{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := callEcho(client, "slower"); err != nil || data != "slower" {
+		t.Errorf("expected slower; got %q, %v", data, err)
+	}
+}
+
+// Shutting down a server with its own handler threads waits for a handler
+// which is running.
+func TestServiceShutdownWaitsForHandler(t *testing.T) {
+	m, args := startTestMaster(t)
+	defer m.Shutdown()
+	server := newTestNode(t, "server", args)
+	defer server.Shutdown()
+	started := make(chan struct{})
+	var finished int32
+	s, err := server.NewServiceServer("/echo", testServiceType{}, func(srv *testService) error {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		atomic.StoreInt32(&finished, 1)
+		return nil
+	}, ServiceThreads(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := newTestNode(t, "client", args)
+	defer node.Shutdown()
+	client, err := node.NewServiceClient("/echo", testServiceType{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go callEcho(client, "one")
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler not called")
+	}
+	s.Shutdown()
+	if atomic.LoadInt32(&finished) == 0 {
+		t.Error("Shutdown returned while the handler was running")
+	}
+}
+
+func TestServiceCallTimeout(t *testing.T) {
+	m, args := startTestMaster(t)
+	defer m.Shutdown()
+	server := newTestNode(t, "server", args)
+	defer server.Shutdown()
+	if _, err := server.NewServiceServer("/echo", testServiceType{}, echoService, ServiceThreads(2)); err != nil {
+		t.Fatal(err)
+	}
+	node := newTestNode(t, "client", args)
+	defer node.Shutdown()
+	client, err := node.NewServiceClient("/echo", testServiceType{}, CallTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if _, err := callEcho(client, "slow"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded; got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("call returned after %v", elapsed)
+	}
+	if data, err := callEcho(client, "one"); err != nil || data != "one" {
+		t.Errorf("expected one; got %q, %v", data, err)
+	}
+}
+
+func TestWaitForService(t *testing.T) {
+	m, args := startTestMaster(t)
+	defer m.Shutdown()
+	node := newTestNode(t, "client", args)
+	defer node.Shutdown()
+	client, err := node.NewServiceClient("/echo", testServiceType{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := node.WaitForService("/echo", 50*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded; got %v", err)
+	}
+	if client.Exists() {
+		t.Error("expected the service not to exist")
+	}
+
+	server := newTestNode(t, "server", args)
+	defer server.Shutdown()
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		if _, err := server.NewServiceServer("/echo", testServiceType{}, echoService); err != nil {
+			t.Error(err)
+		}
+	}()
+	if err := node.WaitForService("/echo", 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if !client.Exists() {
+		t.Error("expected the service to exist")
+	}
+	// Probing doesn't call the handler, which nothing spins for.
+	other, err := node.NewServiceClient("/echo", probeServiceType{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !other.Exists() {
+		t.Error("expected the service to accept a wildcard type")
+	}
+}
@@ -0,0 +1,143 @@
+package ros
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Number of callbacks a CallbackQueue holds before the subscribers, timers
+// and service servers feeding it wait.
+const callbackQueueSize = 100
+
+// CallbackQueue holds the callbacks of the subscribers, timers and service
+// servers attached to it until they are run by a spinner, or by
+// CallAvailable or CallOne.  Each node has a default queue, run by its Spin;
+// others can be created and attached with SubscriberCallbackQueue,
+// TimerCallbackQueue and ServiceCallbackQueue.
+type CallbackQueue struct {
+	jobChan chan func()
+}
+
+func NewCallbackQueue() *CallbackQueue {
+	return &CallbackQueue{jobChan: make(chan func(), callbackQueueSize)}
+}
+
+// Run the callbacks which are already queued, without waiting for more.
+func (q *CallbackQueue) CallAvailable() {
+	for n := len(q.jobChan); n > 0; n-- {
+		select {
+		case job := <-q.jobChan:
+			job()
+		default:
+			return
+		}
+	}
+}
+
+// Run the next callback, waiting up to timeout for one.  Returns whether a
+// callback ran.
+func (q *CallbackQueue) CallOne(timeout time.Duration) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case job := <-q.jobChan:
+		job()
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// Run callbacks as they arrive until done is closed.
+func (q *CallbackQueue) spin(done <-chan struct{}) {
+	for {
+		select {
+		case job := <-q.jobChan:
+			job()
+		case <-done:
+			return
+		}
+	}
+}
+
+// Run callbacks from queue on threads goroutines until done is closed, and
+// return when they have all finished.  Zero or fewer threads means one per
+// CPU.
+func spinThreads(queue *CallbackQueue, threads int, done <-chan struct{}) {
+	if threads <= 0 {
+		threads = runtime.NumCPU()
+	}
+	var wg sync.WaitGroup
+	wg.Add(threads)
+	for i := 0; i < threads; i++ {
+		go func() {
+			defer wg.Done()
+			queue.spin(done)
+		}()
+	}
+	wg.Wait()
+}
+
+// AsyncSpinner runs the callbacks of a queue on a number of goroutines in
+// the background.  Callbacks of one subscription still run one at a time and
+// in order unless it allows concurrent callbacks.
+type AsyncSpinner struct {
+	queue   *CallbackQueue
+	threads int
+
+	mutex    sync.Mutex
+	stopChan chan struct{}
+	finished chan struct{}
+}
+
+// Create a spinner for queue using threads goroutines.  Zero or fewer
+// threads means one per CPU.
+func NewAsyncSpinner(threads int, queue *CallbackQueue) *AsyncSpinner {
+	return &AsyncSpinner{queue: queue, threads: threads}
+}
+
+// Start running callbacks.  Starting a running spinner does nothing.
+func (s *AsyncSpinner) Start() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.stopChan != nil {
+		return
+	}
+	s.stopChan = make(chan struct{})
+	s.finished = make(chan struct{})
+	go func(stopChan, finished chan struct{}) {
+		spinThreads(s.queue, s.threads, stopChan)
+		close(finished)
+	}(s.stopChan, s.finished)
+}
+
+// Stop running callbacks, waiting for those already running to return.
+func (s *AsyncSpinner) Stop() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.stopChan == nil {
+		return
+	}
+	close(s.stopChan)
+	<-s.finished
+	s.stopChan = nil
+}
+
+// Return a channel which is closed once ctx is done or done is closed, and a
+// function to call when the channel is no longer needed.
+func mergeDone(ctx context.Context, done <-chan struct{}) (<-chan struct{}, func()) {
+	merged := make(chan struct{})
+	released := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		case <-released:
+			return
+		}
+		close(merged)
+	}()
+	return merged, func() { close(released) }
+}
@@ -0,0 +1,139 @@
+package ros
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCallbackQueue(t *testing.T) {
+	queue := NewCallbackQueue()
+	if queue.CallOne(10 * time.Millisecond) {
+		t.Error("expected no callback to run from an empty queue")
+	}
+	var calls []int
+	for i := 0; i < 3; i++ {
+		i := i
+		queue.jobChan <- func() { calls = append(calls, i) }
+	}
+	if !queue.CallOne(time.Second) || len(calls) != 1 {
+		t.Errorf("expected one callback to run; got %v", calls)
+	}
+	queue.CallAvailable()
+	if len(calls) != 3 || calls[1] != 1 || calls[2] != 2 {
+		t.Errorf("expected callbacks [0 1 2]; got %v", calls)
+	}
+}
+
+func TestAsyncSpinnerRunsCallbacksConcurrently(t *testing.T) {
+	queue := NewCallbackQueue()
+	spinner := NewAsyncSpinner(2, queue)
+	spinner.Start()
+	defer spinner.Stop()
+
+	// Each callback waits for the other, so they only finish if they run
+	// at the same time.
+	var started sync.WaitGroup
+	started.Add(2)
+	done := make(chan struct{}, 2)
+	for i := 0; i < 2; i++ {
+		queue.jobChan <- func() {
+			started.Done()
+			started.Wait()
+			done <- struct{}{}
+		}
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("callbacks did not run concurrently")
+		}
+	}
+
+	spinner.Stop()
+	queue.jobChan <- func() { t.Error("callback ran after the spinner stopped") }
+	time.Sleep(20 * time.Millisecond)
+	if len(queue.jobChan) != 1 {
+		t.Error("expected the callback to stay queued")
+	}
+}
+
+// Send n numbered messages to a subscriber whose callbacks run on four
+// goroutines, and return the order they were received in and the most
+// callbacks which ran at once.
+func runSubscriberCallbacks(t *testing.T, n int, options subscriberOptions) ([]int, int32) {
+	var mutex sync.Mutex
+	var received []int
+	var running, maxRunning int32
+	callback := func(msg *testMessage) {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		i, _ := strconv.Atoi(msg.Data)
+		mutex.Lock()
+		received = append(received, i)
+		mutex.Unlock()
+		atomic.AddInt32(&running, -1)
+	}
+	queue := NewCallbackQueue()
+	spinner := NewAsyncSpinner(4, queue)
+	spinner.Start()
+	defer spinner.Stop()
+
+	var wg sync.WaitGroup
+	sub := newDefaultSubscriber("/chatter", testMessageType{}, callback, options)
+	wg.Add(1)
+	go sub.start(context.Background(), &wg, "/test_node", "", "", queue.jobChan, NewDefaultLogger(), func(error) {})
+	defer sub.Shutdown()
+	for i := 0; i < n; i++ {
+		var buf bytes.Buffer
+		msg := testMessage{strconv.Itoa(i)}
+		msg.Serialize(&buf)
+		sub.msgChan <- messageEvent{bytes: buf.Bytes()}
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mutex.Lock()
+		count := len(received)
+		mutex.Unlock()
+		if count == n {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d callbacks; got %d", n, count)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	return received, atomic.LoadInt32(&maxRunning)
+}
+
+func TestSubscriberCallbacksInOrder(t *testing.T) {
+	received, maxRunning := runSubscriberCallbacks(t, 50, subscriberOptions{})
+	if maxRunning != 1 {
+		t.Errorf("expected callbacks to run one at a time; %d ran at once", maxRunning)
+	}
+	for i, data := range received {
+		if data != i {
+			t.Fatalf("expected callbacks in order; got %v", received)
+		}
+	}
+}
+
+func TestSubscriberConcurrentCallbacks(t *testing.T) {
+	_, maxRunning := runSubscriberCallbacks(t, 50, subscriberOptions{concurrent: true})
+	if maxRunning < 2 {
+		t.Errorf("expected callbacks to run concurrently; at most %d ran at once", maxRunning)
+	}
+}
@@ -0,0 +1,345 @@
+package ros
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// Topic subscribers publish connection statistics on while
+// /enable_statistics is set.
+const statisticsTopic = "/statistics"
+
+// topicStatisticsType is rosgraph_msgs/TopicStatistics.  The generated
+// message can't be used here because the msgs packages import this one.
+type topicStatisticsType struct{}
+
+func (topicStatisticsType) Text() string {
+	return topicStatisticsText
+}
+
+func (topicStatisticsType) MD5Sum() string {
+	return "10152ed868c5097a5e2e4a89d7daa710"
+}
+
+func (topicStatisticsType) Name() string {
+	return "rosgraph_msgs/TopicStatistics"
+}
+
+func (topicStatisticsType) NewMessage() Message {
+	return new(topicStatistics)
+}
+
+type topicStatistics struct {
+	Topic          string
+	NodePub        string
+	NodeSub        string
+	WindowStart    Time
+	WindowStop     Time
+	DeliveredMsgs  int32
+	DroppedMsgs    int32
+	Traffic        int32
+	PeriodMean     Duration
+	PeriodStddev   Duration
+	PeriodMax      Duration
+	StampAgeMean   Duration
+	StampAgeStddev Duration
+	StampAgeMax    Duration
+}
+
+func (m *topicStatistics) Serialize(w io.Writer) (err error) {
+	for _, s := range []*string{&m.Topic, &m.NodePub, &m.NodeSub} {
+		if err = SerializeMessageField(w, "string", s); err != nil {
+			return err
+		}
+	}
+	for _, t := range []*Time{&m.WindowStart, &m.WindowStop} {
+		if err = SerializeMessageField(w, "time", t); err != nil {
+			return err
+		}
+	}
+	for _, i := range []*int32{&m.DeliveredMsgs, &m.DroppedMsgs, &m.Traffic} {
+		if err = SerializeMessageField(w, "int32", i); err != nil {
+			return err
+		}
+	}
+	for _, d := range m.durations() {
+		if err = SerializeMessageField(w, "duration", d); err != nil {
+			return err
+		}
+	}
+	return
+}
+
+func (m *topicStatistics) Deserialize(r io.Reader) (err error) {
+	for _, s := range []*string{&m.Topic, &m.NodePub, &m.NodeSub} {
+		if err = DeserializeMessageField(r, "string", s); err != nil {
+			return err
+		}
+	}
+	for _, t := range []*Time{&m.WindowStart, &m.WindowStop} {
+		if err = DeserializeMessageField(r, "time", t); err != nil {
+			return err
+		}
+	}
+	for _, i := range []*int32{&m.DeliveredMsgs, &m.DroppedMsgs, &m.Traffic} {
+		if err = DeserializeMessageField(r, "int32", i); err != nil {
+			return err
+		}
+	}
+	for _, d := range m.durations() {
+		if err = DeserializeMessageField(r, "duration", d); err != nil {
+			return err
+		}
+	}
+	return
+}
+
+func (m *topicStatistics) durations() []*Duration {
+	return []*Duration{&m.PeriodMean, &m.PeriodStddev, &m.PeriodMax,
+		&m.StampAgeMean, &m.StampAgeStddev, &m.StampAgeMax}
+}
+
+// statisticsWindow holds the /statistics_window_* parameters.  Statistics
+// are gathered over a window which is halved when it holds more than
+// maxElements messages and doubled when it holds fewer than minElements,
+// staying between minSize and maxSize.
+type statisticsWindow struct {
+	minElements int
+	maxElements int
+	minSize     time.Duration
+	maxSize     time.Duration
+}
+
+// Defaults of the /statistics_window_* parameters, as in roscpp.
+var defaultStatisticsWindow = statisticsWindow{
+	minElements: 10,
+	maxElements: 100,
+	minSize:     4 * time.Second,
+	maxSize:     64 * time.Second,
+}
+
+// Whether the window can grow and shrink between its limits, which takes a
+// positive size and limits in order.
+func (w statisticsWindow) valid() bool {
+	return w.minSize > 0 && w.minSize <= w.maxSize &&
+		w.minElements >= 0 && w.minElements <= w.maxElements
+}
+
+// Whether a message definition starts with a std_msgs/Header, as the ones
+// whose stamp age is measured do.
+func messageHasHeader(text string) bool {
+	for _, line := range strings.Split(text, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if strings.Contains(line, "=") {
+			// A constant, which isn't serialized.
+			continue
+		}
+		return len(fields) == 2 && fields[1] == "header" &&
+			(fields[0] == "Header" || fields[0] == "std_msgs/Header")
+	}
+	return false
+}
+
+// Stamp of a serialized message starting with a std_msgs/Header, which is
+// its seq followed by the stamp.
+func headerStamp(msg []byte) (Time, bool) {
+	if len(msg) < 12 {
+		return Time{}, false
+	}
+	return NewTime(binary.LittleEndian.Uint32(msg[4:8]), binary.LittleEndian.Uint32(msg[8:12])), true
+}
+
+// durationStats accumulates the mean, standard deviation and maximum of a
+// series of durations.
+type durationStats struct {
+	n     int
+	sum   float64 // Seconds.
+	sumSq float64
+	max   time.Duration
+}
+
+func (s *durationStats) add(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	sec := d.Seconds()
+	s.n++
+	s.sum += sec
+	s.sumSq += sec * sec
+	if d > s.max {
+		s.max = d
+	}
+}
+
+// The mean, standard deviation and maximum, all zero without any values.
+func (s *durationStats) result() (mean Duration, stddev Duration, max Duration) {
+	if s.n == 0 {
+		return
+	}
+	m := s.sum / float64(s.n)
+	mean.FromSec(m)
+	stddev.FromSec(math.Sqrt(math.Max(s.sumSq/float64(s.n)-m*m, 0)))
+	max.FromNSec(uint64(s.max))
+	return
+}
+
+// Statistics of the messages from one publisher in the current window.
+type publisherStatistics struct {
+	delivered int
+	dropped   int
+	traffic   int
+	last      Time // Arrival of the previous message, or zero.
+	period    durationStats
+	stampAge  durationStats
+}
+
+// subscriberStatistics gathers the statistics of a subscriber's connections
+// and publishes them on /statistics each time a window ends.  It is used by
+// the subscriber goroutine only.
+type subscriberStatistics struct {
+	pub         Publisher
+	clock       *clock
+	topic       string
+	nodeSub     string
+	hasHeader   bool
+	limits      statisticsWindow
+	window      time.Duration
+	windowStart Time
+	publishers  map[string]*publisherStatistics // By caller ID.
+}
+
+func newSubscriberStatistics(pub Publisher, clock *clock, topic string, nodeSub string, msgType MessageType, limits statisticsWindow) *subscriberStatistics {
+	return &subscriberStatistics{
+		pub:         pub,
+		clock:       clock,
+		topic:       topic,
+		nodeSub:     nodeSub,
+		hasHeader:   messageHasHeader(msgType.Text()),
+		limits:      limits,
+		window:      limits.minSize,
+		windowStart: clock.now(),
+		publishers:  make(map[string]*publisherStatistics),
+	}
+}
+
+func (s *subscriberStatistics) publisher(callerID string) *publisherStatistics {
+	stats, ok := s.publishers[callerID]
+	if !ok {
+		stats = new(publisherStatistics)
+		s.publishers[callerID] = stats
+	}
+	return stats
+}
+
+// Account for a message that arrived, publishing the statistics if it ends
+// the window.
+func (s *subscriberStatistics) received(msgEvent messageEvent) {
+	now := s.clock.now()
+	stats := s.publisher(msgEvent.event.PublisherName)
+	stats.delivered++
+	stats.traffic += len(msgEvent.bytes)
+	if !stats.last.IsZero() {
+		stats.period.add(since(now, stats.last))
+	}
+	stats.last = now
+	if s.hasHeader {
+		if stamp, ok := headerStamp(msgEvent.bytes); ok && !stamp.IsZero() {
+			stats.stampAge.add(since(now, stamp))
+		}
+	}
+	if now.Cmp(s.windowStart) < 0 {
+		// Simulated time went back.
+		s.windowStart = now
+	} else if since(now, s.windowStart) >= s.window {
+		s.publish(now)
+	}
+}
+
+// Account for a message dropped from the subscriber's queue.
+func (s *subscriberStatistics) dropped(msgEvent messageEvent) {
+	s.publisher(msgEvent.event.PublisherName).dropped++
+}
+
+// Publish the statistics of the window ending at now, start the next one and
+// resize it to hold between the minimum and maximum number of messages.
+func (s *subscriberStatistics) publish(now Time) {
+	most := 0
+	for callerID, stats := range s.publishers {
+		if stats.delivered == 0 && stats.dropped == 0 {
+			// Nothing has come from it for a whole window.
+			delete(s.publishers, callerID)
+			continue
+		}
+		msg := &topicStatistics{
+			Topic:         s.topic,
+			NodePub:       callerID,
+			NodeSub:       s.nodeSub,
+			WindowStart:   s.windowStart,
+			WindowStop:    now,
+			DeliveredMsgs: int32(stats.delivered),
+			DroppedMsgs:   int32(stats.dropped),
+			Traffic:       int32(stats.traffic),
+		}
+		msg.PeriodMean, msg.PeriodStddev, msg.PeriodMax = stats.period.result()
+		msg.StampAgeMean, msg.StampAgeStddev, msg.StampAgeMax = stats.stampAge.result()
+		s.pub.Publish(msg)
+		if stats.delivered > most {
+			most = stats.delivered
+		}
+		s.publishers[callerID] = &publisherStatistics{last: stats.last}
+	}
+	s.windowStart = now
+	if most > s.limits.maxElements && s.window/2 >= s.limits.minSize {
+		s.window /= 2
+	} else if most < s.limits.minElements && s.window*2 <= s.limits.maxSize {
+		s.window *= 2
+	}
+}
+
+// Time from earlier to later.
+func since(later Time, earlier Time) time.Duration {
+	return time.Duration(int64(later.ToNSec()) - int64(earlier.ToNSec()))
+}
+
+const topicStatisticsText = `# name of the topic
+string topic
+
+# node id of the publisher
+string node_pub
+
+# node id of the subscriber
+string node_sub
+
+# the statistics apply to this time window
+time window_start
+time window_stop
+
+# number of messages delivered during the window
+int32 delivered_msgs
+# numbers of messages dropped during the window
+int32 dropped_msgs
+
+# traffic during the window, in bytes
+int32 traffic
+
+# mean/stddev/max period between two messages
+duration period_mean
+duration period_stddev
+duration period_max
+
+# mean/stddev/max age of the message based on the
+# timestamp in the message header. In case the
+# message does not have a header, it will be 0.
+duration stamp_age_mean
+duration stamp_age_stddev
+duration stamp_age_max
+`
@@ -0,0 +1,169 @@
+package ros
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+// testStampedType is a message with a std_msgs/Header, whose stamp age
+// statistics are measured.
+type testStampedType struct{}
+
+func (testStampedType) Text() string        { return "# A stamped string\nHeader header\nstring data\n" }
+func (testStampedType) MD5Sum() string      { return "c99a9440709e4d4a9716d55b8270d5e7" }
+func (testStampedType) Name() string        { return "test_msgs/StampedString" }
+func (testStampedType) NewMessage() Message { return new(testStamped) }
+
+type testStamped struct {
+	Seq     uint32
+	Stamp   Time
+	FrameID string
+	Data    string
+}
+
+func (m *testStamped) Serialize(w io.Writer) (err error) {
+	if err = SerializeMessageField(w, "uint32", &m.Seq); err != nil {
+		return err
+	}
+	if err = SerializeMessageField(w, "time", &m.Stamp); err != nil {
+		return err
+	}
+	if err = SerializeMessageField(w, "string", &m.FrameID); err != nil {
+		return err
+	}
+	return SerializeMessageField(w, "string", &m.Data)
+}
+
+func (m *testStamped) Deserialize(r io.Reader) (err error) {
+	if err = DeserializeMessageField(r, "uint32", &m.Seq); err != nil {
+		return err
+	}
+	if err = DeserializeMessageField(r, "time", &m.Stamp); err != nil {
+		return err
+	}
+	if err = DeserializeMessageField(r, "string", &m.FrameID); err != nil {
+		return err
+	}
+	return DeserializeMessageField(r, "string", &m.Data)
+}
+
+func TestMessageHasHeader(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected bool
+	}{
+		{"Header header\nstring data\n", true},
+		{"# comment\n\nstd_msgs/Header header # stamped\n", true},
+		{"uint8 OK=0\nHeader header\n", true},
+		{"string data\nHeader header\n", false},
+		{"Header stamp\n", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		if result := messageHasHeader(test.text); result != test.expected {
+			t.Errorf("messageHasHeader(%q): expected %v; got %v", test.text, test.expected, result)
+		}
+	}
+}
+
+func TestTopicStatistics(t *testing.T) {
+	m, args := startTestMaster(t)
+	defer m.Shutdown()
+	talker := newTestNode(t, "talker", args)
+	defer talker.Shutdown()
+	for key, value := range map[string]interface{}{
+		"/enable_statistics":          true,
+		"/statistics_window_min_size": 0.2,
+		"/statistics_window_max_size": 0.2,
+	} {
+		if err := talker.SetParam(key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	listener := newTestNode(t, "listener", args)
+	defer listener.Shutdown()
+
+	statistics := make(chan *topicStatistics, 10)
+	if _, err := talker.NewSubscriber(statisticsTopic, topicStatisticsType{}, func(msg *topicStatistics) {
+		select {
+		case statistics <- msg:
+		default:
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	go talker.Spin()
+	if _, err := listener.NewSubscriber("stamped", testStampedType{}, func(msg *testStamped) {}); err != nil {
+		t.Fatal(err)
+	}
+	go listener.Spin()
+	pub, err := talker.NewPublisher("stamped", testStampedType{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	age := NewDuration(1, 0)
+	deadline := time.After(5 * time.Second)
+	for {
+		now := Now()
+		pub.Publish(&testStamped{Stamp: now.Sub(age), Data: "stamped"})
+		select {
+		case msg := <-statistics:
+			if msg.Topic != "/stamped" || msg.NodePub != "/talker" || msg.NodeSub != "/listener" {
+				t.Errorf("unexpected statistics for %s from %s to %s", msg.Topic, msg.NodePub, msg.NodeSub)
+			}
+			if msg.DeliveredMsgs == 0 || msg.Traffic == 0 {
+				t.Errorf("expected deliveries and traffic; got %d messages, %d bytes", msg.DeliveredMsgs, msg.Traffic)
+			}
+			if msg.WindowStop.Cmp(msg.WindowStart) <= 0 {
+				t.Errorf("expected a window; got %v to %v", msg.WindowStart, msg.WindowStop)
+			}
+			if msg.DeliveredMsgs > 1 && msg.PeriodMean.IsZero() {
+				t.Error("expected a period between messages")
+			}
+			if sec := msg.StampAgeMean.ToSec(); sec < 0.9 || sec > 2 {
+				t.Errorf("expected stamp age of about 1s; got %vs", sec)
+			}
+			return
+		case <-time.After(20 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("no statistics published")
+		}
+	}
+}
+
+func TestInvalidStatisticsWindow(t *testing.T) {
+	m, args := startTestMaster(t)
+	defer m.Shutdown()
+	setter := newTestNode(t, "setter", args)
+	defer setter.Shutdown()
+	tests := []map[string]interface{}{
+		{"/statistics_window_min_size": 0.0},
+		{"/statistics_window_min_size": 2.0, "/statistics_window_max_size": 1.0},
+		{"/statistics_window_min_elements": int32(50), "/statistics_window_max_elements": int32(5)},
+	}
+	for _, params := range tests {
+		if err := setter.SetParam("/enable_statistics", true); err != nil {
+			t.Fatal(err)
+		}
+		for key, value := range params {
+			if err := setter.SetParam(key, value); err != nil {
+				t.Fatal(err)
+			}
+		}
+		node := newTestNode(t, "listener", args)
+		if node.statistics == nil {
+			t.Errorf("%v: expected statistics to be published", params)
+		}
+		if node.statsWindow != defaultStatisticsWindow {
+			t.Errorf("%v: expected the default window; got %+v", params, node.statsWindow)
+		}
+		node.Shutdown()
+		for key := range params {
+			if err := setter.DeleteParam(key); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package ros
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Directions of connections in the Slave API's getBusInfo.
+const (
+	directionOutbound = "o"
+	directionInbound  = "i"
+)
+
+// Source of connection IDs, which only have to be unique within a node.
+var lastConnectionID int32
+
+// connection holds the counters of one TCPROS connection of a publisher or
+// subscriber.  The counters are updated by the goroutine using the
+// connection and read by whoever reports on it.
+type connection struct {
+	bytes       uint64 // Accessed atomically; keep 64-bit aligned.
+	messages    uint64 // Accessed atomically.
+	dropped     uint64 // Accessed atomically.
+	connected   int32  // Accessed atomically; 1 while connected.
+	id          int32
+	destination string
+	direction   string
+	topic       string
+	transport   atomic.Value   // Its name, if not TCPROS.
+	set         *connectionSet // Set before the connection is used.
+}
+
+func newConnection(destination string, direction string, topic string) *connection {
+	return &connection{
+		id:          atomic.AddInt32(&lastConnectionID, 1),
+		destination: destination,
+		direction:   direction,
+		topic:       topic,
+	}
+}
+
+// Count a message of size bytes sent or received.  TCPROS adds a 4-byte
+// length to each message.
+func (c *connection) count(size int) {
+	atomic.AddUint64(&c.bytes, uint64(size)+4)
+	atomic.AddUint64(&c.messages, 1)
+}
+
+func (c *connection) drop() {
+	atomic.AddUint64(&c.dropped, 1)
+}
+
+func (c *connection) setConnected(connected bool) {
+	var value int32
+	if connected {
+		value = 1
+	}
+	if atomic.SwapInt32(&c.connected, value) != value && c.set != nil {
+		c.set.changed()
+	}
+}
+
+func (c *connection) setTransport(name string) {
+	c.transport.Store(name)
+}
+
+func (c *connection) stats() ConnectionStats {
+	// Messages are counted after their bytes, so loading them first never
+	// reports fewer bytes than messages account for.
+	messages := atomic.LoadUint64(&c.messages)
+	transport, ok := c.transport.Load().(string)
+	if !ok {
+		transport = "TCPROS"
+	}
+	return ConnectionStats{
+		ID:          c.id,
+		Destination: c.destination,
+		Direction:   c.direction,
+		Transport:   transport,
+		Topic:       c.topic,
+		Bytes:       atomic.LoadUint64(&c.bytes),
+		Messages:    messages,
+		Dropped:     atomic.LoadUint64(&c.dropped),
+		Connected:   atomic.LoadInt32(&c.connected) == 1,
+	}
+}
+
+// connectionSet is the connections of one publisher or subscriber, which are
+// added and removed by its goroutine and read from others.
+type connectionSet struct {
+	mutex       sync.Mutex
+	connections map[*connection]struct{}
+	changes     chan struct{} // Closed at the next change, if anyone waits.
+}
+
+func (s *connectionSet) add(c *connection) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.connections == nil {
+		s.connections = make(map[*connection]struct{})
+	}
+	s.connections[c] = struct{}{}
+	c.set = s
+	s.notifyLocked()
+}
+
+func (s *connectionSet) remove(c *connection) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.connections, c)
+	s.notifyLocked()
+}
+
+// A channel closed when a connection is next added or removed, or connects
+// or disconnects.
+func (s *connectionSet) wait() <-chan struct{} {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.changes == nil {
+		s.changes = make(chan struct{})
+	}
+	return s.changes
+}
+
+func (s *connectionSet) changed() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.notifyLocked()
+}
+
+func (s *connectionSet) notifyLocked() {
+	if s.changes != nil {
+		close(s.changes)
+		s.changes = nil
+	}
+}
+
+// Number of connections which are connected.
+func (s *connectionSet) numConnected() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	n := 0
+	for c := range s.connections {
+		if atomic.LoadInt32(&c.connected) == 1 {
+			n++
+		}
+	}
+	return n
+}
+
+// Stats of every connection, ordered by ID.
+func (s *connectionSet) stats() []ConnectionStats {
+	s.mutex.Lock()
+	stats := make([]ConnectionStats, 0, len(s.connections))
+	for c := range s.connections {
+		stats = append(stats, c.stats())
+	}
+	s.mutex.Unlock()
+	sort.Slice(stats, func(i, j int) bool { return stats[i].ID < stats[j].ID })
+	return stats
+}
+
+// Clamp a counter to the 32-bit integers XML-RPC carries.
+func xmlrpcInt(n uint64) int32 {
+	if n > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return int32(n)
+}
@@ -1,24 +1,39 @@
 package ros
 
 import (
+	"bufio"
 	"bytes"
-	"encoding/binary"
+	"context"
+	"errors"
 	"fmt"
-	"io"
 	"net"
 	"reflect"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type messageEvent struct {
 	bytes []byte
 	event MessageEvent
+	link  *connection // The connection the message arrived on.
 }
 
-// The subscription object runs in own goroutine (startSubscription).
-// Do not access any properties from other goroutine.
+// A received message waiting in the callback queue together with the
+// callbacks registered when it arrived.
+type pendingMessage struct {
+	msgEvent  messageEvent
+	callbacks []interface{}
+}
+
+// The subscription object runs in own goroutine (start), which owns its
+// state.  Other goroutines go through its channels and the fields marked as
+// shared.
 type defaultSubscriber struct {
+	dropped          uint64 // Accessed atomically; keep 64-bit aligned.
+	jobPending       int32  // Accessed atomically; set while a callback job is queued or draining the queue.
+	numPublishers    int32  // Accessed atomically.
 	topic            string
 	msgType          MessageType
 	pubList          []string
@@ -26,123 +41,221 @@ type defaultSubscriber struct {
 	msgChan          chan messageEvent
 	callbacks        []interface{}
 	addCallbackChan  chan interface{}
+	waiters          map[chan messageEvent]struct{} // Each wants the next message once.
+	addWaiterChan    chan chan messageEvent
+	removeWaiterChan chan chan messageEvent
 	shutdownChan     chan struct{}
-	connections      map[string]chan struct{}
+	jobStarted       chan struct{} // Signaled when a concurrent job takes its message.
+	connections      map[string]context.CancelFunc
+	links            map[string]*connection
+	stats            connectionSet
 	disconnectedChan chan string
+	queue            *boundedQueue
+	concurrent       bool // Whether callbacks for several messages may run at once.
+	hints            TransportHints
+	hostname         string                // Where publishers send UDPROS datagrams.
+	statistics       *subscriberStatistics // Or nil if not enabled.
+	shutdownOnce     sync.Once
+	registered       chan struct{} // Closed once registration with the master has finished.
+	done             chan struct{} // Closed when the subscriber goroutine exits.
+	onShutdown       func()        // Called once the subscriber has shut down.
+	// Guarded by the node's mutex: the WaitForMessage calls using the
+	// subscription, and whether anything else subscribed through it.
+	waiting int
+	hasUser bool
 }
 
-func newDefaultSubscriber(topic string, msgType MessageType, callback interface{}) *defaultSubscriber {
+func newDefaultSubscriber(topic string, msgType MessageType, callback interface{}, options subscriberOptions) *defaultSubscriber {
 	sub := new(defaultSubscriber)
 	sub.topic = topic
 	sub.msgType = msgType
 	sub.msgChan = make(chan messageEvent, 10)
 	sub.pubListChan = make(chan []string, 10)
 	sub.addCallbackChan = make(chan interface{}, 10)
+	sub.waiters = make(map[chan messageEvent]struct{})
+	sub.addWaiterChan = make(chan chan messageEvent)
+	sub.removeWaiterChan = make(chan chan messageEvent)
 	sub.shutdownChan = make(chan struct{}, 10)
+	sub.jobStarted = make(chan struct{}, 1)
 	sub.disconnectedChan = make(chan string, 10)
-	sub.connections = make(map[string]chan struct{})
-	sub.callbacks = []interface{}{callback}
+	sub.registered = make(chan struct{})
+	sub.done = make(chan struct{})
+	sub.connections = make(map[string]context.CancelFunc)
+	sub.links = make(map[string]*connection)
+	if callback != nil {
+		sub.callbacks = []interface{}{callback}
+	}
+	sub.queue = newBoundedQueue(options.queueSize)
+	sub.concurrent = options.concurrent
+	sub.hints = options.hints
 	return sub
 }
 
-func (sub *defaultSubscriber) start(wg *sync.WaitGroup, nodeId string, nodeApiUri string, masterUri string, jobChan chan func(), logger Logger) {
+// Run the subscriber until it is shut down.  The caller must have added it
+// to wg.
+func (sub *defaultSubscriber) start(ctx context.Context, wg *sync.WaitGroup, nodeId string, nodeApiUri string, masterUri string, jobChan chan func(), logger Logger, reportError func(error)) {
 	logger.Debugf("Subscriber goroutine for %s started.", sub.topic)
-	wg.Add(1)
 	defer wg.Done()
+	defer close(sub.done)
 	defer func() {
 		logger.Debug("defaultSubscriber.start exit")
 	}()
+	// Sending the callback job is one of the cases below, so that messages
+	// keep being received, and the oldest dropped, and the subscriber can
+	// shut down while the callback queue is full.
+	var job func()
+	var pendingJobChan chan func()
 	for {
 		logger.Debug("Loop")
+		if pendingJobChan == nil {
+			if job = sub.nextJob(logger); job != nil {
+				pendingJobChan = jobChan
+			}
+		}
 		select {
+		case pendingJobChan <- job:
+			pendingJobChan = nil
+			logger.Debug("Callback job enqueued.")
+		case <-sub.jobStarted:
+			// Another job may be needed for the remaining messages.
 		case list := <-sub.pubListChan:
 			logger.Debug("Receive pubListChan")
 			deadPubs := setDifference(sub.pubList, list)
 			newPubs := setDifference(list, sub.pubList)
 			sub.pubList = list
+			atomic.StoreInt32(&sub.numPublishers, int32(len(list)))
 
 			for _, pub := range deadPubs {
-				quitChan := sub.connections[pub]
-				quitChan <- struct{}{}
-				delete(sub.connections, pub)
+				sub.disconnect(pub)
 			}
 			for _, pub := range newPubs {
-				protocols := []interface{}{[]interface{}{"TCPROS"}}
-				result, err := callRosApi(pub, "requestTopic", nodeId, sub.topic, protocols)
-				if err != nil {
-					logger.Fatal(err)
-					continue
-				}
-				protocolParams := result.([]interface{})
-				for _, x := range protocolParams {
-					logger.Debug(x)
-				}
-				name := protocolParams[0].(string)
-				if name == "TCPROS" {
-					addr := protocolParams[1].(string)
-					port := protocolParams[2].(int32)
-					uri := fmt.Sprintf("%s:%d", addr, port)
-					quitChan := make(chan struct{}, 10)
-					sub.connections[pub] = quitChan
-					go startRemotePublisherConn(logger,
-						uri, sub.topic,
-						sub.msgType.MD5Sum(),
-						sub.msgType.Name(), nodeId,
-						sub.msgChan,
-						quitChan,
-						sub.disconnectedChan)
-				} else {
-					logger.Warnf("rosgo Not support protocol '%s'", name)
-				}
+				connCtx, cancel := context.WithCancel(ctx)
+				sub.connections[pub] = cancel
+				link := newConnection(pub, directionInbound, sub.topic)
+				sub.links[pub] = link
+				sub.stats.add(link)
+				go startRemotePublisherConn(connCtx, logger, reportError,
+					pub, sub.topic,
+					sub.msgType.MD5Sum(),
+					sub.msgType.Name(), nodeId, sub.hints, sub.hostname, link,
+					sub.msgChan,
+					sub.disconnectedChan)
 			}
 		case callback := <-sub.addCallbackChan:
 			logger.Debug("Receive addCallbackChan")
 			sub.callbacks = append(sub.callbacks, callback)
+		case waiter := <-sub.addWaiterChan:
+			sub.waiters[waiter] = struct{}{}
+		case waiter := <-sub.removeWaiterChan:
+			delete(sub.waiters, waiter)
 		case msgEvent := <-sub.msgChan:
-			// Pop received message then bind callbacks and enqueue to the job channle.
+			if sub.statistics != nil {
+				sub.statistics.received(msgEvent)
+			}
+			// Waiters' channels have room for the one message they get.
+			for waiter := range sub.waiters {
+				waiter <- msgEvent
+				delete(sub.waiters, waiter)
+			}
+			if len(sub.callbacks) == 0 {
+				continue
+			}
+			// Bind callbacks to the received message and put it in the
+			// subscriber's queue, for the next job to pick up.
 			logger.Debug("Receive msgChan")
 			callbacks := make([]interface{}, len(sub.callbacks))
 			copy(callbacks, sub.callbacks)
-			jobChan <- func() {
-				m := sub.msgType.NewMessage()
-				reader := bytes.NewReader(msgEvent.bytes)
-				if err := m.Deserialize(reader); err != nil {
-					logger.Error(err)
+			if dropped, ok := sub.queue.pushDropping(pendingMessage{msgEvent, callbacks}); ok {
+				atomic.AddUint64(&sub.dropped, 1)
+				droppedEvent := dropped.(pendingMessage).msgEvent
+				if droppedEvent.link != nil {
+					droppedEvent.link.drop()
 				}
-				args := []reflect.Value{reflect.ValueOf(m), reflect.ValueOf(msgEvent.event)}
-				for _, callback := range callbacks {
-					fun := reflect.ValueOf(callback)
-					num_args_needed := fun.Type().NumIn()
-					if num_args_needed <= 2 {
-						fun.Call(args[0:num_args_needed])
-					}
+				if sub.statistics != nil {
+					sub.statistics.dropped(droppedEvent)
 				}
+				logger.Debugf("Callback queue for %s is full; dropped oldest message.", sub.topic)
 			}
-			logger.Debug("Callback job enqueued.")
 		case pubUri := <-sub.disconnectedChan:
 			logger.Debugf("Connection to %s was disconnected.", pubUri)
-			delete(sub.connections, pubUri)
+			sub.disconnect(pubUri)
 		case <-sub.shutdownChan:
 			// Shutdown subscription goroutine
 			logger.Debug("Receive shutdownChan")
-			for _, closeChan := range sub.connections {
-				closeChan <- struct{}{}
-				close(closeChan)
-			}
-			_, err := callRosApi(masterUri, "unregisterSubscriber", nodeId, sub.topic, nodeApiUri)
-			if err != nil {
-				logger.Warn(err)
-			}
+			sub.stop(nodeId, nodeApiUri, masterUri, logger)
+			return
+		case <-ctx.Done():
+			logger.Debug("Node shut down")
+			sub.stop(nodeId, nodeApiUri, masterUri, logger)
 			return
 		}
 	}
 }
 
-func startRemotePublisherConn(logger Logger,
+// Close the subscriber's connections and unregister it.
+func (sub *defaultSubscriber) stop(nodeId string, nodeApiUri string, masterUri string, logger Logger) {
+	for pubUri := range sub.connections {
+		sub.disconnect(pubUri)
+	}
+	_, err := callMasterApi(masterUri, "unregisterSubscriber", nodeId, sub.topic, nodeApiUri)
+	if err != nil {
+		logger.Warn(err)
+	}
+}
+
+// Job to put in the callback queue for the queued messages, or nil if none
+// is needed.  Only one job per subscriber waits in the job channel at a
+// time.  It drains the queue when it runs, unless callbacks may run
+// concurrently, in which case it takes one message and lets the next job be
+// queued.
+func (sub *defaultSubscriber) nextJob(logger Logger) func() {
+	if sub.queue.len() == 0 || !atomic.CompareAndSwapInt32(&sub.jobPending, 0, 1) {
+		return nil
+	}
+	if !sub.concurrent {
+		return func() {
+			sub.runCallbacks(logger)
+		}
+	}
+	return func() {
+		item, ok := sub.queue.pop()
+		atomic.StoreInt32(&sub.jobPending, 0)
+		select {
+		case sub.jobStarted <- struct{}{}:
+		default:
+		}
+		if ok {
+			sub.invoke(item.(pendingMessage), logger)
+		}
+	}
+}
+
+// Close the connection to the publisher at pubUri.
+func (sub *defaultSubscriber) disconnect(pubUri string) {
+	if cancel, ok := sub.connections[pubUri]; ok {
+		cancel()
+		delete(sub.connections, pubUri)
+	}
+	if link, ok := sub.links[pubUri]; ok {
+		sub.stats.remove(link)
+		delete(sub.links, pubUri)
+	}
+}
+
+// Delays between attempts to reconnect to a publisher.
+const (
+	minReconnectInterval = 100 * time.Millisecond
+	maxReconnectInterval = 5 * time.Second
+)
+
+// Receive messages from the publisher whose slave API is at pubUri until ctx
+// is canceled.  Failed connections are passed to reportError and retried
+// with exponential backoff.  A publisher with an incompatible message type
+// is given up on and reported on disconnectedChan.
+func startRemotePublisherConn(ctx context.Context, logger Logger, reportError func(error),
 	pubUri string, topic string, md5sum string,
-	msgType string, nodeId string,
+	msgType string, nodeId string, hints TransportHints, hostname string, link *connection,
 	msgChan chan messageEvent,
-	quitChan chan struct{},
 	disconnectedChan chan string) {
 	logger.Debug("startRemotePublisherConn()")
 
@@ -150,31 +263,146 @@ func startRemotePublisherConn(logger Logger,
 		logger.Debug("startRemotePublisherConn() exit")
 	}()
 
-	conn, err := net.Dial("tcp", pubUri)
-	if err != nil {
-		logger.Fatalf("Failed to connect %s!", pubUri)
+	interval := minReconnectInterval
+	for {
+		received, err := receiveFromPublisher(ctx, logger, pubUri, topic, md5sum, msgType, nodeId, hints, hostname, link, msgChan)
+		if ctx.Err() != nil {
+			return
+		}
+		reportError(fmt.Errorf("subscription to %s from %s: %w", topic, pubUri, err))
+		if errors.Is(err, ErrMD5Mismatch) {
+			select {
+			case disconnectedChan <- pubUri:
+			case <-ctx.Done():
+			}
+			return
+		}
+		if received {
+			interval = minReconnectInterval
+		}
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return
+		}
+		interval *= 2
+		if interval > maxReconnectInterval {
+			interval = maxReconnectInterval
+		}
 	}
+}
 
-	// 1. Write connection header
+// Header a subscriber sends to connect to a publisher.
+func subscriberHeader(topic string, md5sum string, msgType string, nodeId string, hints TransportHints) []header {
 	var headers []header
 	headers = append(headers, header{"topic", topic})
 	headers = append(headers, header{"md5sum", md5sum})
 	headers = append(headers, header{"type", msgType})
 	headers = append(headers, header{"callerid", nodeId})
+	if hints.tcpNoDelay {
+		headers = append(headers, header{"tcp_nodelay", "1"})
+	}
+	return headers
+}
+
+// Ask the publisher at pubUri for a connection using the transports in
+// hints, and receive messages from it until the connection fails or ctx is
+// canceled.  UDPROS datagrams are sent to hostname, and received on a socket
+// bound like the node's other listeners.  received tells whether any message
+// arrived.
+func receiveFromPublisher(ctx context.Context, logger Logger,
+	pubUri string, topic string, md5sum string,
+	msgType string, nodeId string, hints TransportHints, hostname string, link *connection,
+	msgChan chan messageEvent) (received bool, err error) {
+	var udpConn *net.UDPConn
+	var protocols []interface{}
+	for _, transport := range hints.protocols() {
+		switch transport {
+		case "TCPROS":
+			protocols = append(protocols, []interface{}{"TCPROS"})
+		case "UDPROS":
+			if udpConn != nil {
+				continue
+			}
+			var addr *net.UDPAddr
+			if addr, err = net.ResolveUDPAddr("udp", net.JoinHostPort(bindAddress(hostname), "0")); err != nil {
+				return false, err
+			}
+			if udpConn, err = net.ListenUDP("udp", addr); err != nil {
+				return false, err
+			}
+			defer udpConn.Close()
+			headerFields := encodeHeaderFields(subscriberHeader(topic, md5sum, msgType, nodeId, hints))
+			port := udpConn.LocalAddr().(*net.UDPAddr).Port
+			protocols = append(protocols, []interface{}{"UDPROS", headerFields, hostname, int32(port), int32(hints.datagramSize())})
+		}
+	}
+	result, err := callRosApiContext(ctx, pubUri, "requestTopic", nodeId, topic, protocols)
+	if err != nil {
+		return false, err
+	}
+	protocolParams, ok := result.([]interface{})
+	if !ok || len(protocolParams) == 0 {
+		return false, fmt.Errorf("publisher offered no protocol")
+	}
+	for _, x := range protocolParams {
+		logger.Debug(x)
+	}
+	if name, _ := protocolParams[0].(string); name == "UDPROS" && udpConn != nil {
+		return receiveDatagrams(ctx, logger, udpConn, protocolParams, md5sum, msgType, link, msgChan)
+	} else if name != "TCPROS" {
+		return false, fmt.Errorf("rosgo does not support protocol '%v'", protocolParams[0])
+	}
+	if len(protocolParams) < 3 {
+		return false, fmt.Errorf("malformed TCPROS parameters %v", protocolParams)
+	}
+	addr, ok := protocolParams[1].(string)
+	port, ok2 := protocolParams[2].(int32)
+	if !ok || !ok2 {
+		return false, fmt.Errorf("malformed TCPROS parameters %v", protocolParams)
+	}
+	pubAddress := net.JoinHostPort(addr, strconv.Itoa(int(port)))
+	return receiveMessages(ctx, logger, pubAddress, topic, md5sum, msgType, nodeId, hints, link, msgChan)
+}
+
+// Connect to the TCPROS endpoint at pubAddress and pass the messages it
+// sends to msgChan until the connection fails or ctx is canceled.  Traffic is
+// counted on link.
+func receiveMessages(ctx context.Context, logger Logger,
+	pubAddress string, topic string, md5sum string,
+	msgType string, nodeId string, hints TransportHints, link *connection,
+	msgChan chan messageEvent) (received bool, err error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", pubAddress)
+	if err != nil {
+		return false, err
+	}
+	if tcpConn, ok := conn.(*net.TCPConn); ok && hints.tcpNoDelay {
+		tcpConn.SetNoDelay(true)
+	}
+	// Closing the connection unblocks the reader when ctx is canceled.
+	connCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-connCtx.Done()
+		conn.Close()
+	}()
+
+	// 1. Write connection header
+	headers := subscriberHeader(topic, md5sum, msgType, nodeId, hints)
 	logger.Debug("TCPROS Connection Header")
 	for _, h := range headers {
 		logger.Debugf("  `%s` = `%s`", h.key, h.value)
 	}
-	err = writeConnectionHeader(headers, conn)
-	if err != nil {
-		logger.Fatal("Failed to write connection header.")
+	if err = writeConnectionHeader(headers, conn); err != nil {
+		return false, fmt.Errorf("failed to write connection header: %w", err)
 	}
 
 	// 2. Read reponse header
-	var resHeaders []header
-	resHeaders, err = readConnectionHeader(conn)
+	reader := bufio.NewReader(conn)
+	resHeaders, err := readConnectionHeader(reader)
 	if err != nil {
-		logger.Fatal("Failed to read reasponse header.")
+		return false, fmt.Errorf("failed to read response header: %w", err)
 	}
 	logger.Debug("TCPROS Response Header:")
 	resHeaderMap := make(map[string]string)
@@ -182,68 +410,209 @@ func startRemotePublisherConn(logger Logger,
 		resHeaderMap[h.key] = h.value
 		logger.Debugf("  `%s` = `%s`", h.key, h.value)
 	}
+	if message, ok := resHeaderMap["error"]; ok {
+		return false, fmt.Errorf("publisher rejected connection: %s", message)
+	}
 	if resHeaderMap["type"] != msgType || resHeaderMap["md5sum"] != md5sum {
-		logger.Fatalf("Incomatible message type!")
+		return false, fmt.Errorf("%w: expected %s [%s], publisher has %s [%s]",
+			ErrMD5Mismatch, msgType, md5sum, resHeaderMap["type"], resHeaderMap["md5sum"])
 	}
 	logger.Debug("Start receiving messages...")
+	link.setTransport("TCPROS")
+	link.setConnected(true)
+	defer link.setConnected(false)
 	event := MessageEvent{ // Event struct to be sent with each message.
 		PublisherName:    resHeaderMap["callerid"],
 		ConnectionHeader: resHeaderMap,
 	}
 
 	// 3. Start reading messages
-	readingSize := true
-	var msgSize uint32 = 0
-	var buffer []byte
 	for {
+		buffer, err := readMessage(reader)
+		if err != nil {
+			return received, fmt.Errorf("failed to read a message from %s: %w", pubAddress, err)
+		}
+		received = true
+		link.count(len(buffer))
+		event.ReceiptTime = time.Now()
 		select {
-		case <-quitChan:
-			return
-		default:
-			conn.SetDeadline(time.Now().Add(10 * time.Millisecond))
-			if readingSize {
-				//logger.Debug("Reading message size...")
-				err = binary.Read(conn, binary.LittleEndian, &msgSize)
-				if err != nil {
-					if neterr, ok := err.(net.Error); ok && neterr.Timeout() {
-						// Timed out
-						//logger.Debug(neterr)
-						continue
-					} else {
-						logger.Error("Failed to read a message size")
-						disconnectedChan <- pubUri
-						return
-					}
-				}
-				logger.Debugf("  %d", msgSize)
-				buffer = make([]byte, int(msgSize))
-				readingSize = false
-			} else {
-				//logger.Debug("Reading message body...")
-				_, err = io.ReadFull(conn, buffer)
-				if err != nil {
-					if neterr, ok := err.(net.Error); ok && neterr.Timeout() {
-						// Timed out
-						//logger.Debug(neterr)
-						continue
-					} else {
-						logger.Error("Failed to read a message body")
-						disconnectedChan <- pubUri
-						return
-					}
-				}
-				event.ReceiptTime = time.Now()
-				msgChan <- messageEvent{bytes: buffer, event: event}
-				readingSize = true
+		case msgChan <- messageEvent{bytes: buffer, event: event, link: link}:
+		case <-ctx.Done():
+			return received, ctx.Err()
+		}
+	}
+}
+
+// Receive the messages a publisher sends to conn over the UDPROS connection
+// it described in protocolParams, and pass them to msgChan until ctx is
+// canceled.  Traffic is counted on link.
+func receiveDatagrams(ctx context.Context, logger Logger, conn *net.UDPConn,
+	protocolParams []interface{}, md5sum string, msgType string, link *connection,
+	msgChan chan messageEvent) (received bool, err error) {
+	if len(protocolParams) < 6 {
+		return false, fmt.Errorf("malformed UDPROS parameters %v", protocolParams)
+	}
+	connectionID, ok1 := protocolParams[3].(int32)
+	maxDatagramSize, ok2 := protocolParams[4].(int32)
+	headerFields, ok3 := protocolParams[5].([]byte)
+	if !ok1 || !ok2 || !ok3 || maxDatagramSize <= udprosHeaderSize || maxDatagramSize > maxUDPPayload {
+		return false, fmt.Errorf("malformed UDPROS parameters %v", protocolParams)
+	}
+	resHeaders, err := decodeHeaderFields(headerFields)
+	if err != nil {
+		return false, fmt.Errorf("failed to read response header: %w", err)
+	}
+	logger.Debug("UDPROS Response Header:")
+	resHeaderMap := make(map[string]string)
+	for _, h := range resHeaders {
+		resHeaderMap[h.key] = h.value
+		logger.Debugf("  `%s` = `%s`", h.key, h.value)
+	}
+	if resHeaderMap["type"] != msgType || resHeaderMap["md5sum"] != md5sum {
+		return false, fmt.Errorf("%w: expected %s [%s], publisher has %s [%s]",
+			ErrMD5Mismatch, msgType, md5sum, resHeaderMap["type"], resHeaderMap["md5sum"])
+	}
+	// Closing the socket unblocks the reader when ctx is canceled.
+	connCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-connCtx.Done()
+		conn.Close()
+	}()
+	link.setTransport("UDPROS")
+	link.setConnected(true)
+	defer link.setConnected(false)
+	event := MessageEvent{
+		PublisherName:    resHeaderMap["callerid"],
+		ConnectionHeader: resHeaderMap,
+	}
+
+	reader := newUDPROSReader(conn, uint32(connectionID), int(maxDatagramSize))
+	for {
+		buffer, err := reader.readMessage()
+		if err != nil {
+			return received, fmt.Errorf("failed to read a datagram: %w", err)
+		}
+		received = true
+		link.count(len(buffer))
+		event.ReceiptTime = time.Now()
+		select {
+		case msgChan <- messageEvent{bytes: buffer, event: event, link: link}:
+		case <-ctx.Done():
+			return received, ctx.Err()
+		}
+	}
+}
+
+// Invoke callbacks for every message in the callback queue.
+func (sub *defaultSubscriber) runCallbacks(logger Logger) {
+	for {
+		if !sub.runCallback(logger) {
+			// Clear the flag, then check for a message queued after the
+			// pop, whose arrival didn't schedule a job since the flag was
+			// still set.
+			atomic.StoreInt32(&sub.jobPending, 0)
+			if sub.queue.len() == 0 || !atomic.CompareAndSwapInt32(&sub.jobPending, 0, 1) {
+				return
 			}
 		}
 	}
 }
 
+// Run the callbacks for the oldest queued message.  Returns false if there
+// was none.
+func (sub *defaultSubscriber) runCallback(logger Logger) bool {
+	item, ok := sub.queue.pop()
+	if !ok {
+		return false
+	}
+	sub.invoke(item.(pendingMessage), logger)
+	return true
+}
+
+// Run the callbacks bound to a message.
+func (sub *defaultSubscriber) invoke(pending pendingMessage, logger Logger) {
+	m := sub.msgType.NewMessage()
+	reader := bytes.NewReader(pending.msgEvent.bytes)
+	if err := m.Deserialize(reader); err != nil {
+		logger.Error(err)
+	}
+	args := []reflect.Value{reflect.ValueOf(m), reflect.ValueOf(pending.msgEvent.event)}
+	for _, callback := range pending.callbacks {
+		fun := reflect.ValueOf(callback)
+		num_args_needed := fun.Type().NumIn()
+		if num_args_needed <= 2 {
+			fun.Call(args[0:num_args_needed])
+		}
+	}
+}
+
+// Replace the list of publishers to receive from.
+func (sub *defaultSubscriber) updatePublishers(publishers []string) {
+	select {
+	case sub.pubListChan <- publishers:
+	case <-sub.done:
+	}
+}
+
+// Add a callback for the messages which arrive from now on.  Returns false
+// if the subscriber has shut down.
+func (sub *defaultSubscriber) addCallback(callback interface{}) bool {
+	select {
+	case sub.addCallbackChan <- callback:
+		return true
+	case <-sub.done:
+		return false
+	}
+}
+
+// Unregister the subscriber and close its connections, returning once it has
+// finished.  Further calls do nothing.
 func (sub *defaultSubscriber) Shutdown() {
-	sub.shutdownChan <- struct{}{}
+	sub.shutdownOnce.Do(func() {
+		sub.shutdownChan <- struct{}{}
+		<-sub.done
+		if sub.onShutdown != nil {
+			sub.onShutdown()
+		}
+	})
 }
 
 func (sub *defaultSubscriber) GetNumPublishers() int {
-	return len(sub.pubList)
+	return int(atomic.LoadInt32(&sub.numPublishers))
+}
+
+func (sub *defaultSubscriber) GetNumDropped() uint64 {
+	return atomic.LoadUint64(&sub.dropped)
+}
+
+func (sub *defaultSubscriber) GetConnections() []ConnectionStats {
+	return sub.stats.stats()
+}
+
+func (sub *defaultSubscriber) WaitForPublishers(n int, timeout time.Duration) error {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return sub.WaitForPublishersContext(ctx, n)
+}
+
+func (sub *defaultSubscriber) WaitForPublishersContext(ctx context.Context, n int) error {
+	for {
+		// Get the channel first so no change is missed.
+		changed := sub.stats.wait()
+		if sub.stats.numConnected() >= n {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-sub.done:
+			return ErrShutdown
+		}
+	}
 }
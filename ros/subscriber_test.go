@@ -0,0 +1,197 @@
+package ros
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSubscriberQueueDropsOldest(t *testing.T) {
+	var wg sync.WaitGroup
+	jobChan := make(chan func(), 10)
+	var received []string
+	callback := func(msg *testMessage) {
+		received = append(received, msg.Data)
+	}
+	sub := newDefaultSubscriber("/chatter", testMessageType{}, callback, subscriberOptions{queueSize: 2})
+	wg.Add(1)
+	go sub.start(context.Background(), &wg, "/test_node", "", "", jobChan, NewDefaultLogger(), func(error) {})
+	defer sub.Shutdown()
+
+	for _, data := range []string{"a", "b", "c", "d", "e"} {
+		var buf bytes.Buffer
+		msg := testMessage{data}
+		msg.Serialize(&buf)
+		sub.msgChan <- messageEvent{bytes: buf.Bytes()}
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for sub.GetNumDropped() < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := sub.GetNumDropped(); n != 3 {
+		t.Fatalf("expected 3 dropped messages; got %d", n)
+	}
+	if len(jobChan) != 1 {
+		t.Fatalf("expected a single pending callback job; got %d", len(jobChan))
+	}
+
+	job := <-jobChan
+	job()
+	if len(received) != 2 || received[0] != "d" || received[1] != "e" {
+		t.Errorf("expected callbacks for [d e]; got %v", received)
+	}
+}
+
+func TestSubscriberFullCallbackQueue(t *testing.T) {
+	for _, concurrent := range []bool{false, true} {
+		var wg sync.WaitGroup
+		// Nothing takes jobs from the channel.
+		jobChan := make(chan func())
+		options := subscriberOptions{queueSize: 2, concurrent: concurrent}
+		sub := newDefaultSubscriber("/chatter", testMessageType{}, func(msg *testMessage) {}, options)
+		wg.Add(1)
+		go sub.start(context.Background(), &wg, "/test_node", "", "", jobChan, NewDefaultLogger(), func(error) {})
+
+		for _, data := range []string{"a", "b", "c", "d", "e"} {
+			var buf bytes.Buffer
+			msg := testMessage{data}
+			msg.Serialize(&buf)
+			select {
+			case sub.msgChan <- messageEvent{bytes: buf.Bytes()}:
+			case <-time.After(5 * time.Second):
+				t.Fatalf("concurrent=%v: subscriber stopped receiving", concurrent)
+			}
+		}
+		deadline := time.Now().Add(5 * time.Second)
+		for sub.GetNumDropped() < 3 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		if n := sub.GetNumDropped(); n != 3 {
+			t.Errorf("concurrent=%v: expected 3 dropped messages; got %d", concurrent, n)
+		}
+
+		done := make(chan struct{})
+		go func() {
+			sub.Shutdown()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("concurrent=%v: shutdown blocked on the callback queue", concurrent)
+		}
+	}
+}
+
+func TestRemotePublisherConnRetries(t *testing.T) {
+	// Nothing listens here, so every attempt fails and is retried.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubUri := "http://" + listener.Addr().String()
+	listener.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reported := make(chan error, 10)
+	done := make(chan struct{})
+	go func() {
+		startRemotePublisherConn(ctx, NewDefaultLogger(), func(err error) { reported <- err },
+			pubUri, "/chatter", testMessageType{}.MD5Sum(), testMessageType{}.Name(), "/test_node", TransportHints{}, "",
+			newConnection(pubUri, directionInbound, "/chatter"), make(chan messageEvent), make(chan string))
+		close(done)
+	}()
+	for i := 0; i < 2; i++ {
+		select {
+		case <-reported:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("attempt %d was not reported", i+1)
+		}
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection goroutine did not stop after cancel")
+	}
+}
+
+func TestReceiveMessagesMD5Mismatch(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		readConnectionHeader(conn)
+		writeConnectionHeader([]header{
+			{"type", "std_msgs/Int32"},
+			{"md5sum", "da5909fbe378aeaf85e547e830cc1bb7"},
+			{"callerid", "/test_publisher"},
+		}, conn)
+	}()
+
+	_, err = receiveMessages(context.Background(), NewDefaultLogger(), listener.Addr().String(),
+		"/chatter", testMessageType{}.MD5Sum(), testMessageType{}.Name(), "/test_node", TransportHints{},
+		newConnection("", directionInbound, "/chatter"), make(chan messageEvent))
+	if !errors.Is(err, ErrMD5Mismatch) {
+		t.Errorf("expected ErrMD5Mismatch; got %v", err)
+	}
+}
+
+func TestWaitForPublishers(t *testing.T) {
+	m, args := startTestMaster(t)
+	defer m.Shutdown()
+	listener := newTestNode(t, "listener", args)
+	defer listener.Shutdown()
+	sub, err := listener.NewSubscriber("chatter", testMessageType{}, func(msg *testMessage) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sub.WaitForPublishers(1, 50*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded; got %v", err)
+	}
+
+	for _, name := range []string{"talker1", "talker2"} {
+		talker := newTestNode(t, name, args)
+		defer talker.Shutdown()
+		if _, err := talker.NewPublisher("chatter", testMessageType{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sub.WaitForPublishers(2, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	connected := 0
+	for _, c := range sub.GetConnections() {
+		if c.Connected {
+			connected++
+		}
+	}
+	if connected != 2 {
+		t.Errorf("expected 2 connected publishers; got %d", connected)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- sub.WaitForPublishers(3, 0)
+	}()
+	sub.Shutdown()
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrShutdown) {
+			t.Errorf("expected ErrShutdown; got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("wait didn't end when the subscriber shut down")
+	}
+}
@@ -0,0 +1,201 @@
+package ros
+
+import (
+	"sync"
+)
+
+// TimerEvent is passed to timer callbacks.
+type TimerEvent struct {
+	// When the previous callback was due and when it ran.
+	LastExpected Time
+	LastReal     Time
+	// When this callback was due and when it started running.
+	CurrentExpected Time
+	CurrentReal     Time
+	// How long the previous callback took.
+	LastDuration Duration
+}
+
+// Timer calls a callback periodically, or once, from the node's Spin.
+type Timer interface {
+	// Start the timer, the first callback being due a period from now.
+	// Starting a running timer does nothing.
+	Start()
+	// Stop the timer.  A callback which is already queued doesn't run.
+	Stop()
+	// Change the period, restarting the timer if it is running so the next
+	// callback is due a period from now.
+	SetPeriod(period Duration)
+}
+
+type defaultTimer struct {
+	clock    *clock
+	callback func(TimerEvent)
+	oneshot  bool
+	jobChan  chan func()
+
+	mutex        sync.Mutex
+	period       Duration
+	stop         chan struct{} // Closed to stop the running timer; nil when stopped.
+	generation   int           // Changed each time the timer starts or stops.
+	pending      bool          // Whether a callback is queued.
+	fired        chan struct{} // Closed when the queued callback runs.
+	lastExpected Time
+	lastReal     Time
+	lastDuration Duration
+}
+
+func newDefaultTimer(clock *clock, jobChan chan func(), period Duration, callback func(TimerEvent), oneshot bool) *defaultTimer {
+	t := new(defaultTimer)
+	t.clock = clock
+	t.callback = callback
+	t.oneshot = oneshot
+	t.jobChan = jobChan
+	t.period = period
+	return t
+}
+
+func (t *defaultTimer) Start() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.start()
+}
+
+// The caller must hold t.mutex.
+func (t *defaultTimer) start() {
+	if t.stop != nil {
+		return
+	}
+	t.stop = make(chan struct{})
+	t.generation++
+	t.pending = false
+	go t.run(t.stop, t.generation, t.period)
+}
+
+func (t *defaultTimer) Stop() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.halt()
+}
+
+// The caller must hold t.mutex.
+func (t *defaultTimer) halt() {
+	t.generation++
+	if t.stop != nil {
+		close(t.stop)
+		t.stop = nil
+	}
+}
+
+func (t *defaultTimer) SetPeriod(period Duration) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.period = period
+	if t.stop != nil {
+		t.halt()
+		t.start()
+	}
+}
+
+// Queue a callback each time one is due until stop is closed.  A callback
+// which falls due while the previous one is still queued is skipped, and the
+// timer waits for that one to run, so it doesn't spin when the period is
+// zero or callbacks are overdue.
+func (t *defaultTimer) run(stop chan struct{}, generation int, period Duration) {
+	start := t.clock.now()
+	expected := start.Add(period)
+	for {
+		err := t.clock.sleepUntil(start, expected, stop)
+		if err == ErrTimeJumpedBackward {
+			start = t.clock.now()
+			expected = start.Add(period)
+			continue
+		}
+		if err != nil {
+			return
+		}
+		t.mutex.Lock()
+		if t.generation != generation {
+			t.mutex.Unlock()
+			return
+		}
+		queue := !t.pending
+		fired := t.fired
+		if queue {
+			t.pending = true
+			fired = make(chan struct{})
+			t.fired = fired
+		}
+		if t.oneshot {
+			t.stop = nil
+		}
+		t.mutex.Unlock()
+
+		if queue {
+			current := expected
+			job := func() { t.fire(generation, current) }
+			select {
+			case t.jobChan <- job:
+			case <-stop:
+				return
+			case <-t.clock.done:
+				return
+			}
+		}
+		if t.oneshot {
+			return
+		}
+		if !queue {
+			select {
+			case <-fired:
+			case <-stop:
+				return
+			case <-t.clock.done:
+				return
+			}
+		}
+		start = expected
+		expected = expected.Add(period)
+		// Don't try to make up for callbacks which were missed.
+		if now := t.clock.now(); now.Cmp(expected) > 0 {
+			start = now
+			expected = now.Add(period)
+		}
+	}
+}
+
+// Run the callback due at expected, unless the timer was stopped or
+// restarted after it was queued.
+func (t *defaultTimer) fire(generation int, expected Time) {
+	t.mutex.Lock()
+	if t.generation != generation {
+		t.mutex.Unlock()
+		return
+	}
+	t.pending = false
+	if t.fired != nil {
+		close(t.fired)
+		t.fired = nil
+	}
+	event := TimerEvent{
+		LastExpected:    t.lastExpected,
+		LastReal:        t.lastReal,
+		CurrentExpected: expected,
+		CurrentReal:     t.clock.now(),
+		LastDuration:    t.lastDuration,
+	}
+	t.mutex.Unlock()
+
+	t.callback(event)
+	end := t.clock.now()
+
+	t.mutex.Lock()
+	t.lastExpected = event.CurrentExpected
+	t.lastReal = event.CurrentReal
+	if end.Cmp(event.CurrentReal) >= 0 {
+		t.lastDuration = end.Diff(event.CurrentReal)
+	} else {
+		t.lastDuration = Duration{}
+	}
+	t.mutex.Unlock()
+}
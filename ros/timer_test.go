@@ -0,0 +1,172 @@
+package ros
+
+import (
+	"syscall"
+	"testing"
+	"time"
+)
+
+// Run jobs as Spin would until n timer events are collected.
+func collectTimerEvents(t *testing.T, jobChan chan func(), events chan TimerEvent, n int) []TimerEvent {
+	t.Helper()
+	var collected []TimerEvent
+	deadline := time.After(5 * time.Second)
+	for len(collected) < n {
+		select {
+		case job := <-jobChan:
+			job()
+		case event := <-events:
+			collected = append(collected, event)
+		case <-deadline:
+			t.Fatalf("expected %d timer events; got %d", n, len(collected))
+		}
+	}
+	return collected
+}
+
+// Fail if a job is queued within d.
+func expectNoJob(t *testing.T, jobChan chan func(), d time.Duration) {
+	t.Helper()
+	select {
+	case job := <-jobChan:
+		job()
+		t.Error("unexpected timer callback queued")
+	case <-time.After(d):
+	}
+}
+
+func TestTimerPeriodic(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+	jobChan := make(chan func(), 10)
+	events := make(chan TimerEvent, 10)
+	period := NewDuration(0, 20000000)
+	timer := newDefaultTimer(newClock(done), jobChan, period, func(event TimerEvent) {
+		time.Sleep(5 * time.Millisecond)
+		events <- event
+	}, false)
+	timer.Start()
+	defer timer.Stop()
+
+	collected := collectTimerEvents(t, jobChan, events, 3)
+	if !collected[0].LastExpected.IsZero() {
+		t.Errorf("expected no previous callback; got %v", collected[0].LastExpected)
+	}
+	for i := 1; i < len(collected); i++ {
+		previous, event := collected[i-1], collected[i]
+		if event.LastExpected != previous.CurrentExpected || event.LastReal != previous.CurrentReal {
+			t.Errorf("event %d doesn't follow the previous one: %+v after %+v", i, event, previous)
+		}
+		if interval := event.CurrentExpected.Diff(previous.CurrentExpected); interval != period {
+			t.Errorf("expected callbacks 20ms apart; got %v", interval)
+		}
+		if event.CurrentReal.Cmp(event.CurrentExpected) < 0 {
+			t.Errorf("callback ran at %v before it was due at %v", event.CurrentReal, event.CurrentExpected)
+		}
+		if event.LastDuration.ToNSec() < uint64(5*time.Millisecond) {
+			t.Errorf("expected the last callback to take at least 5ms; got %v", event.LastDuration)
+		}
+	}
+}
+
+func TestTimerOneshotStopAndSetPeriod(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+	jobChan := make(chan func(), 10)
+	events := make(chan TimerEvent, 10)
+	timer := newDefaultTimer(newClock(done), jobChan, NewDuration(0, 10000000), func(event TimerEvent) {
+		events <- event
+	}, true)
+	timer.Start()
+	collectTimerEvents(t, jobChan, events, 1)
+	expectNoJob(t, jobChan, 50*time.Millisecond)
+
+	// A oneshot timer can be started again.
+	timer.Start()
+	collectTimerEvents(t, jobChan, events, 1)
+
+	// A callback queued before Stop doesn't run.
+	timer.Start()
+	time.Sleep(50 * time.Millisecond)
+	timer.Stop()
+	for len(jobChan) > 0 {
+		(<-jobChan)()
+	}
+	select {
+	case <-events:
+		t.Error("callback ran after the timer stopped")
+	default:
+	}
+
+	timer.SetPeriod(NewDuration(0, 20000000))
+	expectNoJob(t, jobChan, 50*time.Millisecond)
+	timer.Start()
+	collectTimerEvents(t, jobChan, events, 1)
+}
+
+func TestTimerSimulated(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+	clock := newClock(done)
+	clock.simulate()
+	clock.set(NewTime(10, 0))
+	jobChan := make(chan func(), 10)
+	events := make(chan TimerEvent, 10)
+	timer := newDefaultTimer(clock, jobChan, NewDuration(1, 0), func(event TimerEvent) {
+		events <- event
+	}, false)
+	timer.Start()
+	defer timer.Stop()
+
+	time.Sleep(20 * time.Millisecond)
+	clock.set(NewTime(10, 500000000))
+	expectNoJob(t, jobChan, 50*time.Millisecond)
+	clock.set(NewTime(11, 0))
+	event := collectTimerEvents(t, jobChan, events, 1)[0]
+	if event.CurrentExpected != NewTime(11, 0) || event.CurrentReal != NewTime(11, 0) {
+		t.Errorf("expected a callback due and run at 11s; got %+v", event)
+	}
+	// Time jumping back restarts the period.
+	clock.set(NewTime(5, 0))
+	time.Sleep(20 * time.Millisecond)
+	clock.set(NewTime(6, 0))
+	event = collectTimerEvents(t, jobChan, events, 1)[0]
+	if event.CurrentExpected != NewTime(6, 0) {
+		t.Errorf("expected a callback due at 6s; got %+v", event)
+	}
+}
+
+// CPU time the process has used.
+func cpuTime(t *testing.T) time.Duration {
+	t.Helper()
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		t.Fatal(err)
+	}
+	return time.Duration(usage.Utime.Nano() + usage.Stime.Nano())
+}
+
+// A zero-period timer waits for its queued callback to run rather than
+// spin, then queues the next one straight away.
+func TestTimerZeroPeriod(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+	jobChan := make(chan func(), 10)
+	events := make(chan TimerEvent, 10)
+	timer := newDefaultTimer(newClock(done), jobChan, Duration{}, func(event TimerEvent) {
+		events <- event
+	}, false)
+	timer.Start()
+	defer timer.Stop()
+
+	collectTimerEvents(t, jobChan, events, 1)
+	before := cpuTime(t)
+	time.Sleep(200 * time.Millisecond)
+	if used := cpuTime(t) - before; used > 100*time.Millisecond {
+		t.Errorf("expected the timer to wait for its callback; used %v of CPU in 200ms", used)
+	}
+	if len(jobChan) != 1 {
+		t.Errorf("expected one callback queued; got %d", len(jobChan))
+	}
+	collectTimerEvents(t, jobChan, events, 2)
+}
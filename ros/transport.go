@@ -0,0 +1,30 @@
+package ros
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+)
+
+// Write a length-prefixed TCPROS message with a single system call where the
+// writer supports it.
+func writeMessage(w io.Writer, msg []byte) error {
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(len(msg)))
+	buffers := net.Buffers{size[:], msg}
+	_, err := buffers.WriteTo(w)
+	return err
+}
+
+// Read a length-prefixed TCPROS message.
+func readMessage(r io.Reader) ([]byte, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return nil, err
+	}
+	msg := make([]byte, int(binary.LittleEndian.Uint32(size[:])))
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
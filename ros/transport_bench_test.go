@@ -0,0 +1,153 @@
+package ros_test
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ppg/rosgo/master"
+	"github.com/ppg/rosgo/msgs/sensor_msgs"
+	"github.com/ppg/rosgo/msgs/std_msgs"
+	"github.com/ppg/rosgo/ros"
+)
+
+func newBenchImage() *sensor_msgs.Image {
+	const width, height = 640, 480
+	return &sensor_msgs.Image{
+		Height:   height,
+		Width:    width,
+		Encoding: "rgb8",
+		Step:     width * 3,
+		Data:     make([]uint8, width*height*3),
+	}
+}
+
+// startBenchLink connects a publisher and a subscriber on two nodes over
+// loopback TCPROS, with unbounded queues so no message is dropped.  Received
+// messages are passed to callback by the subscribing node's Spin.  The
+// returned function tears the link down.
+func startBenchLink(b *testing.B, msgType ros.MessageType, callback interface{}) (ros.Publisher, func()) {
+	m, err := master.Start("127.0.0.1:0")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Setenv("ROS_MASTER_URI", m.Uri())
+	b.Setenv("ROS_IP", "127.0.0.1")
+
+	talker, err := ros.NewNode("bench_pub")
+	if err != nil {
+		b.Fatal(err)
+	}
+	talker.Logger().SetSeverity(ros.LogLevelFatal)
+	pub, err := talker.NewPublisher("bench", msgType, ros.PublisherQueueSize(0))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	listener, err := ros.NewNode("bench_sub")
+	if err != nil {
+		b.Fatal(err)
+	}
+	listener.Logger().SetSeverity(ros.LogLevelFatal)
+	if _, err := listener.NewSubscriber("bench", msgType, callback, ros.SubscriberQueueSize(0)); err != nil {
+		b.Fatal(err)
+	}
+	go listener.Spin()
+
+	return pub, func() {
+		listener.Shutdown()
+		talker.Shutdown()
+		m.Shutdown()
+	}
+}
+
+// waitForBenchLink publishes msg until the subscriber signals on received.
+func waitForBenchLink(b *testing.B, pub ros.Publisher, msg ros.Message, received chan struct{}) {
+	deadline := time.After(5 * time.Second)
+	for {
+		pub.Publish(msg)
+		select {
+		case <-received:
+			return
+		case <-time.After(10 * time.Millisecond):
+		case <-deadline:
+			b.Fatal("subscriber did not connect")
+		}
+	}
+}
+
+func benchmarkLatency(b *testing.B, msgType ros.MessageType, msg ros.Message) {
+	received := make(chan struct{}, 1)
+	pub, stop := startBenchLink(b, msgType, func() { received <- struct{}{} })
+	defer stop()
+	waitForBenchLink(b, pub, msg, received)
+	// Drain messages from the warm-up still in flight.
+	time.Sleep(10 * time.Millisecond)
+	for len(received) > 0 {
+		<-received
+	}
+
+	b.SetBytes(int64(serializedSize(msg)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pub.Publish(msg)
+		<-received
+	}
+}
+
+func benchmarkThroughput(b *testing.B, msgType ros.MessageType, msg ros.Message) {
+	var mutex sync.Mutex
+	count, target := 0, -1
+	received := make(chan struct{}, 1)
+	pub, stop := startBenchLink(b, msgType, func() {
+		mutex.Lock()
+		count++
+		if count == target || target < 0 {
+			select {
+			case received <- struct{}{}:
+			default:
+			}
+		}
+		mutex.Unlock()
+	})
+	defer stop()
+	waitForBenchLink(b, pub, msg, received)
+	time.Sleep(10 * time.Millisecond)
+
+	mutex.Lock()
+	target = count + b.N
+	for len(received) > 0 {
+		<-received
+	}
+	mutex.Unlock()
+
+	b.SetBytes(int64(serializedSize(msg)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pub.Publish(msg)
+	}
+	<-received
+}
+
+func serializedSize(msg ros.Message) int {
+	var buf bytes.Buffer
+	msg.Serialize(&buf)
+	return buf.Len()
+}
+
+func BenchmarkStringLatency(b *testing.B) {
+	benchmarkLatency(b, std_msgs.MsgString, &std_msgs.String{Data: "hello world"})
+}
+
+func BenchmarkStringThroughput(b *testing.B) {
+	benchmarkThroughput(b, std_msgs.MsgString, &std_msgs.String{Data: "hello world"})
+}
+
+func BenchmarkImageLatency(b *testing.B) {
+	benchmarkLatency(b, sensor_msgs.MsgImage, newBenchImage())
+}
+
+func BenchmarkImageThroughput(b *testing.B) {
+	benchmarkThroughput(b, sensor_msgs.MsgImage, newBenchImage())
+}
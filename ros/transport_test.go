@@ -0,0 +1,31 @@
+package ros
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestMessageFraming(t *testing.T) {
+	var buf bytes.Buffer
+	for _, msg := range []string{"hello", "", "world"} {
+		if err := writeMessage(&buf, []byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if buf.Len() != 3*4+len("hello")+len("world") {
+		t.Errorf("unexpected framed length %d", buf.Len())
+	}
+	for _, expected := range []string{"hello", "", "world"} {
+		msg, err := readMessage(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(msg) != expected {
+			t.Errorf("expected '%s'; got '%s'", expected, string(msg))
+		}
+	}
+	if _, err := readMessage(&buf); err != io.EOF {
+		t.Errorf("expected io.EOF at end of stream; got %v", err)
+	}
+}
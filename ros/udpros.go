@@ -0,0 +1,125 @@
+package ros
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net"
+)
+
+// UDPROS sends each message, prefixed with its length as over TCPROS, in
+// datagrams no larger than the size the subscriber asked for.  Every
+// datagram starts with a header holding the connection ID, an opcode, the
+// message ID and a block number, which is the number of datagrams in the
+// message for the first one and the datagram's index for the others.
+const (
+	udprosData0 = 0 // First datagram of a message.
+	udprosDataN = 1 // Following datagram of a message.
+)
+
+const udprosHeaderSize = 8
+
+// Largest UDP payload over IPv4, which bounds the datagram size either side
+// of a connection may ask for.
+const maxUDPPayload = 65507
+
+// Largest datagram, header included, which subscribers ask UDPROS publishers
+// for unless TransportHints.MaxDatagramSize says otherwise.
+const DefaultMaxDatagramSize = 1500
+
+// udprosWriter splits messages into the datagrams of one UDPROS connection.
+type udprosWriter struct {
+	conn            net.Conn
+	connectionID    uint32
+	maxDatagramSize int
+	messageID       uint8
+}
+
+func newUDPROSWriter(conn net.Conn, connectionID uint32, maxDatagramSize int) *udprosWriter {
+	return &udprosWriter{conn: conn, connectionID: connectionID, maxDatagramSize: maxDatagramSize}
+}
+
+func (w *udprosWriter) writeMessage(msg []byte) error {
+	payload := make([]byte, 4+len(msg))
+	binary.LittleEndian.PutUint32(payload, uint32(len(msg)))
+	copy(payload[4:], msg)
+	blockSize := w.maxDatagramSize - udprosHeaderSize
+	blocks := (len(payload) + blockSize - 1) / blockSize
+	if blocks > math.MaxUint16 {
+		return fmt.Errorf("message of %d bytes needs more than %d datagrams", len(msg), math.MaxUint16)
+	}
+	w.messageID++
+	datagram := make([]byte, w.maxDatagramSize)
+	for i := 0; i < blocks; i++ {
+		var opcode byte = udprosData0
+		block := blocks
+		if i > 0 {
+			opcode = udprosDataN
+			block = i
+		}
+		binary.LittleEndian.PutUint32(datagram[0:], w.connectionID)
+		datagram[4] = opcode
+		datagram[5] = w.messageID
+		binary.LittleEndian.PutUint16(datagram[6:], uint16(block))
+		n := copy(datagram[udprosHeaderSize:], payload[i*blockSize:])
+		if _, err := w.conn.Write(datagram[:udprosHeaderSize+n]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// udprosReader puts the messages of one UDPROS connection back together.
+type udprosReader struct {
+	conn         net.Conn
+	connectionID uint32
+	datagram     []byte
+}
+
+func newUDPROSReader(conn net.Conn, connectionID uint32, maxDatagramSize int) *udprosReader {
+	return &udprosReader{conn: conn, connectionID: connectionID, datagram: make([]byte, maxDatagramSize)}
+}
+
+// Read datagrams until a whole message has arrived and return it.  Messages
+// which lose a datagram, or whose datagrams arrive out of order, are
+// dropped, and pings and errors ignored.
+func (r *udprosReader) readMessage() ([]byte, error) {
+	var payload []byte
+	var messageID uint8
+	var blocks int
+	next := 0 // Index of the datagram expected next, or 0 between messages.
+	for {
+		n, err := r.conn.Read(r.datagram)
+		if err != nil {
+			return nil, err
+		}
+		if n < udprosHeaderSize || binary.LittleEndian.Uint32(r.datagram) != r.connectionID {
+			continue
+		}
+		opcode := r.datagram[4]
+		id := r.datagram[5]
+		block := int(binary.LittleEndian.Uint16(r.datagram[6:]))
+		data := r.datagram[udprosHeaderSize:n]
+		switch {
+		case opcode == udprosData0:
+			payload = append(payload[:0], data...)
+			messageID, blocks, next = id, block, 1
+		case opcode == udprosDataN && next > 0 && id == messageID && block == next:
+			payload = append(payload, data...)
+			next++
+		case opcode == udprosDataN:
+			next = 0
+			continue
+		default:
+			continue
+		}
+		if next != blocks {
+			continue
+		}
+		next = 0
+		if len(payload) < 4 || int(binary.LittleEndian.Uint32(payload)) != len(payload)-4 {
+			continue
+		}
+		return append([]byte(nil), payload[4:]...), nil
+	}
+}
@@ -0,0 +1,181 @@
+package ros
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// A pair of UDP sockets, the first sending to the second.
+func udpPair(t *testing.T) (net.Conn, *net.UDPConn) {
+	receiver, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender, err := net.Dial("udp", receiver.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	receiver.SetDeadline(time.Now().Add(5 * time.Second))
+	return sender, receiver
+}
+
+func TestUDPROSFragmentation(t *testing.T) {
+	sender, receiver := udpPair(t)
+	defer sender.Close()
+	defer receiver.Close()
+	writer := newUDPROSWriter(sender, 7, 20)
+	reader := newUDPROSReader(receiver, 7, 20)
+
+	// Datagrams carry 12 bytes, 4 of which the first spends on the length.
+	for _, size := range []int{0, 8, 9, 12, 100} {
+		msg := bytes.Repeat([]byte{byte(size)}, size)
+		if err := writer.writeMessage(msg); err != nil {
+			t.Fatal(err)
+		}
+		received, err := reader.readMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(received, msg) {
+			t.Errorf("expected %d bytes of %d; got %v", size, size, received)
+		}
+	}
+}
+
+func TestUDPROSReaderDropsIncompleteMessages(t *testing.T) {
+	sender, receiver := udpPair(t)
+	defer sender.Close()
+	defer receiver.Close()
+	reader := newUDPROSReader(receiver, 7, 20)
+
+	datagram := func(connectionID uint32, opcode byte, messageID byte, block uint16, data string) {
+		var buf bytes.Buffer
+		binary.Write(&buf, binary.LittleEndian, connectionID)
+		buf.WriteByte(opcode)
+		buf.WriteByte(messageID)
+		binary.Write(&buf, binary.LittleEndian, block)
+		buf.WriteString(data)
+		if _, err := sender.Write(buf.Bytes()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Another connection's message, and one which loses its second datagram.
+	datagram(8, udprosData0, 1, 1, "\x05\x00\x00\x00other")
+	datagram(7, udprosData0, 1, 3, "\x0c\x00\x00\x00lost")
+	datagram(7, udprosDataN, 1, 2, "datagram")
+	// A complete message in two datagrams.
+	datagram(7, udprosData0, 2, 2, "\x05\x00\x00\x00he")
+	datagram(7, udprosDataN, 2, 1, "llo")
+
+	received, err := reader.readMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(received) != "hello" {
+		t.Errorf("expected hello; got %q", received)
+	}
+}
+
+func TestUDPROSSubscription(t *testing.T) {
+	m, args := startTestMaster(t)
+	defer m.Shutdown()
+	talker := newTestNode(t, "talker", args)
+	defer talker.Shutdown()
+	listener := newTestNode(t, "listener", args)
+	defer listener.Shutdown()
+	go listener.Spin()
+
+	pub, err := talker.NewPublisher("chatter", testMessageType{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := make(chan string, 10)
+	hints := TransportHints{}.Unreliable().Reliable().MaxDatagramSize(100)
+	sub, err := listener.NewSubscriber("chatter", testMessageType{}, func(msg *testMessage) {
+		received <- msg.Data
+	}, SubscriberTransportHints(hints))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sub.WaitForPublishers(1, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	// Long enough to take several datagrams.
+	long := strings.Repeat("hello", 100)
+	deadline := time.After(5 * time.Second)
+	for waiting := true; waiting; {
+		pub.Publish(&testMessage{long})
+		select {
+		case data := <-received:
+			if data != long {
+				t.Errorf("expected %d bytes; got %d", len(long), len(data))
+			}
+			waiting = false
+		case <-time.After(50 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("no message received")
+		}
+	}
+	for _, c := range append(pub.GetConnections(), sub.GetConnections()...) {
+		if c.Transport != "UDPROS" {
+			t.Errorf("expected a UDPROS connection; got %+v", c)
+		}
+	}
+}
+
+// A publisher which can't connect over UDPROS picks the subscriber's next
+// choice.
+func TestRequestTopicFallsBackToTCPROS(t *testing.T) {
+	m, args := startTestMaster(t)
+	defer m.Shutdown()
+	talker := newTestNode(t, "talker", args)
+	defer talker.Shutdown()
+	if _, err := talker.NewPublisher("chatter", testMessageType{}); err != nil {
+		t.Fatal(err)
+	}
+	protocols := []interface{}{
+		[]interface{}{"UDPROS", []byte("junk"), "127.0.0.1", int32(1), int32(DefaultMaxDatagramSize)},
+		[]interface{}{"TCPROS"},
+	}
+	result, err := callRosApi(talker.xmlrpcUri, "requestTopic", "/listener", "/chatter", protocols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params, ok := result.([]interface{}); !ok || len(params) == 0 || params[0] != "TCPROS" {
+		t.Errorf("expected TCPROS; got %v", result)
+	}
+}
+
+func TestRequestTopicLimitsDatagramSize(t *testing.T) {
+	m, args := startTestMaster(t)
+	defer m.Shutdown()
+	talker := newTestNode(t, "talker", args)
+	defer talker.Shutdown()
+	if _, err := talker.NewPublisher("chatter", testMessageType{}); err != nil {
+		t.Fatal(err)
+	}
+	_, receiver := udpPair(t)
+	defer receiver.Close()
+	port := int32(receiver.LocalAddr().(*net.UDPAddr).Port)
+	headerFields := encodeHeaderFields(subscriberHeader("/chatter", testMessageType{}.MD5Sum(), testMessageType{}.Name(), "/listener", TransportHints{}))
+	protocols := []interface{}{
+		[]interface{}{"UDPROS", headerFields, "127.0.0.1", port, int32(math.MaxInt32)},
+	}
+	result, err := callRosApi(talker.xmlrpcUri, "requestTopic", "/listener", "/chatter", protocols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params, ok := result.([]interface{})
+	if !ok || len(params) < 5 || params[0] != "UDPROS" {
+		t.Fatalf("expected UDPROS; got %v", result)
+	}
+	if params[4] != int32(maxUDPPayload) {
+		t.Errorf("expected datagrams of at most %d bytes; got %v", maxUDPPayload, params[4])
+	}
+}
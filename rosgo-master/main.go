@@ -0,0 +1,33 @@
+package main
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	flag "github.com/ogier/pflag"
+
+	"github.com/ppg/rosgo/master"
+)
+
+var address string
+
+func main() {
+	log.SetFlags(0)
+
+	flag.StringVarP(&address, "address", "a", ":11311", "address to listen on; defaults to port 11311 on all interfaces")
+	flag.Parse()
+
+	m, err := master.Start(address)
+	if err != nil {
+		log.Printf("unable to start master: %s", err)
+		os.Exit(1)
+	}
+	log.Printf("ROS_MASTER_URI=%s", m.Uri())
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	<-signals
+	m.Shutdown()
+}
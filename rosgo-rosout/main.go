@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/ppg/rosgo/msgs/rosgraph_msgs"
+	"github.com/ppg/rosgo/ros"
+)
+
+func main() {
+	log.SetFlags(0)
+
+	// The aggregator's own log messages would otherwise come back to it on
+	// /rosout.
+	node, err := ros.NewNode("/rosout", ros.NoRosout())
+	if err != nil {
+		log.Printf("unable to start node: %s", err)
+		os.Exit(1)
+	}
+	defer node.Shutdown()
+
+	if err := aggregate(node); err != nil {
+		log.Print(err)
+		os.Exit(1)
+	}
+	node.Spin()
+}
+
+// Republish the messages on /rosout on /rosout_agg.
+func aggregate(node ros.Node) error {
+	pub, err := node.NewPublisher("/rosout_agg", rosgraph_msgs.MsgLog, ros.PublisherQueueSize(1000))
+	if err != nil {
+		return fmt.Errorf("unable to advertise /rosout_agg: %s", err)
+	}
+	if _, err := node.NewSubscriber("/rosout", rosgraph_msgs.MsgLog, func(msg *rosgraph_msgs.Log) {
+		pub.Publish(msg)
+	}, ros.SubscriberQueueSize(1000)); err != nil {
+		return fmt.Errorf("unable to subscribe to /rosout: %s", err)
+	}
+	return nil
+}
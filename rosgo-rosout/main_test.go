@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ppg/rosgo/master"
+	"github.com/ppg/rosgo/msgs/rosgraph_msgs"
+	"github.com/ppg/rosgo/ros"
+)
+
+func TestAggregatorIgnoresOwnLog(t *testing.T) {
+	m, err := master.Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Shutdown()
+	os.Setenv("ROS_MASTER_URI", m.Uri())
+	os.Setenv("ROS_IP", "127.0.0.1")
+
+	aggregator, err := ros.NewNode("/rosout", ros.NoRosout())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer aggregator.Shutdown()
+	// The aggregator logs every message it handles at this level.
+	aggregator.Logger().SetSeverity(ros.LogLevelDebug)
+	if err := aggregate(aggregator); err != nil {
+		t.Fatal(err)
+	}
+	go aggregator.Spin()
+
+	talker, err := ros.NewNode("talker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer talker.Shutdown()
+	listener, err := ros.NewNode("listener")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Shutdown()
+	listener.Logger().SetSeverity(ros.LogLevelFatal)
+	names := make(chan string, 1000)
+	if _, err := listener.NewSubscriber("/rosout_agg", rosgraph_msgs.MsgLog, func(msg *rosgraph_msgs.Log) {
+		select {
+		case names <- msg.Name:
+		default:
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	go listener.Spin()
+
+	deadline := time.After(5 * time.Second)
+	for received := false; !received; {
+		talker.Logger().Info("hello")
+		select {
+		case name := <-names:
+			received = name == "/talker"
+			if name == "/rosout" {
+				t.Fatal("the aggregator republished its own log message")
+			}
+		case <-time.After(50 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("the talker's log message was not aggregated")
+		}
+	}
+	timeout := time.After(200 * time.Millisecond)
+	for {
+		select {
+		case name := <-names:
+			if name == "/rosout" {
+				t.Fatal("the aggregator republished its own log message")
+			}
+		case <-timeout:
+			return
+		}
+	}
+}
@@ -2,6 +2,7 @@ package xmlrpc
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/xml"
 	"errors"
@@ -465,16 +466,28 @@ func parseResponse(d *xml.Decoder) (ok bool, result interface{}, e error) {
 }
 
 func Call(url string, method string, args ...interface{}) (res interface{}, e error) {
+	return CallContext(context.Background(), url, method, args...)
+}
+
+// Call like Call, giving up when ctx is done.  The error then wraps ctx's.
+func CallContext(ctx context.Context, url string, method string, args ...interface{}) (res interface{}, e error) {
 	var buffer bytes.Buffer
 	e = emitRequest(&buffer, method, args...)
 	if e != nil {
 		e = fmt.Errorf("Building request failed for %v", e)
 		return
 	}
+	var req *http.Request
+	req, e = http.NewRequestWithContext(ctx, http.MethodPost, url, &buffer)
+	if e != nil {
+		e = fmt.Errorf("Building request failed for %v", e)
+		return
+	}
+	req.Header.Set("Content-Type", "text/xml")
 	var r *http.Response
-	r, e = http.Post(url, "text/xml", &buffer)
+	r, e = http.DefaultClient.Do(req)
 	if e != nil {
-		e = fmt.Errorf("Sending request failed for %v", e)
+		e = fmt.Errorf("Sending request failed for %w", e)
 		return
 	}
 	defer r.Body.Close()
@@ -488,6 +501,10 @@ func Call(url string, method string, args ...interface{}) (res interface{}, e er
 	decoder := xml.NewDecoder(r.Body)
 	ok, result, e := parseResponse(decoder)
 	if e != nil {
+		if ctx.Err() != nil {
+			e = fmt.Errorf("Receiving response failed for %w", ctx.Err())
+			return
+		}
 		e = fmt.Errorf("Parsing response failed for %v", e)
 		return
 	}
@@ -518,8 +535,10 @@ func Call(url string, method string, args ...interface{}) (res interface{}, e er
 type Method interface{}
 
 type Handler struct {
-	mapping map[string]Method
-	wait    sync.WaitGroup
+	mapping  map[string]Method
+	wait     sync.WaitGroup
+	mutex    sync.Mutex
+	shutdown bool // Set by WaitForShutdown; requests after it are refused.
 }
 
 func NewHandler(mapping map[string]Method) *Handler {
@@ -529,11 +548,21 @@ func NewHandler(mapping map[string]Method) *Handler {
 }
 
 func (self *Handler) WaitForShutdown() {
+	self.mutex.Lock()
+	self.shutdown = true
+	self.mutex.Unlock()
 	self.wait.Wait()
 }
 
 func (self *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	self.mutex.Lock()
+	if self.shutdown {
+		self.mutex.Unlock()
+		http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		return
+	}
 	self.wait.Add(1)
+	self.mutex.Unlock()
 	defer self.wait.Done()
 
 	decoder := xml.NewDecoder(req.Body)